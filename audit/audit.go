@@ -0,0 +1,380 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/tagging"
+)
+
+// Value case rules of a tag
+const (
+	CaseAny   string = "any"
+	CaseLower string = "lower"
+	CaseUpper string = "upper"
+)
+
+// Problems found on a tag
+const (
+	ProblemMissing string = "missing"
+	ProblemInvalid string = "invalid"
+)
+
+var (
+	GetCurrentTagsClient = tagging.GetCurrentTags
+	TagTableClient       = client.TagTable
+)
+
+// TagRule describes a tag of the tagging policy.
+type TagRule struct {
+	Key      string   `yaml:"key"`
+	Required bool     `yaml:"required"`
+	Allowed  []string `yaml:"allowed"` // Allowed values, any value if empty
+	Pattern  string   `yaml:"pattern"` // Regular expression the value must match
+	Case     string   `yaml:"case"`    // Value case rule: any, lower or upper
+	Default  string   `yaml:"default"` // Value applied by --fix when the tag is missing
+
+	pattern *regexp.Regexp
+}
+
+// Schema is the tagging policy the tables are audited against.
+type Schema struct {
+	CaseSensitiveKeys bool      `yaml:"caseSensitiveKeys"`
+	IgnoreValueCase   bool      `yaml:"ignoreValueCase"` // Compare the allowed values case-insensitively
+	Tags              []TagRule `yaml:"tags"`
+}
+
+// Issue is a missing or invalid tag of a table.
+type Issue struct {
+	Key     string `json:"key"`
+	Problem string `json:"problem"`
+	Value   string `json:"value,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// TableReport is the audit result of a table.
+type TableReport struct {
+	Table     string            `json:"table"`
+	Compliant bool              `json:"compliant"`
+	Issues    []Issue           `json:"issues,omitempty"`
+	Fixes     map[string]string `json:"fixes,omitempty"` // Default values applied, or pending on a dry run
+	FixStatus string            `json:"fixStatus,omitempty"`
+	Error     string            `json:"error,omitempty"`
+
+	arn  string
+	tags map[string]string
+}
+
+// LoadSchema reads and validates a YAML or JSON schema file.
+// It returns the schema and an error if the file can't be read or the schema is invalid.
+func LoadSchema(path string) (*Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read schema file:%s - error:%v", path, err))
+	}
+
+	var schema Schema
+	if err = yaml.Unmarshal(content, &schema); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse schema file:%s - error:%v", path, err))
+	}
+	if err = schema.Validate(); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid schema file:%s - error:%v", path, err))
+	}
+	return &schema, nil
+}
+
+// Validate checks the rules of the schema and compiles their patterns.
+// It returns an error if a rule is invalid.
+func (s *Schema) Validate() error {
+	if len(s.Tags) == 0 {
+		return errors.New("no tag rule defined")
+	}
+
+	seen := make(map[string]bool)
+	for i := range s.Tags {
+		rule := &s.Tags[i]
+		if rule.Key == "" {
+			return errors.New(fmt.Sprintf("tag rule #%d has no key", i+1))
+		}
+		if seen[s.normalizeKey(rule.Key)] {
+			return errors.New(fmt.Sprintf("tag rule defined more than once:%s", rule.Key))
+		}
+		seen[s.normalizeKey(rule.Key)] = true
+
+		switch rule.Case {
+		case "":
+			rule.Case = CaseAny
+		case CaseAny, CaseLower, CaseUpper:
+		default:
+			return errors.New(fmt.Sprintf("unrecognized case rule of tag:%s - %s", rule.Key, rule.Case))
+		}
+
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return errors.New(fmt.Sprintf("invalid pattern of tag:%s - %v", rule.Key, err))
+			}
+			rule.pattern = pattern
+		}
+
+		if rule.Default != "" {
+			if reason := s.checkValue(rule, rule.Default); reason != "" {
+				return errors.New(fmt.Sprintf("default value of tag:%s is invalid - %s", rule.Key, reason))
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeKey returns the key used to compare tag keys according to the key case rule.
+func (s *Schema) normalizeKey(key string) string {
+	if s.CaseSensitiveKeys {
+		return key
+	}
+	return strings.ToLower(key)
+}
+
+// checkValue checks a tag value against its rule, it returns the reason why the value is invalid or an empty string.
+func (s *Schema) checkValue(rule *TagRule, value string) string {
+	if value == "" {
+		return "empty value"
+	}
+
+	if rule.Case == CaseLower && value != strings.ToLower(value) {
+		return "value must be lower case"
+	}
+	if rule.Case == CaseUpper && value != strings.ToUpper(value) {
+		return "value must be upper case"
+	}
+
+	if len(rule.Allowed) > 0 {
+		allowed := false
+		for _, candidate := range rule.Allowed {
+			if candidate == value || (s.IgnoreValueCase && strings.EqualFold(candidate, value)) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("value not in allowed values: %s", strings.Join(rule.Allowed, ", "))
+		}
+	}
+
+	if rule.pattern != nil && !rule.pattern.MatchString(value) {
+		return fmt.Sprintf("value doesn't match pattern: %s", rule.Pattern)
+	}
+	return ""
+}
+
+// CheckTags audits the tags of a table against the schema.
+// It returns the issues found, sorted by key.
+func (s *Schema) CheckTags(tags map[string]string) []Issue {
+	var issues []Issue
+	for i := range s.Tags {
+		rule := &s.Tags[i]
+
+		key, value, found := "", "", false
+		for tagKey, tagValue := range tags {
+			if s.normalizeKey(tagKey) == s.normalizeKey(rule.Key) {
+				key, value, found = tagKey, tagValue, true
+				break
+			}
+		}
+
+		if !found {
+			if s.CaseSensitiveKeys {
+				// Tell a misspelled key apart from a missing one
+				for tagKey := range tags {
+					if strings.EqualFold(tagKey, rule.Key) {
+						issues = append(issues, Issue{Key: rule.Key, Problem: ProblemInvalid, Value: tags[tagKey], Reason: fmt.Sprintf("key case mismatch: %s", tagKey)})
+						found = true
+						break
+					}
+				}
+			}
+			if !found && rule.Required {
+				issues = append(issues, Issue{Key: rule.Key, Problem: ProblemMissing, Reason: "required tag is missing"})
+			}
+			continue
+		}
+
+		if reason := s.checkValue(rule, value); reason != "" {
+			issues = append(issues, Issue{Key: key, Problem: ProblemInvalid, Value: value, Reason: reason})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// Fixes returns the default values of the missing tags which define one.
+func (s *Schema) Fixes(issues []Issue) map[string]string {
+	fixes := make(map[string]string)
+	for _, issue := range issues {
+		if issue.Problem != ProblemMissing {
+			continue
+		}
+		for _, rule := range s.Tags {
+			if rule.Key == issue.Key && rule.Default != "" {
+				fixes[rule.Key] = rule.Default
+			}
+		}
+	}
+	return fixes
+}
+
+// auditTable audits a table, the default values of its missing tags are pending fixes if 'fix' is set.
+func auditTable(dbmgr *client.DynamoDBManager, schema *Schema, tableName string, fix bool) TableReport {
+	report := TableReport{Table: tableName}
+
+	tableArn, tags, err := GetCurrentTagsClient(dbmgr, tableName)
+	if err != nil {
+		dbmgr.Logger.Warnf("Get tags of table:%s, failed due to:%v", tableName, err)
+		report.Error = err.Error()
+		return report
+	}
+	report.arn, report.tags = tableArn, tags
+
+	report.Issues = schema.CheckTags(tags)
+	if fix {
+		if fixes := schema.Fixes(report.Issues); len(fixes) > 0 {
+			report.Fixes = fixes
+			report.FixStatus = tagging.StatusPending
+		}
+	}
+
+	report.Compliant = len(report.Issues) == 0
+	return report
+}
+
+// applyFixes tags the table with the pending fixes of its report, and audits it again once they are applied.
+func applyFixes(dbmgr *client.DynamoDBManager, schema *Schema, report *TableReport) {
+	if err := TagTableClient(dbmgr, report.arn, report.Fixes); err != nil {
+		report.FixStatus = tagging.StatusFailed
+		report.Error = err.Error()
+		report.Compliant = false
+		return
+	}
+	report.FixStatus = tagging.StatusApplied
+	report.Issues = schema.CheckTags(mergeTags(report.tags, report.Fixes))
+	report.Compliant = len(report.Issues) == 0
+}
+
+// mergeTags returns the tags overwritten by the fixes.
+func mergeTags(tags map[string]string, fixes map[string]string) map[string]string {
+	merged := make(map[string]string, len(tags)+len(fixes))
+	for key, value := range tags {
+		merged[key] = value
+	}
+	for key, value := range fixes {
+		merged[key] = value
+	}
+	return merged
+}
+
+// WriteReport writes the audit reports of the tables in the given output format.
+func WriteReport(w io.Writer, reports []TableReport, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, reports)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tKEY\tPROBLEM\tVALUE\tREASON\n")
+	for _, report := range reports {
+		if report.Error != "" {
+			fmt.Fprintf(tw, "%s\t-\terror\t-\t%s\n", report.Table, report.Error)
+		}
+		if report.Compliant {
+			fmt.Fprintf(tw, "%s\t-\tcompliant\t-\t-\n", report.Table)
+		}
+		for _, issue := range report.Issues {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", report.Table, issue.Key, issue.Problem, issue.Value, issue.Reason)
+		}
+		keys := make([]string, 0, len(report.Fixes))
+		for key := range report.Fixes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%s\t%s\tfix %s\t%s\tdefault value\n", report.Table, key, report.FixStatus, report.Fixes[key])
+		}
+	}
+	return tw.Flush()
+}
+
+// ExecuteAuditTags audits the tags of the given tables against the schema file.
+// It takes a DynamoDBManager, the schema file, the table names, the fix, dry-run and confirmation flags and an output format as input.
+// With 'fix' set, the default values of the missing tags are shown and applied once the user confirms, unless 'yes' is set,
+// or only reported with dryRun.
+// It returns an error wrapping client.ErrPendingChanges for a dry run with pending fixes,
+// client.ErrPolicyViolation if any table is not compliant, or client.ErrPartialFailure if some tables couldn't be audited.
+func ExecuteAuditTags(dbmgr *client.DynamoDBManager, schemaFile string, tables []string, fix bool, dryRun bool, yes bool, format string) error {
+	schema, err := LoadSchema(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	dbmgr.Logger.Infof("Begin to audit the tags of %d table(s) against schema:%s, ...", len(tables), schemaFile)
+	reports := make([]TableReport, 0, len(tables))
+	pending := 0
+	for _, tableName := range tables {
+		report := auditTable(dbmgr, schema, tableName, fix)
+		if report.FixStatus == tagging.StatusPending {
+			pending++
+		}
+		reports = append(reports, report)
+	}
+
+	if pending > 0 && !dryRun {
+		var fixes []TableReport
+		for _, report := range reports {
+			if report.FixStatus == tagging.StatusPending {
+				fixes = append(fixes, report)
+			}
+		}
+		plan := func(w io.Writer) error { return WriteReport(w, fixes, output.Text) }
+		if err = prompt.ApprovePlan(yes, fmt.Sprintf("Apply the default tags to %d table(s)?", pending), plan); err != nil {
+			return err
+		}
+		for i := range reports {
+			if reports[i].FixStatus == tagging.StatusPending {
+				applyFixes(dbmgr, schema, &reports[i])
+			}
+		}
+		pending = 0
+	}
+
+	nonCompliant, failed := 0, 0
+	for _, report := range reports {
+		if report.Error != "" {
+			failed++
+		} else if !report.Compliant {
+			nonCompliant++
+		}
+	}
+
+	if err = WriteReport(output.Stdout, reports, format); err != nil {
+		return err
+	}
+
+	switch {
+	case pending > 0:
+		return fmt.Errorf("fixes pending for %d table(s) - %w", pending, client.ErrPendingChanges)
+	case nonCompliant > 0:
+		return fmt.Errorf("%d of %d table(s) are not compliant with the tagging policy - %w", nonCompliant, len(tables), client.ErrPolicyViolation)
+	case failed > 0:
+		return fmt.Errorf("%d of %d table(s) couldn't be audited - %w", failed, len(tables), client.ErrPartialFailure)
+	}
+	dbmgr.Logger.Infof("All %d table(s) are compliant with the tagging policy", len(tables))
+	return nil
+}
@@ -0,0 +1,363 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+)
+
+// DefaultNameTemplate names the backups after their table and creation time
+const DefaultNameTemplate = "{table}-{timestamp}"
+
+// Placeholders of the backup name template
+const (
+	tablePlaceholder     = "{table}"
+	timestampPlaceholder = "{timestamp}"
+	timestampLayout      = "20060102-150405"
+	restoreTimeout       = time.Hour
+)
+
+// Statuses of a backup operation
+const (
+	StatusCreated   string = "created"
+	StatusRestoring string = "restoring"
+	StatusRestored  string = "restored"
+	StatusDeleted   string = "deleted"
+	StatusPending   string = "pending"
+	StatusFailed           = outcome.StatusFailed
+)
+
+var (
+	CreateBackupClient           = client.CreateBackup
+	ListBackupsClient            = client.ListBackups
+	RestoreTableFromBackupClient = client.RestoreTableFromBackup
+	DeleteBackupClient           = client.DeleteBackup
+	WaitForTableClient           = client.WaitForTable
+)
+
+// validBackupName matches the names DynamoDB accepts for backups
+var validBackupName = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+// Backup is a backup of a table as listed.
+type Backup struct {
+	Table   string     `json:"table"`
+	Name    string     `json:"name"`
+	Arn     string     `json:"arn"`
+	Created time.Time  `json:"created"`
+	Expiry  *time.Time `json:"expiry,omitempty"`
+	Size    int64      `json:"sizeBytes"`
+	Status  string     `json:"status"`
+	Type    string     `json:"type"`
+}
+
+// Result is the outcome of an operation on a backup.
+type Result struct {
+	Table string `json:"table,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Arn   string `json:"arn,omitempty"`
+	outcome.Outcome
+}
+
+// ParseTime parses a time given as RFC 3339, e.g. 2024-02-21T10:00:00Z, or as a date, e.g. 2024-02-21.
+// It returns nil for an empty string, or an error if the time is malformed.
+func ParseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("invalid time:%s, RFC 3339 or YYYY-MM-DD is expected", value))
+}
+
+// ParseType parses the type of the backups to list: user, system, aws-backup or all.
+// It returns the backup type filter and an error if the type is not recognized.
+func ParseType(value string) (types.BackupTypeFilter, error) {
+	switch strings.ToLower(value) {
+	case "user", "":
+		return types.BackupTypeFilterUser, nil
+	case "system":
+		return types.BackupTypeFilterSystem, nil
+	case "aws-backup":
+		return types.BackupTypeFilterAwsBackup, nil
+	case "all":
+		return types.BackupTypeFilterAll, nil
+	}
+	return "", errors.New(fmt.Sprintf("unrecognized backup type provided:%s", value))
+}
+
+// BackupName builds the name of a backup from the template, replacing {table} and {timestamp}.
+// It returns the name and an error if it isn't a valid backup name.
+func BackupName(template string, tableName string, now time.Time) (string, error) {
+	name := strings.ReplaceAll(template, tablePlaceholder, tableName)
+	name = strings.ReplaceAll(name, timestampPlaceholder, now.UTC().Format(timestampLayout))
+	if !validBackupName.MatchString(name) {
+		return "", errors.New(fmt.Sprintf("invalid backup name:%s, 3 to 255 letters, digits, '_', '-' or '.' are expected", name))
+	}
+	return name, nil
+}
+
+// ValidateNameTemplate checks that the template builds valid backup names.
+// It returns an error if it doesn't.
+func ValidateNameTemplate(template string) error {
+	_, err := BackupName(template, "table", time.Now())
+	return err
+}
+
+// ListBackups lists the backups of the given tables, or of every table if none is given, created within the optional time range.
+// It returns the backups sorted by table and creation time, and an error.
+func ListBackups(dbmgr *client.DynamoDBManager, tables []string, backupType types.BackupTypeFilter, from *time.Time, to *time.Time) ([]Backup, error) {
+	if len(tables) == 0 {
+		tables = []string{""}
+	}
+
+	var backups []Backup
+	for _, tableName := range tables {
+		summaries, err := ListBackupsClient(dbmgr, tableName, backupType, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range summaries {
+			backups = append(backups, Backup{
+				Table:   aws.ToString(summary.TableName),
+				Name:    aws.ToString(summary.BackupName),
+				Arn:     aws.ToString(summary.BackupArn),
+				Created: aws.ToTime(summary.BackupCreationDateTime),
+				Expiry:  summary.BackupExpiryDateTime,
+				Size:    aws.ToInt64(summary.BackupSizeBytes),
+				Status:  string(summary.BackupStatus),
+				Type:    string(summary.BackupType),
+			})
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].Table != backups[j].Table {
+			return backups[i].Table < backups[j].Table
+		}
+		return backups[i].Created.Before(backups[j].Created)
+	})
+	return backups, nil
+}
+
+// WriteBackups writes the backups in the given output format.
+func WriteBackups(w io.Writer, backups []Backup, format string) error {
+	if format == output.JSON {
+		if backups == nil {
+			backups = []Backup{}
+		}
+		return output.WriteJSON(w, backups)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tNAME\tCREATED\tSIZE\tSTATUS\tTYPE\tARN\n")
+	for _, backup := range backups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", backup.Table, backup.Name, output.FormatTime(&backup.Created),
+			output.FormatBytes(backup.Size), backup.Status, backup.Type, backup.Arn)
+	}
+	return tw.Flush()
+}
+
+// WriteResults writes the outcome of an operation on backups in the given output format.
+func WriteResults(w io.Writer, results []Result, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, results)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tBACKUP\tSTATUS\tDETAILS\n")
+	for _, result := range results {
+		details := result.Arn
+		if result.Error != "" {
+			details = result.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Table, result.Name, result.Status, details)
+	}
+	return tw.Flush()
+}
+
+// ExecuteList lists the backups of the given tables, or of every table if none is given, and writes them in the given output format.
+// It takes a DynamoDBManager, the table names, the backup type, the optional time range and the output format as input.
+// It returns client.ErrNoMatches if there is no backup, or an error if the listing fails.
+func ExecuteList(dbmgr *client.DynamoDBManager, tables []string, backupType types.BackupTypeFilter, from *time.Time, to *time.Time, format string) error {
+	backups, err := ListBackups(dbmgr, tables, backupType, from, to)
+	if err != nil {
+		return err
+	}
+	if err = WriteBackups(output.Stdout, backups, format); err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return client.ErrNoMatches
+	}
+	return nil
+}
+
+// ExecuteCreate creates an on-demand backup of every given table, named after the template.
+// It takes a DynamoDBManager, the table names, the name template, the dry-run flag and the output format of the report as input.
+// It returns an error wrapping client.ErrPendingChanges for a dry run,
+// client.ErrPartialFailure if some backups failed, or the error of the failure if all of them failed.
+func ExecuteCreate(dbmgr *client.DynamoDBManager, tables []string, nameTemplate string, dryRun bool, format string) error {
+	now := time.Now()
+	results := make([]Result, 0, len(tables))
+	for _, tableName := range tables {
+		result := Result{Table: tableName, Outcome: outcome.Outcome{Status: StatusPending}}
+		name, err := BackupName(nameTemplate, tableName, now)
+		if err != nil {
+			result.Fail(err)
+			results = append(results, result)
+			continue
+		}
+		result.Name = name
+
+		if !dryRun {
+			details, err := CreateBackupClient(dbmgr, tableName, name)
+			if err != nil {
+				result.Fail(err)
+			} else {
+				result.Status = StatusCreated
+				result.Arn = aws.ToString(details.BackupArn)
+				dbmgr.Logger.Infof("Created backup:%s of table:%s", name, tableName)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := WriteResults(output.Stdout, results, format); err != nil {
+		return err
+	}
+	if pending := outcome.CountStatus(results, StatusPending); pending > 0 {
+		return fmt.Errorf("backups pending for %d table(s) - %w", pending, client.ErrPendingChanges)
+	}
+	return outcome.Summarize("backup", "backup(s)", results)
+}
+
+// ExecuteRestore restores a backup to a new table, the latest backup of a table can be restored by giving its name instead of the backup ARN.
+// It takes a DynamoDBManager, the backup ARN, or the source table name, the target table name, the wait and dry-run flags
+// and the output format of the report as input.
+// With 'wait' set, it waits until the restored table is active.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, or an error if the restore fails.
+func ExecuteRestore(dbmgr *client.DynamoDBManager, backupArn string, sourceTable string, targetTable string, wait bool, dryRun bool, format string) error {
+	result := Result{Table: targetTable, Arn: backupArn, Outcome: outcome.Outcome{Status: StatusPending}}
+	if backupArn == "" {
+		backups, err := ListBackups(dbmgr, []string{sourceTable}, types.BackupTypeFilterAll, nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, backup := range backups {
+			if backup.Status == string(types.BackupStatusAvailable) {
+				result.Arn, result.Name = backup.Arn, backup.Name
+			}
+		}
+		if result.Arn == "" {
+			return fmt.Errorf("no available backup of table:%s - %w", sourceTable, client.ErrNoMatches)
+		}
+	}
+
+	if !dryRun {
+		if _, err := RestoreTableFromBackupClient(dbmgr, result.Arn, targetTable); err != nil {
+			result.Fail(err)
+		} else {
+			result.Status = StatusRestoring
+			dbmgr.Logger.Infof("Restoring backup:%s to table:%s", result.Arn, targetTable)
+			if wait {
+				if err = WaitForTableClient(dbmgr, targetTable, restoreTimeout); err != nil {
+					result.Fail(err)
+				} else {
+					result.Status = StatusRestored
+				}
+			}
+		}
+	}
+
+	if err := WriteResults(output.Stdout, []Result{result}, format); err != nil {
+		return err
+	}
+	switch result.Status {
+	case StatusPending:
+		return fmt.Errorf("restore of backup:%s to table:%s pending - %w", result.Arn, targetTable, client.ErrPendingChanges)
+	case StatusFailed:
+		return result.Err()
+	}
+	return nil
+}
+
+// deleteAll deletes the backups of the pending results and updates their status.
+func deleteAll(dbmgr *client.DynamoDBManager, results []Result) {
+	for i := range results {
+		if results[i].Status != StatusPending {
+			continue
+		}
+		if err := DeleteBackupClient(dbmgr, results[i].Arn); err != nil {
+			results[i].Fail(err)
+			continue
+		}
+		results[i].Status = StatusDeleted
+		dbmgr.Logger.Infof("Deleted backup:%s of table:%s", results[i].Arn, results[i].Table)
+	}
+}
+
+// DeleteBackups deletes the given backups once confirmed, or only reports them with dryRun set.
+// It returns an error as described by ExecuteDelete.
+func DeleteBackups(dbmgr *client.DynamoDBManager, backups []Backup, dryRun bool, yes bool, format string) error {
+	results := make([]Result, 0, len(backups))
+	for _, backup := range backups {
+		results = append(results, Result{Table: backup.Table, Name: backup.Name, Arn: backup.Arn, Outcome: outcome.Outcome{Status: StatusPending}})
+	}
+	if len(results) == 0 {
+		return client.ErrNoMatches
+	}
+
+	if dryRun {
+		if err := WriteResults(output.Stdout, results, format); err != nil {
+			return err
+		}
+		return fmt.Errorf("deletion pending for %d backup(s) - %w", len(results), client.ErrPendingChanges)
+	}
+	plan := func(w io.Writer) error { return WriteResults(w, results, output.Text) }
+	if err := prompt.ApprovePlan(yes, fmt.Sprintf("Delete %d backup(s)?", len(results)), plan); err != nil {
+		return err
+	}
+
+	deleteAll(dbmgr, results)
+
+	if err := WriteResults(output.Stdout, results, format); err != nil {
+		return err
+	}
+	return outcome.Summarize("deletion", "backup(s)", results)
+}
+
+// ExecuteDelete deletes the given backups, or the backups of the given tables created within the optional time range.
+// It takes a DynamoDBManager, the backup ARNs, the table names, the backup type and time range of the backups of the tables,
+// the dry-run and confirmation flags and the output format of the report as input.
+// The backups are deleted once the user confirms, unless 'yes' is set.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, client.ErrNoMatches if there is no backup to delete,
+// client.ErrPartialFailure if some deletions failed, or the error of the failure if all of them failed.
+func ExecuteDelete(dbmgr *client.DynamoDBManager, arns []string, tables []string, backupType types.BackupTypeFilter, from *time.Time, to *time.Time,
+	dryRun bool, yes bool, format string) error {
+	var backups []Backup
+	for _, arn := range arns {
+		backups = append(backups, Backup{Arn: arn})
+	}
+	if len(tables) > 0 {
+		listed, err := ListBackups(dbmgr, tables, backupType, from, to)
+		if err != nil {
+			return err
+		}
+		backups = append(backups, listed...)
+	}
+	return DeleteBackups(dbmgr, backups, dryRun, yes, format)
+}
@@ -0,0 +1,295 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/tagging"
+)
+
+// Decisions of the prune plan
+const (
+	Keep   string = "keep"
+	Delete string = "delete"
+)
+
+// DefaultLatest is the number of newest backups kept by a rule that doesn't set it
+const DefaultLatest = 1
+
+var GetCurrentTagsTask = tagging.GetCurrentTags
+
+// RetentionRule tells how many backups to keep for the tables it matches.
+// A table matches if its name matches the glob pattern, when given, and it has the tag, when given.
+type RetentionRule struct {
+	Tables  string `yaml:"tables"`  // Glob pattern of the table names, e.g. prod-*
+	Tag     string `yaml:"tag"`     // Tag the tables must have, as key or key=value
+	Latest  *int   `yaml:"latest"`  // Number of newest backups always kept, DefaultLatest if not set
+	Daily   int    `yaml:"daily"`   // Days for which the newest backup of the day is kept
+	Weekly  int    `yaml:"weekly"`  // ISO weeks for which the newest backup of the week is kept
+	Monthly int    `yaml:"monthly"` // Months for which the newest backup of the month is kept
+}
+
+// RetentionPolicy holds the retention rules, the first rule matching a table applies to it.
+// The backups of the tables matching no rule are all kept.
+type RetentionPolicy struct {
+	Rules []RetentionRule `yaml:"rules"`
+}
+
+// Decision is the plan for a backup: keep or delete it, and why.
+type Decision struct {
+	Backup
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// LoadPolicy reads and validates a YAML or JSON retention policy file.
+// It returns the policy and an error if the file can't be read or the policy is invalid.
+func LoadPolicy(file string) (*RetentionPolicy, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read policy file:%s - error:%v", file, err))
+	}
+
+	var policy RetentionPolicy
+	if err = yaml.Unmarshal(content, &policy); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse policy file:%s - error:%v", file, err))
+	}
+	if err = policy.Validate(); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid policy file:%s - error:%v", file, err))
+	}
+	return &policy, nil
+}
+
+// Validate checks the rules of the policy.
+// It returns an error if a rule is invalid.
+func (p *RetentionPolicy) Validate() error {
+	if len(p.Rules) == 0 {
+		return errors.New("no retention rule defined")
+	}
+	for i, rule := range p.Rules {
+		if rule.Tables == "" && rule.Tag == "" {
+			return errors.New(fmt.Sprintf("retention rule #%d has neither tables nor tag", i+1))
+		}
+		if _, err := path.Match(rule.Tables, ""); err != nil {
+			return errors.New(fmt.Sprintf("retention rule #%d has an invalid tables pattern:%s", i+1, rule.Tables))
+		}
+		if rule.Daily < 0 || rule.Weekly < 0 || rule.Monthly < 0 || (rule.Latest != nil && *rule.Latest < 0) {
+			return errors.New(fmt.Sprintf("retention rule #%d has a negative count", i+1))
+		}
+	}
+	return nil
+}
+
+// needsTags reports whether any rule matches the tables by tag.
+func (p *RetentionPolicy) needsTags() bool {
+	for _, rule := range p.Rules {
+		if rule.Tag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the rule applies to the table with the given tags.
+func (r *RetentionRule) Matches(tableName string, tags map[string]string) bool {
+	if r.Tables != "" {
+		if matched, _ := path.Match(r.Tables, tableName); !matched {
+			return false
+		}
+	}
+	if r.Tag != "" {
+		key, value, hasValue := strings.Cut(r.Tag, "=")
+		current, exists := tags[key]
+		if !exists || (hasValue && current != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rule returns the first rule of the policy applying to the table, nil if none does.
+func (p *RetentionPolicy) Rule(tableName string, tags map[string]string) *RetentionRule {
+	for i := range p.Rules {
+		if p.Rules[i].Matches(tableName, tags) {
+			return &p.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Describe formats the rule for the prune plan, e.g. "latest 1, daily 7, weekly 4, monthly 12".
+func (r *RetentionRule) Describe() string {
+	return fmt.Sprintf("latest %d, daily %d, weekly %d, monthly %d", r.latest(), r.Daily, r.Weekly, r.Monthly)
+}
+
+func (r *RetentionRule) latest() int {
+	if r.Latest == nil {
+		return DefaultLatest
+	}
+	return *r.Latest
+}
+
+// period identifies the day, ISO week or month of a time.
+type period func(t time.Time) string
+
+func day(t time.Time) string { return t.Format("2006-01-02") }
+
+func week(t time.Time) string {
+	year, w := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, w)
+}
+
+func month(t time.Time) string { return t.Format("2006-01") }
+
+// recentPeriods returns the identifiers of the 'count' periods up to now, e.g. the last 7 days.
+func recentPeriods(now time.Time, count int, id period, step func(t time.Time) time.Time) map[string]bool {
+	periods := make(map[string]bool, count)
+	for t := now; len(periods) < count; t = step(t) {
+		periods[id(t)] = true
+	}
+	return periods
+}
+
+// Plan decides which backups of a table the rule keeps, the backups must be of the same table and sorted by creation time.
+// The newest backups, and the newest backup of each recent day, week and month are kept, the other available backups are deleted.
+// Backups that aren't available, e.g. still being created, are always kept.
+func (r *RetentionRule) Plan(backups []Backup, now time.Time) []Decision {
+	now = now.UTC()
+	days := recentPeriods(now, r.Daily, day, func(t time.Time) time.Time { return t.AddDate(0, 0, -1) })
+	weeks := recentPeriods(now, r.Weekly, week, func(t time.Time) time.Time { return t.AddDate(0, 0, -7) })
+	months := recentPeriods(now, r.Monthly, month, func(t time.Time) time.Time {
+		// Step from the middle of the month, so no month is skipped
+		return time.Date(t.Year(), t.Month(), 15, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	})
+
+	decisions := make([]Decision, len(backups))
+	seen := map[string]bool{}
+	available := 0
+	// Newest first, so the first backup of a period is the one kept
+	for i := len(backups) - 1; i >= 0; i-- {
+		backup := backups[i]
+		decision := Decision{Backup: backup, Decision: Delete, Reason: "not retained by " + r.Describe()}
+		created := backup.Created.UTC()
+
+		switch {
+		case backup.Status != string(types.BackupStatusAvailable):
+			decision.Decision, decision.Reason = Keep, "not available"
+		case available < r.latest():
+			decision.Decision, decision.Reason = Keep, "latest"
+		case days[day(created)] && !seen["d"+day(created)]:
+			decision.Decision, decision.Reason = Keep, "daily "+day(created)
+		case weeks[week(created)] && !seen["w"+week(created)]:
+			decision.Decision, decision.Reason = Keep, "weekly "+week(created)
+		case months[month(created)] && !seen["m"+month(created)]:
+			decision.Decision, decision.Reason = Keep, "monthly "+month(created)
+		}
+
+		if backup.Status == string(types.BackupStatusAvailable) {
+			available++
+			// The newest backup of each period is accounted for, whichever reason kept it
+			if decision.Decision == Keep {
+				seen["d"+day(created)], seen["w"+week(created)], seen["m"+month(created)] = true, true, true
+			}
+		}
+		decisions[i] = decision
+	}
+	return decisions
+}
+
+// WritePlan writes the prune plan in the given output format.
+func WritePlan(w io.Writer, decisions []Decision, format string) error {
+	if format == output.JSON {
+		if decisions == nil {
+			decisions = []Decision{}
+		}
+		return output.WriteJSON(w, decisions)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tNAME\tCREATED\tDECISION\tREASON\n")
+	for _, decision := range decisions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", decision.Table, decision.Name, output.FormatTime(&decision.Created), decision.Decision, decision.Reason)
+	}
+	return tw.Flush()
+}
+
+// ExecutePrune deletes the user backups of the given tables that their retention rule doesn't keep.
+// It takes a DynamoDBManager, the policy file, the table names, the dry-run and confirmation flags and the output format as input.
+// The plan is shown first, the backups are deleted once the user confirms, unless 'yes' is set.
+// It returns an error wrapping client.ErrPendingChanges for a dry run with backups to delete,
+// client.ErrPartialFailure if some deletions failed, or the error of the failure if all of them failed.
+func ExecutePrune(dbmgr *client.DynamoDBManager, policyFile string, tables []string, dryRun bool, yes bool, format string) error {
+	policy, err := LoadPolicy(policyFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var decisions []Decision
+	var errs []error
+	for _, tableName := range tables {
+		var tags map[string]string
+		if policy.needsTags() {
+			if _, tags, err = GetCurrentTagsTask(dbmgr, tableName); err != nil {
+				dbmgr.Logger.Warnf("Get tags of table:%s, failed due to:%v", tableName, err)
+				errs = append(errs, err)
+				continue
+			}
+		}
+		rule := policy.Rule(tableName, tags)
+		if rule == nil {
+			dbmgr.Logger.Debugf("No retention rule applies to table:%s, its backups are kept", tableName)
+			continue
+		}
+
+		backups, err := ListBackups(dbmgr, []string{tableName}, types.BackupTypeFilterUser, nil, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		decisions = append(decisions, rule.Plan(backups, now)...)
+	}
+
+	var results []Result
+	for _, decision := range decisions {
+		if decision.Decision == Delete {
+			results = append(results, Result{Table: decision.Table, Name: decision.Name, Arn: decision.Arn, Outcome: outcome.Outcome{Status: StatusPending}})
+		}
+	}
+	if len(errs) > 0 {
+		// Some tables couldn't be planned, the others are still pruned
+		errs = []error{fmt.Errorf("%d table(s) not pruned - %w", len(errs), client.ErrPartialFailure)}
+	}
+
+	if dryRun || len(results) == 0 {
+		if err = WritePlan(output.Stdout, decisions, format); err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return errors.Join(errs...)
+		}
+		return errors.Join(append(errs, fmt.Errorf("deletion pending for %d backup(s) - %w", len(results), client.ErrPendingChanges))...)
+	}
+
+	plan := func(w io.Writer) error { return WritePlan(w, decisions, output.Text) }
+	if err = prompt.ApprovePlan(yes, fmt.Sprintf("Delete %d backup(s)?", len(results)), plan); err != nil {
+		return err
+	}
+	deleteAll(dbmgr, results)
+	if err = WriteResults(output.Stdout, results, format); err != nil {
+		return err
+	}
+	return errors.Join(append(errs, outcome.Summarize("deletion", "backup(s)", results))...)
+}
@@ -0,0 +1,152 @@
+package backup
+
+import (
+	"testing"
+	"time"
+)
+
+// backupAt returns a backup of the orders table created at the given time, in the given status.
+func backupAt(created string, status string) Backup {
+	t, err := time.Parse("2006-01-02 15:04", created)
+	if err != nil {
+		panic(err)
+	}
+	return Backup{Table: "orders", Name: "orders-" + t.Format("20060102-1504"), Created: t, Status: status}
+}
+
+func TestRetentionRulePlan(t *testing.T) {
+	backups := []Backup{
+		backupAt("2024-01-10 02:00", "AVAILABLE"),
+		backupAt("2024-01-25 02:00", "AVAILABLE"),
+		backupAt("2024-02-05 02:00", "AVAILABLE"),
+		backupAt("2024-02-20 02:00", "AVAILABLE"),
+		backupAt("2024-03-05 02:00", "AVAILABLE"),
+		backupAt("2024-03-12 02:00", "AVAILABLE"),
+		backupAt("2024-03-13 02:00", "AVAILABLE"),
+		backupAt("2024-03-18 02:00", "AVAILABLE"),
+		backupAt("2024-03-19 02:00", "AVAILABLE"),
+		backupAt("2024-03-19 23:00", "CREATING"),
+		backupAt("2024-03-20 01:00", "AVAILABLE"),
+		backupAt("2024-03-20 08:00", "AVAILABLE"),
+	}
+	want := []struct {
+		decision string
+		reason   string
+	}{
+		{Delete, ""},
+		{Keep, "monthly 2024-01"},
+		{Delete, ""},
+		{Keep, "monthly 2024-02"},
+		{Delete, ""},
+		{Delete, ""},
+		{Keep, "weekly 2024-W11"},
+		{Delete, ""},
+		{Keep, "daily 2024-03-19"},
+		{Keep, "not available"},
+		{Delete, ""},
+		{Keep, "latest"},
+	}
+
+	rule := RetentionRule{Tables: "orders", Daily: 2, Weekly: 2, Monthly: 3}
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	decisions := rule.Plan(backups, now)
+	if len(decisions) != len(backups) {
+		t.Fatalf("Plan() returned %d decision(s), want %d", len(decisions), len(backups))
+	}
+	for i, decision := range decisions {
+		if decision.Name != backups[i].Name {
+			t.Errorf("decision #%d is for backup:%s, want %s", i, decision.Name, backups[i].Name)
+		}
+		if decision.Decision != want[i].decision || (want[i].reason != "" && decision.Reason != want[i].reason) {
+			t.Errorf("decision of backup:%s = %s (%s), want %s (%s)", decision.Name, decision.Decision, decision.Reason,
+				want[i].decision, want[i].reason)
+		}
+	}
+}
+
+func TestRetentionRulePlanLatest(t *testing.T) {
+	backups := []Backup{
+		backupAt("2024-03-01 02:00", "AVAILABLE"),
+		backupAt("2024-03-02 02:00", "AVAILABLE"),
+		backupAt("2024-03-03 02:00", "AVAILABLE"),
+	}
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	none, three := 0, 3
+
+	tests := []struct {
+		name   string
+		latest *int
+		kept   int
+	}{
+		{"default", nil, DefaultLatest},
+		{"none", &none, 0},
+		{"all", &three, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := RetentionRule{Tables: "*", Latest: tt.latest}
+			kept := 0
+			for _, decision := range rule.Plan(backups, now) {
+				if decision.Decision == Keep {
+					kept++
+				}
+			}
+			if kept != tt.kept {
+				t.Errorf("Plan() kept %d backup(s), want %d", kept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyRule(t *testing.T) {
+	policy := RetentionPolicy{Rules: []RetentionRule{
+		{Tables: "prod-*", Tag: "tier=critical", Daily: 30},
+		{Tables: "prod-*", Daily: 7},
+		{Tag: "backup", Daily: 1},
+	}}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		table string
+		tags  map[string]string
+		daily int // Daily count of the rule applying, -1 if none
+	}{
+		{"prod-orders", map[string]string{"tier": "critical"}, 30},
+		{"prod-orders", map[string]string{"tier": "standard"}, 7},
+		{"dev-orders", map[string]string{"backup": ""}, 1},
+		{"dev-orders", nil, -1},
+	}
+	for _, tt := range tests {
+		rule := policy.Rule(tt.table, tt.tags)
+		daily := -1
+		if rule != nil {
+			daily = rule.Daily
+		}
+		if daily != tt.daily {
+			t.Errorf("Rule(%s, %v) has daily:%d, want %d", tt.table, tt.tags, daily, tt.daily)
+		}
+	}
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+	}{
+		{"no rule", RetentionPolicy{}},
+		{"neither tables nor tag", RetentionPolicy{Rules: []RetentionRule{{Daily: 7}}}},
+		{"invalid pattern", RetentionPolicy{Rules: []RetentionRule{{Tables: "prod-["}}}},
+		{"negative count", RetentionPolicy{Rules: []RetentionRule{{Tables: "*", Weekly: -4}}}},
+		{"negative latest", RetentionPolicy{Rules: []RetentionRule{{Tables: "*", Latest: &negative}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); err == nil {
+				t.Error("Validate() returned no error")
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ForrestIsARealGoodman/dynamodb-manager/logging"
 
@@ -19,40 +21,65 @@ const (
 	DefaultWcu = 5
 )
 
+// ManagerOptions holds the settings used to create a DynamoDBManager.
+type ManagerOptions struct {
+	Profile          string        // AWS shared config profile, the default credential chain is used if empty
+	Region           string        // AWS region, overrides the region of the profile or environment
+	Endpoint         string        // DynamoDB endpoint URL, e.g. of DynamoDB Local, the endpoint of the region if empty
+	RoleArn          string        // Role to assume through STS, if any
+	ExternalID       string        // External ID required by the trust policy of the role
+	MFASerial        string        // Serial number or ARN of the MFA device, the token code is prompted for
+	SessionName      string        // Name of the assumed role session, DefaultSessionName if empty
+	RoleDuration     time.Duration // Duration of the assumed role session, DefaultRoleDuration if 0
+	CacheCredentials bool          // Cache the temporary credentials on disk between invocations
+	Retry            RetryConfig   // Retry, backoff and client-side rate limit strategy
+}
+
 // DynamoDBManager represents the DynamoDB manager in Go.
 type DynamoDBManager struct {
 	DynamoDBClient *dynamodb.Client // Add DynamoDB client
 	Logger         *logging.Logger
+	Config         aws.Config // AWS config the DynamoDB client was created from, for the clients of other services
 }
 
 var LoadConfig = config.LoadDefaultConfig
 var DBNewFromConfig = dynamodb.NewFromConfig
 var NewListTablesPageIt = dynamodb.NewListTablesPaginator
 
-// CreateNewDynamoDBManager creates a new DynamoDBManager instance based on the provided AWS profile name.
+// CreateNewDynamoDBManager creates a new DynamoDBManager instance based on the provided manager options.
 // It returns a DynamoDBManager and an error.
-func CreateNewDynamoDBManager(profileName string) (*DynamoDBManager, error) {
+func CreateNewDynamoDBManager(opts ManagerOptions) (*DynamoDBManager, error) {
 	var configToUse aws.Config
 	var err error
 
-	if profileName != "" {
-		// For local test purpose
-		configToUse, err = LoadConfig(context.TODO(), config.WithSharedConfigProfile(profileName))
-	} else {
-		configToUse, err = LoadConfig(context.Background())
+	if err = opts.Retry.Validate(); err != nil {
+		return nil, err
 	}
 
+	if opts.MFASerial != "" && opts.RoleArn == "" {
+		return nil, errors.New("mfa serial requires a role arn to assume!")
+	}
+
+	if opts.RoleDuration != 0 && (opts.RoleDuration < MinRoleDuration || opts.RoleDuration > MaxRoleDuration) {
+		return nil, errors.New(fmt.Sprintf("role duration must be between %v and %v:%v", MinRoleDuration, MaxRoleDuration, opts.RoleDuration))
+	}
+
+	configToUse, err = LoadConfig(context.Background(), loadOptions(opts)...)
 	if err != nil {
-		fmt.Printf("CreateNewDynamoDBManager-config.LoadDefaultConfig:%s", err)
-		return nil, errors.New("Failed to instantiate aws config!")
+		return nil, errors.New(fmt.Sprintf("Failed to instantiate aws config: %v", err))
+	}
+	setupCredentials(&configToUse, opts)
+	if opts.Endpoint != "" {
+		// Set once the credentials are set up, so STS keeps its own endpoint
+		configToUse.BaseEndpoint = aws.String(opts.Endpoint)
 	}
 
-	return NewDynamoDBManager(configToUse)
+	return NewDynamoDBManager(opts.Retry, configToUse)
 }
 
-// NewDynamoDBManager creates a new DynamoDBManager instance with the given AWS config.
+// NewDynamoDBManager creates a new DynamoDBManager instance with the given retry configuration and AWS config.
 // It returns a DynamoDBManager and an error.
-func NewDynamoDBManager(cfg ...aws.Config) (*DynamoDBManager, error) {
+func NewDynamoDBManager(retryCfg RetryConfig, cfg ...aws.Config) (*DynamoDBManager, error) {
 	var configToUse aws.Config
 
 	if len(cfg) > 0 {
@@ -61,12 +88,17 @@ func NewDynamoDBManager(cfg ...aws.Config) (*DynamoDBManager, error) {
 		return nil, errors.New("cfg must be provided!")
 	}
 
-	dbclient := DBNewFromConfig(configToUse)
+	dbclient := DBNewFromConfig(configToUse, func(o *dynamodb.Options) {
+		o.Retryer = NewRetryer(retryCfg)
+		if limiter := newRateLimiter(retryCfg); limiter != nil {
+			o.APIOptions = append(o.APIOptions, limiter.addToStack)
+		}
+	})
 	db := DynamoDBManager{
 		DynamoDBClient: dbclient,
 		Logger:         nil,
+		Config:         configToUse,
 	}
-	fmt.Println("Instantiated dynamoDB manager!")
 	return &db, nil
 }
 
@@ -75,13 +107,13 @@ func NewDynamoDBManager(cfg ...aws.Config) (*DynamoDBManager, error) {
 func SetupLogger(dbmgr *DynamoDBManager, level string) error {
 	loggerObj, err := logging.NewLogger(level)
 	if err != nil {
-		fmt.Printf("SetupLogger failed due to:%v", err)
 		return err
 	}
 	if loggerObj == nil {
 		return errors.New("Failed to setup logger, returned empty!")
 	}
 	dbmgr.Logger = loggerObj
+	dbmgr.Logger.Debug("Instantiated dynamoDB manager!")
 	return nil
 }
 
@@ -96,6 +128,7 @@ func GetTableList(dbmgr *DynamoDBManager) ([]string, error) {
 		output, err = tablePaginator.NextPage(context.Background())
 		if err != nil {
 			dbmgr.Logger.Errorf("Couldn't list tables. Here's why: %v\n", err)
+			err = wrapError("ListTables", "", err)
 			break
 		} else {
 			tableNames = append(tableNames, output.TableNames...)
@@ -114,12 +147,20 @@ func GetTableArn(dbmgr *DynamoDBManager, tableName string) (string, error) {
 	output, err := dbmgr.DynamoDBClient.DescribeTable(context.Background(), input)
 	if err != nil {
 		dbmgr.Logger.Errorf("Failed to get Table Arn, Here's why: %v\n", err)
-		return "", err
+		return "", wrapError("DescribeTable", tableName, err)
 	}
 	return *output.Table.TableArn, nil
 
 }
 
+// ReservedTagPrefix is the prefix of the tags set by AWS, e.g. aws:cloudformation:stack-name, which can't be set or removed by users
+const ReservedTagPrefix = "aws:"
+
+// IsReservedTag reports whether the tag key is reserved for AWS.
+func IsReservedTag(key string) bool {
+	return strings.HasPrefix(key, ReservedTagPrefix)
+}
+
 // GetTableTags retrieves the tags of a DynamoDB table with the given ARN using the provided DynamoDBManager.
 // It returns a slice of tags and an error.
 func GetTableTags(dbmgr *DynamoDBManager, tableArn string) ([]types.Tag, error) {
@@ -130,7 +171,7 @@ func GetTableTags(dbmgr *DynamoDBManager, tableArn string) ([]types.Tag, error)
 	result, err := dbmgr.DynamoDBClient.ListTagsOfResource(context.Background(), listTagsInput)
 	if err != nil {
 		dbmgr.Logger.Errorf("Error calling ListTagsOfResource:%v", err)
-		return nil, err
+		return nil, wrapError("ListTagsOfResource", tableArn, err)
 	}
 
 	return result.Tags, nil
@@ -147,7 +188,7 @@ func GetCurrentBillingMode(dbmgr *DynamoDBManager, tableName string) (string, st
 
 	output, err := dbmgr.DynamoDBClient.DescribeTable(context.Background(), input)
 	if err != nil {
-		return "", "", "", err
+		return "", "", "", wrapError("DescribeTable", tableName, err)
 	}
 
 	if output.Table.BillingModeSummary != nil {
@@ -211,7 +252,7 @@ func UpdateProvisionedCapacity(dbmgr *DynamoDBManager, switchToProvisioned bool,
 		dbmgr.Logger.Infof("Provisioned capacity updated for table:%s - RCU: %d, WCU: %d", tableName, rcuVal, wcuVal)
 	}
 
-	return err
+	return wrapError("UpdateTable", tableName, err)
 }
 
 // SwitchToOnDemandCapacity switches a DynamoDB table to on-demand capacity mode.
@@ -229,5 +270,331 @@ func SwitchToOnDemandCapacity(dbmgr *DynamoDBManager, tableName string) error {
 		dbmgr.Logger.Infof("Switched to on-demand capacity for table: %s\n", tableName)
 	}
 
-	return err
+	return wrapError("UpdateTable", tableName, err)
+}
+
+// DescribeTable retrieves the full description of a DynamoDB table with the given name.
+// It returns the table description and an error.
+func DescribeTable(dbmgr *DynamoDBManager, tableName string) (*types.TableDescription, error) {
+	input := &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}
+
+	output, err := dbmgr.DynamoDBClient.DescribeTable(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Failed to describe table:%s, Here's why: %v\n", tableName, err)
+		return nil, wrapError("DescribeTable", tableName, err)
+	}
+	return output.Table, nil
+}
+
+// DescribeTimeToLive retrieves the time to live settings of a DynamoDB table with the given name.
+// It returns the time to live description and an error.
+func DescribeTimeToLive(dbmgr *DynamoDBManager, tableName string) (*types.TimeToLiveDescription, error) {
+	input := &dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(tableName),
+	}
+
+	output, err := dbmgr.DynamoDBClient.DescribeTimeToLive(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Failed to describe time to live of table:%s, Here's why: %v\n", tableName, err)
+		return nil, wrapError("DescribeTimeToLive", tableName, err)
+	}
+	return output.TimeToLiveDescription, nil
+}
+
+// DescribeContinuousBackups retrieves the continuous backups and point in time recovery settings of a DynamoDB table.
+// It returns the continuous backups description and an error.
+func DescribeContinuousBackups(dbmgr *DynamoDBManager, tableName string) (*types.ContinuousBackupsDescription, error) {
+	input := &dynamodb.DescribeContinuousBackupsInput{
+		TableName: aws.String(tableName),
+	}
+
+	output, err := dbmgr.DynamoDBClient.DescribeContinuousBackups(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Failed to describe continuous backups of table:%s, Here's why: %v\n", tableName, err)
+		return nil, wrapError("DescribeContinuousBackups", tableName, err)
+	}
+	return output.ContinuousBackupsDescription, nil
+}
+
+// TagTable adds or overwrites the given tags on the DynamoDB table with the given ARN.
+// It returns an error if the tagging fails.
+func TagTable(dbmgr *DynamoDBManager, tableArn string, tags map[string]string) error {
+	input := &dynamodb.TagResourceInput{
+		ResourceArn: aws.String(tableArn),
+	}
+	for key, value := range tags {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	_, err := dbmgr.DynamoDBClient.TagResource(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error calling TagResource for arn:%s - error:%v", tableArn, err)
+		return wrapError("TagResource", tableArn, err)
+	}
+	return nil
+}
+
+// UntagTable removes the tags with the given keys from the DynamoDB table with the given ARN.
+// It returns an error if the untagging fails.
+func UntagTable(dbmgr *DynamoDBManager, tableArn string, tagKeys []string) error {
+	input := &dynamodb.UntagResourceInput{
+		ResourceArn: aws.String(tableArn),
+		TagKeys:     tagKeys,
+	}
+
+	_, err := dbmgr.DynamoDBClient.UntagResource(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error calling UntagResource for arn:%s - error:%v", tableArn, err)
+		return wrapError("UntagResource", tableArn, err)
+	}
+	return nil
+}
+
+// ScanPage scans one page of a DynamoDB table, or of one of its segments for a parallel scan.
+// It returns the scan output and an error.
+func ScanPage(ctx context.Context, dbmgr *DynamoDBManager, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	output, err := dbmgr.DynamoDBClient.Scan(ctx, input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error scanning table:%s - error:%v", aws.ToString(input.TableName), err)
+		return nil, wrapError("Scan", aws.ToString(input.TableName), err)
+	}
+	return output, nil
+}
+
+// BatchWriteItem writes a batch of up to 25 put or delete requests to one or more DynamoDB tables.
+// It returns the output, holding the unprocessed requests to retry, and an error.
+func BatchWriteItem(dbmgr *DynamoDBManager, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	var tableName string
+	for name := range input.RequestItems {
+		tableName = name
+	}
+	output, err := dbmgr.DynamoDBClient.BatchWriteItem(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error writing a batch of items to table:%s - error:%v", tableName, err)
+		return nil, wrapError("BatchWriteItem", tableName, err)
+	}
+	return output, nil
+}
+
+// GetItem reads an item of a DynamoDB table by its primary key.
+// It returns the output, without item if it doesn't exist, and an error.
+func GetItem(dbmgr *DynamoDBManager, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	output, err := dbmgr.DynamoDBClient.GetItem(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error getting an item of table:%s - error:%v", aws.ToString(input.TableName), err)
+		return nil, wrapError("GetItem", aws.ToString(input.TableName), err)
+	}
+	return output, nil
+}
+
+// QueryPage queries one page of a DynamoDB table or of one of its indexes.
+// It returns the query output and an error.
+func QueryPage(dbmgr *DynamoDBManager, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	output, err := dbmgr.DynamoDBClient.Query(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error querying table:%s - error:%v", aws.ToString(input.TableName), err)
+		return nil, wrapError("Query", aws.ToString(input.TableName), err)
+	}
+	return output, nil
+}
+
+// ExecuteStatement runs a PartiQL statement, reading one page of its results.
+// It returns the output, with the token of the next page if any, and an error.
+func ExecuteStatement(dbmgr *DynamoDBManager, input *dynamodb.ExecuteStatementInput) (*dynamodb.ExecuteStatementOutput, error) {
+	output, err := dbmgr.DynamoDBClient.ExecuteStatement(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error executing statement:%s - error:%v", aws.ToString(input.Statement), err)
+		return nil, wrapError("ExecuteStatement", "", err)
+	}
+	return output, nil
+}
+
+// BatchExecuteStatement runs a batch of up to 25 PartiQL statements, all reads or all writes.
+// It returns the output, with the result or error of each statement, and an error if the whole batch fails.
+func BatchExecuteStatement(dbmgr *DynamoDBManager, input *dynamodb.BatchExecuteStatementInput) (*dynamodb.BatchExecuteStatementOutput, error) {
+	output, err := dbmgr.DynamoDBClient.BatchExecuteStatement(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error executing a batch of %d statement(s) - error:%v", len(input.Statements), err)
+		return nil, wrapError("BatchExecuteStatement", "", err)
+	}
+	return output, nil
+}
+
+// CreateTable creates a DynamoDB table, the table is usable once it becomes active, see WaitForTable.
+// It returns the description of the table being created and an error.
+func CreateTable(dbmgr *DynamoDBManager, input *dynamodb.CreateTableInput) (*types.TableDescription, error) {
+	output, err := dbmgr.DynamoDBClient.CreateTable(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error creating table:%s - error:%v", aws.ToString(input.TableName), err)
+		return nil, wrapError("CreateTable", aws.ToString(input.TableName), err)
+	}
+	return output.TableDescription, nil
+}
+
+// WaitForTable waits until a DynamoDB table exists and is active, or the timeout expires.
+// It returns an error if the table isn't active in time.
+func WaitForTable(dbmgr *DynamoDBManager, tableName string, timeout time.Duration) error {
+	waiter := dynamodb.NewTableExistsWaiter(dbmgr.DynamoDBClient)
+	err := waiter.Wait(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String(tableName)}, timeout)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error waiting for table:%s to become active - error:%v", tableName, err)
+		return wrapError("DescribeTable", tableName, err)
+	}
+	return nil
+}
+
+// UpdateTimeToLive enables or disables the expiry of the items of a DynamoDB table based on the given attribute.
+// It returns an error if the update fails.
+func UpdateTimeToLive(dbmgr *DynamoDBManager, tableName string, attributeName string, enabled bool) error {
+	_, err := dbmgr.DynamoDBClient.UpdateTimeToLive(context.Background(), &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attributeName),
+			Enabled:       aws.Bool(enabled),
+		},
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error updating the time to live of table:%s - error:%v", tableName, err)
+		return wrapError("UpdateTimeToLive", tableName, err)
+	}
+	return nil
+}
+
+// CreateBackup creates an on-demand backup of a DynamoDB table.
+// It returns the details of the backup being created and an error.
+func CreateBackup(dbmgr *DynamoDBManager, tableName string, backupName string) (*types.BackupDetails, error) {
+	output, err := dbmgr.DynamoDBClient.CreateBackup(context.Background(), &dynamodb.CreateBackupInput{
+		TableName:  aws.String(tableName),
+		BackupName: aws.String(backupName),
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error creating backup:%s of table:%s - error:%v", backupName, tableName, err)
+		return nil, wrapError("CreateBackup", tableName, err)
+	}
+	return output.BackupDetails, nil
+}
+
+// ListBackups lists the backups of the given type, of a DynamoDB table or of every table if the name is empty,
+// created within the optional time range.
+// It returns the backup summaries of all the pages and an error.
+func ListBackups(dbmgr *DynamoDBManager, tableName string, backupType types.BackupTypeFilter, from *time.Time, to *time.Time) ([]types.BackupSummary, error) {
+	input := &dynamodb.ListBackupsInput{
+		BackupType:          backupType,
+		TimeRangeLowerBound: from,
+		TimeRangeUpperBound: to,
+	}
+	if tableName != "" {
+		input.TableName = aws.String(tableName)
+	}
+
+	var backups []types.BackupSummary
+	for {
+		output, err := dbmgr.DynamoDBClient.ListBackups(context.Background(), input)
+		if err != nil {
+			dbmgr.Logger.Errorf("Error listing the backups of table:%s - error:%v", tableName, err)
+			return nil, wrapError("ListBackups", tableName, err)
+		}
+		backups = append(backups, output.BackupSummaries...)
+		if output.LastEvaluatedBackupArn == nil {
+			return backups, nil
+		}
+		input.ExclusiveStartBackupArn = output.LastEvaluatedBackupArn
+	}
+}
+
+// RestoreTableFromBackup creates a new DynamoDB table from a backup.
+// It returns the description of the table being restored and an error.
+func RestoreTableFromBackup(dbmgr *DynamoDBManager, backupArn string, targetTableName string) (*types.TableDescription, error) {
+	output, err := dbmgr.DynamoDBClient.RestoreTableFromBackup(context.Background(), &dynamodb.RestoreTableFromBackupInput{
+		BackupArn:       aws.String(backupArn),
+		TargetTableName: aws.String(targetTableName),
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error restoring backup:%s to table:%s - error:%v", backupArn, targetTableName, err)
+		return nil, wrapError("RestoreTableFromBackup", targetTableName, err)
+	}
+	return output.TableDescription, nil
+}
+
+// DeleteBackup deletes a backup of a DynamoDB table.
+// It returns an error if the deletion fails.
+func DeleteBackup(dbmgr *DynamoDBManager, backupArn string) error {
+	_, err := dbmgr.DynamoDBClient.DeleteBackup(context.Background(), &dynamodb.DeleteBackupInput{
+		BackupArn: aws.String(backupArn),
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error deleting backup:%s - error:%v", backupArn, err)
+		return wrapError("DeleteBackup", "", err)
+	}
+	return nil
+}
+
+// UpdateContinuousBackups enables or disables the point in time recovery of a DynamoDB table.
+// It returns the updated continuous backups description and an error.
+func UpdateContinuousBackups(dbmgr *DynamoDBManager, tableName string, enabled bool) (*types.ContinuousBackupsDescription, error) {
+	output, err := dbmgr.DynamoDBClient.UpdateContinuousBackups(context.Background(), &dynamodb.UpdateContinuousBackupsInput{
+		TableName: aws.String(tableName),
+		PointInTimeRecoverySpecification: &types.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: aws.Bool(enabled),
+		},
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error updating the point in time recovery of table:%s - error:%v", tableName, err)
+		return nil, wrapError("UpdateContinuousBackups", tableName, err)
+	}
+	return output.ContinuousBackupsDescription, nil
+}
+
+// RestoreTableToPointInTime creates a new DynamoDB table from the state of the source table at the given time.
+// It returns the description of the table being restored and an error.
+func RestoreTableToPointInTime(dbmgr *DynamoDBManager, sourceTableName string, targetTableName string, restoreTime time.Time) (*types.TableDescription, error) {
+	output, err := dbmgr.DynamoDBClient.RestoreTableToPointInTime(context.Background(), &dynamodb.RestoreTableToPointInTimeInput{
+		SourceTableName: aws.String(sourceTableName),
+		TargetTableName: aws.String(targetTableName),
+		RestoreDateTime: aws.Time(restoreTime),
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error restoring table:%s at %s to table:%s - error:%v", sourceTableName, restoreTime.Format(time.RFC3339), targetTableName, err)
+		return nil, wrapError("RestoreTableToPointInTime", targetTableName, err)
+	}
+	return output.TableDescription, nil
+}
+
+// DescribeBackup retrieves the description of a backup, including its status.
+// It returns the backup description and an error.
+func DescribeBackup(dbmgr *DynamoDBManager, backupArn string) (*types.BackupDescription, error) {
+	output, err := dbmgr.DynamoDBClient.DescribeBackup(context.Background(), &dynamodb.DescribeBackupInput{
+		BackupArn: aws.String(backupArn),
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error describing backup:%s - error:%v", backupArn, err)
+		return nil, wrapError("DescribeBackup", "", err)
+	}
+	return output.BackupDescription, nil
+}
+
+// DeleteTable deletes a DynamoDB table and all of its items.
+// It returns the description of the table being deleted and an error.
+func DeleteTable(dbmgr *DynamoDBManager, tableName string) (*types.TableDescription, error) {
+	output, err := dbmgr.DynamoDBClient.DeleteTable(context.Background(), &dynamodb.DeleteTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		dbmgr.Logger.Errorf("Error deleting table:%s - error:%v", tableName, err)
+		return nil, wrapError("DeleteTable", tableName, err)
+	}
+	return output.TableDescription, nil
+}
+
+// UpdateTable applies the changes of the input to a DynamoDB table, the table is updating until it becomes active again, see WaitForTable.
+// It returns the description of the table being updated and an error.
+func UpdateTable(dbmgr *DynamoDBManager, input *dynamodb.UpdateTableInput) (*types.TableDescription, error) {
+	output, err := dbmgr.DynamoDBClient.UpdateTable(context.Background(), input)
+	if err != nil {
+		dbmgr.Logger.Errorf("Error updating table:%s - error:%v", aws.ToString(input.TableName), err)
+		return nil, wrapError("UpdateTable", aws.ToString(input.TableName), err)
+	}
+	return output.TableDescription, nil
 }
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	DefaultSessionName = "dynamodb-manager"
+	// DefaultRoleDuration is the duration of the assumed role sessions, the maximum session duration of a role by default,
+	// rather than the 15 minutes of the SDK which long exports and imports would outlast
+	DefaultRoleDuration = time.Hour
+	// MinRoleDuration and MaxRoleDuration are the bounds of the session duration accepted by STS
+	MinRoleDuration = 15 * time.Minute
+	MaxRoleDuration = 12 * time.Hour
+	// credentialsExpiryWindow is how long before their expiry cached credentials are refreshed
+	credentialsExpiryWindow = 5 * time.Minute
+)
+
+var STSNewFromConfig = sts.NewFromConfig
+
+// MFATokenProvider prompts for the MFA token code when assuming a role protected by MFA.
+var MFATokenProvider = stscreds.StdinTokenProvider
+
+// CredentialsCacheDir returns the directory where the temporary credentials are cached between invocations.
+var CredentialsCacheDir = func() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "dynamodb-manager", "credentials"), nil
+}
+
+// loadOptions converts the manager options into the options used to load the AWS config.
+func loadOptions(opts ManagerOptions) []func(*config.LoadOptions) error {
+	var optFns []func(*config.LoadOptions) error
+
+	if opts.Profile != "" {
+		optFns = append(optFns, config.WithSharedConfigProfile(opts.Profile))
+	}
+	if opts.Region != "" {
+		optFns = append(optFns, config.WithRegion(opts.Region))
+	}
+	// Prompt for the MFA token of roles assumed through the shared config profile as well
+	optFns = append(optFns, config.WithAssumeRoleCredentialOptions(func(o *stscreds.AssumeRoleOptions) {
+		o.TokenProvider = MFATokenProvider
+	}))
+	return optFns
+}
+
+// setupCredentials replaces the credentials of the AWS config with the assume-role credentials
+// requested by the manager options, and caches the temporary credentials on disk if enabled.
+func setupCredentials(cfg *aws.Config, opts ManagerOptions) {
+	provider := cfg.Credentials
+
+	if opts.RoleArn != "" {
+		stsClient := STSNewFromConfig(*cfg)
+		provider = stscreds.NewAssumeRoleProvider(stsClient, opts.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = DefaultSessionName
+			if opts.SessionName != "" {
+				o.RoleSessionName = opts.SessionName
+			}
+			o.Duration = DefaultRoleDuration
+			if opts.RoleDuration != 0 {
+				o.Duration = opts.RoleDuration
+			}
+			if opts.ExternalID != "" {
+				o.ExternalID = aws.String(opts.ExternalID)
+			}
+			if opts.MFASerial != "" {
+				o.SerialNumber = aws.String(opts.MFASerial)
+				o.TokenProvider = MFATokenProvider
+			}
+		})
+	}
+
+	// Credentials of the default chain aren't cached as their source may change between invocations
+	if opts.CacheCredentials && provider != nil && (opts.RoleArn != "" || opts.Profile != "") {
+		provider = &fileCredentialsCache{
+			key:      credentialsCacheKey(opts),
+			provider: provider,
+		}
+	}
+
+	if provider != nil {
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+}
+
+// credentialsCacheKey identifies the cached credentials of the profile, region and role session given in the manager options.
+func credentialsCacheKey(opts ManagerOptions) string {
+	identity := strings.Join([]string{opts.Profile, opts.Region, opts.RoleArn, opts.ExternalID, opts.MFASerial, opts.SessionName,
+		opts.RoleDuration.String()}, "|")
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:])
+}
+
+// fileCredentialsCache persists temporary credentials on disk, so an MFA token isn't needed on every invocation.
+type fileCredentialsCache struct {
+	key      string
+	provider aws.CredentialsProvider
+}
+
+// Retrieve returns the cached credentials if they are still valid, otherwise it retrieves and caches new ones.
+func (c *fileCredentialsCache) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if creds, err := c.load(); err == nil && creds.Expires.After(time.Now().Add(credentialsExpiryWindow)) {
+		return creds, nil
+	}
+
+	creds, err := c.provider.Retrieve(ctx)
+	if err != nil {
+		return creds, err
+	}
+	// Long-term credentials are never written to the cache
+	if creds.CanExpire {
+		c.store(creds)
+	}
+	return creds, nil
+}
+
+// path returns the file of the cached credentials.
+func (c *fileCredentialsCache) path() (string, error) {
+	dir, err := CredentialsCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, c.key+".json"), nil
+}
+
+// load reads the cached credentials, it returns an error if there are none.
+func (c *fileCredentialsCache) load() (aws.Credentials, error) {
+	var creds aws.Credentials
+	path, err := c.path()
+	if err != nil {
+		return creds, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return creds, err
+	}
+	err = json.Unmarshal(content, &creds)
+	return creds, err
+}
+
+// store writes the credentials to the cache, readable by the current user only.
+// Failing to cache the credentials isn't fatal, they are requested again on the next invocation.
+func (c *fileCredentialsCache) store(creds aws.Credentials) {
+	path, err := c.path()
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	content, err := json.Marshal(creds)
+	if err != nil {
+		return
+	}
+	os.WriteFile(path, content, 0600)
+}
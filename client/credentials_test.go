@@ -0,0 +1,35 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCredentialsCacheKey(t *testing.T) {
+	base := ManagerOptions{Profile: "dev", Region: "eu-west-1", RoleArn: "arn:aws:iam::123456789012:role/admin", SessionName: "dynamodb-manager"}
+	if credentialsCacheKey(base) != credentialsCacheKey(base) {
+		t.Error("credentialsCacheKey() differs for the same options")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(o *ManagerOptions)
+	}{
+		{"profile", func(o *ManagerOptions) { o.Profile = "prod" }},
+		{"region", func(o *ManagerOptions) { o.Region = "us-east-1" }},
+		{"role", func(o *ManagerOptions) { o.RoleArn = "arn:aws:iam::123456789012:role/reader" }},
+		{"external id", func(o *ManagerOptions) { o.ExternalID = "partner" }},
+		{"mfa serial", func(o *ManagerOptions) { o.MFASerial = "arn:aws:iam::123456789012:mfa/user" }},
+		{"session name", func(o *ManagerOptions) { o.SessionName = "other" }},
+		{"role duration", func(o *ManagerOptions) { o.RoleDuration = 2 * time.Hour }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+			if credentialsCacheKey(opts) == credentialsCacheKey(base) {
+				t.Errorf("credentialsCacheKey() ignores the %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/smithy-go"
+)
+
+// Sentinel errors returned by the client package, they can be matched with errors.Is
+var (
+	ErrTableNotFound     = errors.New("table not found")
+	ErrThrottled         = errors.New("request throttled")
+	ErrResourceInUse     = errors.New("resource in use")
+	ErrModeSwitchTooSoon = errors.New("billing mode switched too recently")
+	ErrLimitExceeded     = errors.New("account or table limit exceeded")
+	ErrAccessDenied      = errors.New("access denied")
+	ErrValidation        = errors.New("invalid request")
+)
+
+// Outcome errors shared by the commands built on the client package, they can be matched with errors.Is
+var (
+	ErrNoMatches       = errors.New("no matching tables")
+	ErrPartialFailure  = errors.New("partial failure")
+	ErrPolicyViolation = errors.New("policy violation")
+	ErrPendingChanges  = errors.New("dry run has pending changes")
+)
+
+// modeSwitchMessage matches the message of the error DynamoDB returns when the billing mode is switched again too soon,
+// e.g. "Subscriber limit exceeded: Updates to PayPerRequest mode are limited to once in 1 day(s)."
+var modeSwitchMessage = regexp.MustCompile(`(?i)updates? to (PayPerRequest|PAY_PER_REQUEST|Provisioned|PROVISIONED) (billing )?mode (are|is) limited to`)
+
+// OperationError is returned when a DynamoDB operation fails.
+// It wraps both the matching sentinel error, if any, and the SDK error that caused the failure,
+// so callers can use errors.Is with the sentinels and errors.As with the SDK error types.
+type OperationError struct {
+	Operation string // DynamoDB API operation, e.g. DescribeTable
+	Table     string // Table or resource the operation was called for, may be empty
+	Kind      error  // Sentinel error classifying the failure, nil if unknown
+	Err       error  // Underlying SDK error
+}
+
+// Error formats the operation, table and cause of the failure.
+func (e *OperationError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("%s failed: %v", e.Operation, e.Err)
+	}
+	return fmt.Sprintf("%s failed for table:%s - %v", e.Operation, e.Table, e.Err)
+}
+
+// Unwrap returns the sentinel error and the SDK error wrapped by the operation error.
+func (e *OperationError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// wrapError wraps an SDK error returned by 'operation' for 'table' in an OperationError.
+// It returns nil if err is nil.
+func wrapError(operation string, table string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &OperationError{
+		Operation: operation,
+		Table:     table,
+		Kind:      classifyError(err),
+		Err:       err,
+	}
+}
+
+// classifyError maps the error code of an SDK error to one of the sentinel errors.
+// It returns nil if the error doesn't match any of them.
+func classifyError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+
+	switch apiErr.ErrorCode() {
+	case "ResourceNotFoundException", "TableNotFoundException":
+		return ErrTableNotFound
+	case "ResourceInUseException", "TableInUseException":
+		return ErrResourceInUse
+	case "AccessDeniedException", "UnrecognizedClientException":
+		return ErrAccessDenied
+	case "ThrottlingException", "ProvisionedThroughputExceededException", "RequestLimitExceeded", "TooManyRequestsException":
+		return ErrThrottled
+	case "LimitExceededException", "ValidationException":
+		// Billing mode can be switched only a limited number of times within a given period,
+		// DynamoDB reports it as a limit or validation error
+		if modeSwitchMessage.MatchString(apiErr.ErrorMessage()) {
+			return ErrModeSwitchTooSoon
+		}
+		if apiErr.ErrorCode() == "LimitExceededException" {
+			// E.g. too many tables or concurrent control plane operations, still failing once the retries are exhausted
+			return ErrLimitExceeded
+		}
+		return ErrValidation
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		message string
+		want    error
+	}{
+		{"table not found", "ResourceNotFoundException", "Requested resource not found", ErrTableNotFound},
+		{"resource in use", "ResourceInUseException", "Table already exists", ErrResourceInUse},
+		{"access denied", "AccessDeniedException", "User is not authorized", ErrAccessDenied},
+		{"throttled", "ThrottlingException", "Rate exceeded", ErrThrottled},
+		{"throughput exceeded", "ProvisionedThroughputExceededException", "The level of configured provisioned throughput was exceeded", ErrThrottled},
+		{"mode switch to on-demand", "LimitExceededException",
+			"Subscriber limit exceeded: Updates to PayPerRequest mode are limited to once in 1 day(s). Last update at Mon Feb 19 10:00:00 UTC 2024", ErrModeSwitchTooSoon},
+		{"mode switch to provisioned", "ValidationException",
+			"Subscriber limit exceeded: Updates to Provisioned mode are limited to once in 1 day(s).", ErrModeSwitchTooSoon},
+		{"concurrent table operations", "LimitExceededException",
+			"Subscriber limit exceeded: Only 50 tables can be created, updated, or deleted simultaneously", ErrLimitExceeded},
+		{"limit message mentioning a mode once", "LimitExceededException", "Too many operations in this mode at once", ErrLimitExceeded},
+		{"validation", "ValidationException", "One or more parameter values were invalid", ErrValidation},
+		{"unknown code", "InternalServerError", "Internal server error", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &smithy.GenericAPIError{Code: tt.code, Message: tt.message}
+			if got := classifyError(err); got != tt.want {
+				t.Errorf("classifyError(%s: %s) = %v, want %v", tt.code, tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorNotAPIError(t *testing.T) {
+	if got := classifyError(errors.New("connection reset")); got != nil {
+		t.Errorf("classifyError() = %v, want nil", got)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	if wrapError("DescribeTable", "orders", nil) != nil {
+		t.Fatal("wrapError(nil) is not nil")
+	}
+
+	apiErr := &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: "Requested resource not found"}
+	err := wrapError("DescribeTable", "orders", apiErr)
+	if !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("errors.Is(%v, ErrTableNotFound) = false", err)
+	}
+	var target smithy.APIError
+	if !errors.As(err, &target) || target.ErrorCode() != "ResourceNotFoundException" {
+		t.Errorf("errors.As(%v) doesn't return the SDK error", err)
+	}
+	if want := "DescribeTable failed for table:orders - api error ResourceNotFoundException: Requested resource not found"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Retry modes supported by the DynamoDB client
+const (
+	RetryModeStandard = "standard"
+	RetryModeAdaptive = "adaptive"
+)
+
+const (
+	DefaultMaxAttempts = 3
+	DefaultMaxBackoff  = 20 * time.Second
+	DefaultRateBurst   = 1
+	// AllOperations is the rate limit key applied to operations without their own limit
+	AllOperations = "*"
+)
+
+// RetryConfig describes how the DynamoDB client retries failed requests and
+// how it throttles outgoing requests on the client side.
+type RetryConfig struct {
+	MaxAttempts int                // Maximum attempts per request, including the first one
+	MaxBackoff  time.Duration      // Upper bound of the delay between two attempts
+	Mode        string             // RetryModeStandard or RetryModeAdaptive
+	RateLimits  map[string]float64 // Requests per second keyed by operation name, AllOperations for the rest
+	RateBurst   int                // Requests allowed in a burst by each rate limiter
+}
+
+// DefaultRetryConfig returns the retry configuration matching the SDK defaults without client-side rate limits.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: DefaultMaxAttempts,
+		MaxBackoff:  DefaultMaxBackoff,
+		Mode:        RetryModeStandard,
+		RateBurst:   DefaultRateBurst,
+	}
+}
+
+// ParseRateLimits parses a rate limit spec such as "DescribeTable=5,ListTagsOfResource=2".
+// A bare number without an operation name applies to all operations.
+// It returns the limits keyed by operation name and an error if the spec is malformed.
+func ParseRateLimits(spec string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	if strings.TrimSpace(spec) == "" {
+		return limits, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		operation, value := AllOperations, entry
+		if idx := strings.Index(entry, "="); idx >= 0 {
+			operation, value = strings.TrimSpace(entry[:idx]), strings.TrimSpace(entry[idx+1:])
+		}
+		if operation == "" {
+			return nil, errors.New(fmt.Sprintf("missing operation name in rate limit:%s", entry))
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 {
+			return nil, errors.New(fmt.Sprintf("invalid rate limit:%s, a positive number of requests per second is expected", entry))
+		}
+		limits[operation] = rate
+	}
+	return limits, nil
+}
+
+// Validate checks the retry configuration and returns an error if any value is out of range.
+func (r RetryConfig) Validate() error {
+	if r.MaxAttempts < 0 {
+		return errors.New(fmt.Sprintf("max attempts must not be negative:%d", r.MaxAttempts))
+	}
+	if r.MaxBackoff < 0 {
+		return errors.New(fmt.Sprintf("max backoff must not be negative:%v", r.MaxBackoff))
+	}
+	if r.Mode != "" && r.Mode != RetryModeStandard && r.Mode != RetryModeAdaptive {
+		return errors.New(fmt.Sprintf("unrecognized retry mode provided:%s", r.Mode))
+	}
+	if r.RateBurst < 0 {
+		return errors.New(fmt.Sprintf("rate burst must not be negative:%d", r.RateBurst))
+	}
+	for operation, rate := range r.RateLimits {
+		if rate <= 0 {
+			return errors.New(fmt.Sprintf("rate limit of %s must be positive:%v", operation, rate))
+		}
+	}
+	return nil
+}
+
+// noRetryModeSwitch stops the retries of the LimitExceededException returned when the billing mode is switched again too soon,
+// which the SDK retries as a throttling error although retries can't lift it. The other limit errors are retried.
+var noRetryModeSwitch = retry.IsErrorRetryableFunc(func(err error) aws.Ternary {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "LimitExceededException" && modeSwitchMessage.MatchString(apiErr.ErrorMessage()) {
+		return aws.FalseTernary
+	}
+	return aws.UnknownTernary
+})
+
+// NewRetryer builds the SDK retryer described by the retry configuration.
+// Throttling errors such as ThrottlingException and LimitExceededException are retried by both modes,
+// the adaptive mode additionally slows down the request rate when throttled. A billing mode switched too soon isn't retried.
+func NewRetryer(r RetryConfig) aws.Retryer {
+	standardOptions := func(o *retry.StandardOptions) {
+		o.Retryables = append([]retry.IsErrorRetryable{noRetryModeSwitch}, o.Retryables...)
+		if r.MaxAttempts > 0 {
+			o.MaxAttempts = r.MaxAttempts
+		}
+		if r.MaxBackoff > 0 {
+			o.MaxBackoff = r.MaxBackoff
+		}
+	}
+
+	if r.Mode == RetryModeAdaptive {
+		return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+			o.StandardOptions = append(o.StandardOptions, standardOptions)
+		})
+	}
+	return retry.NewStandard(standardOptions)
+}
+
+// TokenBucket is a client-side token bucket refilled at a constant rate.
+// It is safe for concurrent use.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a full token bucket refilled with 'rate' tokens per second and holding up to 'burst' tokens.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until 'n' tokens are available and takes them from the bucket.
+// Requests larger than the burst are admitted once the bucket is full and leave it in debt.
+// It returns the context error if the context is done before the tokens are available.
+func (b *TokenBucket) Wait(ctx context.Context, n float64) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+
+		needed := n
+		if needed > b.burst {
+			needed = b.burst
+		}
+		if b.tokens >= needed {
+			b.tokens -= n
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((needed - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimiter throttles every attempt of an operation with the token bucket configured for it.
+type rateLimiter struct {
+	buckets  map[string]*TokenBucket
+	fallback *TokenBucket
+}
+
+// newRateLimiter creates the rate limiter for the retry configuration, it returns nil if no limit is configured.
+func newRateLimiter(r RetryConfig) *rateLimiter {
+	if len(r.RateLimits) == 0 {
+		return nil
+	}
+
+	limiter := rateLimiter{buckets: make(map[string]*TokenBucket)}
+	for operation, rate := range r.RateLimits {
+		if operation == AllOperations {
+			limiter.fallback = NewTokenBucket(rate, r.RateBurst)
+		} else {
+			limiter.buckets[operation] = NewTokenBucket(rate, r.RateBurst)
+		}
+	}
+	return &limiter
+}
+
+// ID identifies the middleware in the SDK middleware stack.
+func (l *rateLimiter) ID() string {
+	return "ClientSideRateLimiter"
+}
+
+// HandleFinalize waits for a token of the current operation before sending each attempt.
+func (l *rateLimiter) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	bucket, exists := l.buckets[awsmiddleware.GetOperationName(ctx)]
+	if !exists {
+		bucket = l.fallback
+	}
+	if bucket != nil {
+		if err := bucket.Wait(ctx, 1); err != nil {
+			return middleware.FinalizeOutput{}, middleware.Metadata{}, errors.New(fmt.Sprintf("client-side rate limiter: %v", err))
+		}
+	}
+	return next.HandleFinalize(ctx, in)
+}
+
+// addToStack registers the rate limiter after the retry middleware so every attempt is throttled.
+func (l *rateLimiter) addToStack(stack *middleware.Stack) error {
+	return stack.Finalize.Insert(l, "Retry", middleware.After)
+}
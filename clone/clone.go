@@ -0,0 +1,378 @@
+package clone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/schema"
+)
+
+const (
+	DefaultSegments   = 4
+	DefaultMaxRetries = 8
+	batchSize         = 25 // Maximum number of requests of a BatchWriteItem call
+	tableTimeout      = 15 * time.Minute
+	initialBackoff    = 100 * time.Millisecond
+	maxBackoff        = 5 * time.Second
+)
+
+var (
+	DescribeTableClient      = client.DescribeTable
+	DescribeTimeToLiveClient = client.DescribeTimeToLive
+	GetTableTagsClient       = client.GetTableTags
+	CreateTableClient        = client.CreateTable
+	WaitForTableClient       = client.WaitForTable
+	UpdateTimeToLiveClient   = client.UpdateTimeToLive
+	ScanPageClient           = client.ScanPage
+	BatchWriteItemClient     = client.BatchWriteItem
+)
+
+// Options describes a copy of a table.
+type Options struct {
+	Source        string
+	Destination   string
+	Segments      int     // Number of segments scanned in parallel
+	ReadCapacity  float64 // Read capacity units consumed per second at most on the source, unlimited if 0
+	WriteCapacity float64 // Write capacity units consumed per second at most on the destination, unlimited if 0
+	MaxRetries    int     // Retries of the unprocessed items of a batch before the copy fails
+	SchemaOnly    bool    // Only create the destination table
+	DataOnly      bool    // Only copy the items into the existing destination table
+	Verify        bool    // Compare the item counts and checksums of both tables once copied
+	DryRun        bool
+	Yes           bool
+}
+
+// Validate checks the copy options and applies the defaults.
+// It returns an error if any of them is invalid.
+func (o *Options) Validate() error {
+	if o.Source == "" || o.Destination == "" {
+		return errors.New("source and destination tables must be provided!")
+	}
+	if o.SchemaOnly && o.DataOnly {
+		return errors.New("schema-only can't be used together with data-only!")
+	}
+	if o.Segments == 0 {
+		o.Segments = DefaultSegments
+	}
+	if o.Segments < 0 || o.Segments > 1000000 {
+		return errors.New(fmt.Sprintf("segments must be between 1 and 1000000:%d", o.Segments))
+	}
+	if o.ReadCapacity < 0 || o.WriteCapacity < 0 {
+		return errors.New("read and write capacity must not be negative!")
+	}
+	if o.MaxRetries < 0 {
+		return errors.New(fmt.Sprintf("max retries must not be negative:%d", o.MaxRetries))
+	}
+	return nil
+}
+
+// newLimiter creates the token bucket capping the consumed capacity units per second, nil if unlimited.
+func newLimiter(capacity float64) *client.TokenBucket {
+	if capacity <= 0 {
+		return nil
+	}
+	return client.NewTokenBucket(capacity, int(capacity))
+}
+
+// consume waits until the capacity consumed by a request is available in the budget of the limiter.
+func consume(ctx context.Context, limiter *client.TokenBucket, capacity []types.ConsumedCapacity) error {
+	if limiter == nil {
+		return nil
+	}
+	var units float64
+	for _, consumed := range capacity {
+		units += aws.ToFloat64(consumed.CapacityUnits)
+	}
+	return limiter.Wait(ctx, units)
+}
+
+// scanTable scans a table with a parallel segmented scan and hands every page to 'handle', called concurrently by the segments.
+// The first failing segment stops the others.
+// It returns the errors of the segments joined.
+func scanTable(ctx context.Context, dbmgr *client.DynamoDBManager, table string, segments int, consistentRead bool,
+	limiter *client.TokenBucket, handle func(segment int, page []items.Item) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, segments)
+	for segment := 0; segment < segments; segment++ {
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			input := &dynamodb.ScanInput{
+				TableName:              aws.String(table),
+				Segment:                aws.Int32(int32(segment)),
+				TotalSegments:          aws.Int32(int32(segments)),
+				ConsistentRead:         aws.Bool(consistentRead),
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			}
+			for ctx.Err() == nil {
+				page, err := ScanPageClient(ctx, dbmgr, input)
+				if err == nil {
+					err = handle(segment, page.Items)
+				}
+				if err == nil && page.ConsumedCapacity != nil {
+					err = consume(ctx, limiter, []types.ConsumedCapacity{*page.ConsumedCapacity})
+				}
+				if err != nil {
+					errs[segment] = err
+					cancel()
+					return
+				}
+				if len(page.LastEvaluatedKey) == 0 {
+					return
+				}
+				input.ExclusiveStartKey = page.LastEvaluatedKey
+			}
+			errs[segment] = ctx.Err()
+		}(segment)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// backoff returns the delay before the given retry, exponential with full jitter.
+func backoff(retry int) time.Duration {
+	delay := maxBackoff
+	if retry < 16 {
+		delay = initialBackoff << retry
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// writeItems writes the items to the table with BatchWriteItem calls, retrying the unprocessed items with backoff.
+// It returns an error if a call fails or items are still unprocessed after the retries.
+func writeItems(ctx context.Context, dbmgr *client.DynamoDBManager, table string, page []items.Item, maxRetries int, limiter *client.TokenBucket) error {
+	for start := 0; start < len(page); start += batchSize {
+		end := start + batchSize
+		if end > len(page) {
+			end = len(page)
+		}
+		requests := make([]types.WriteRequest, 0, end-start)
+		for _, item := range page[start:end] {
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+		}
+
+		for retry := 0; len(requests) > 0; retry++ {
+			output, err := BatchWriteItemClient(dbmgr, &dynamodb.BatchWriteItemInput{
+				RequestItems:           map[string][]types.WriteRequest{table: requests},
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			})
+			if err != nil && !errors.Is(err, client.ErrThrottled) {
+				return err
+			}
+			if err == nil {
+				requests = output.UnprocessedItems[table]
+				if err = consume(ctx, limiter, output.ConsumedCapacity); err != nil {
+					return err
+				}
+			}
+			if len(requests) == 0 {
+				break
+			}
+			if retry >= maxRetries {
+				return errors.New(fmt.Sprintf("%d item(s) still unprocessed after %d retries", len(requests), retry))
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff(retry)):
+			}
+		}
+	}
+	return nil
+}
+
+// createTable creates the destination table like the source one, with its tags but the reserved ones and its time to live, and waits until it is active.
+func createTable(src *client.DynamoDBManager, dst *client.DynamoDBManager, table *types.TableDescription, opts *Options) error {
+	input := schema.CreateTableInput(table, opts.Destination)
+	tags, err := GetTableTagsClient(src, aws.ToString(table.TableArn))
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		// The tags reserved for AWS can't be set on the new table
+		if client.IsReservedTag(aws.ToString(tag.Key)) {
+			dst.Logger.Infof("Skipped the reserved tag:%s of table:%s", aws.ToString(tag.Key), opts.Source)
+			continue
+		}
+		input.Tags = append(input.Tags, tag)
+	}
+	ttl, err := DescribeTimeToLiveClient(src, opts.Source)
+	if err != nil {
+		return err
+	}
+	ttlEnabled := ttl != nil && (ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabled || ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabling)
+
+	dst.Logger.Infof("Begin to create table:%s like table:%s with %d global and %d local index(es), ...",
+		opts.Destination, opts.Source, len(input.GlobalSecondaryIndexes), len(input.LocalSecondaryIndexes))
+	if _, err = CreateTableClient(dst, input); err != nil {
+		return err
+	}
+	if err = WaitForTableClient(dst, opts.Destination, tableTimeout); err != nil {
+		return err
+	}
+	if ttlEnabled {
+		if err = UpdateTimeToLiveClient(dst, opts.Destination, aws.ToString(ttl.AttributeName), true); err != nil {
+			return err
+		}
+	}
+	dst.Logger.Infof("Created table:%s", opts.Destination)
+	return nil
+}
+
+// copyItems copies the items of the source table into the destination one.
+// It returns the number of items copied and an error.
+func copyItems(ctx context.Context, src *client.DynamoDBManager, dst *client.DynamoDBManager, opts *Options) (int64, error) {
+	readLimiter, writeLimiter := newLimiter(opts.ReadCapacity), newLimiter(opts.WriteCapacity)
+	var copied int64
+
+	src.Logger.Infof("Begin to copy the items of table:%s into table:%s with %d segment(s), ...", opts.Source, opts.Destination, opts.Segments)
+	err := scanTable(ctx, src, opts.Source, opts.Segments, false, readLimiter, func(segment int, page []items.Item) error {
+		if err := writeItems(ctx, dst, opts.Destination, page, opts.MaxRetries, writeLimiter); err != nil {
+			return err
+		}
+		total := atomic.AddInt64(&copied, int64(len(page)))
+		src.Logger.Debugf("Segment:%d - copied %d item(s), %d in total", segment, len(page), total)
+		return nil
+	})
+	return copied, err
+}
+
+// Digest sums up the items of a table: their count and the sum of their checksums.
+type Digest struct {
+	Items    int64
+	Checksum uint64
+}
+
+// TableDigest scans a table with strongly consistent reads and computes its digest.
+// It returns the digest and an error.
+func TableDigest(ctx context.Context, dbmgr *client.DynamoDBManager, table string, segments int, readCapacity float64) (Digest, error) {
+	digests := make([]Digest, segments)
+	err := scanTable(ctx, dbmgr, table, segments, true, newLimiter(readCapacity), func(segment int, page []items.Item) error {
+		for _, item := range page {
+			digests[segment].Items++
+			digests[segment].Checksum += items.Checksum(item)
+		}
+		return nil
+	})
+
+	var digest Digest
+	for _, d := range digests {
+		digest.Items += d.Items
+		digest.Checksum += d.Checksum
+	}
+	return digest, err
+}
+
+// verify compares the digests of the source and destination tables.
+// It returns an error if they don't match.
+func verify(ctx context.Context, src *client.DynamoDBManager, dst *client.DynamoDBManager, opts *Options) error {
+	src.Logger.Infof("Begin to verify table:%s against table:%s, ...", opts.Destination, opts.Source)
+	var srcDigest, dstDigest Digest
+	var srcErr, dstErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		srcDigest, srcErr = TableDigest(ctx, src, opts.Source, opts.Segments, opts.ReadCapacity)
+	}()
+	go func() {
+		defer wg.Done()
+		dstDigest, dstErr = TableDigest(ctx, dst, opts.Destination, opts.Segments, opts.ReadCapacity)
+	}()
+	wg.Wait()
+	if err := errors.Join(srcErr, dstErr); err != nil {
+		return err
+	}
+
+	if srcDigest != dstDigest {
+		return errors.New(fmt.Sprintf("verification failed, table:%s has %d item(s) with checksum:%016x, table:%s has %d item(s) with checksum:%016x",
+			opts.Source, srcDigest.Items, srcDigest.Checksum, opts.Destination, dstDigest.Items, dstDigest.Checksum))
+	}
+	src.Logger.Infof("Verified table:%s - %d item(s) with checksum:%016x", opts.Destination, dstDigest.Items, dstDigest.Checksum)
+	return nil
+}
+
+// ExecuteCopy copies a table: it creates the destination table with the key schema, indexes, billing mode and capacity,
+// time to live and tags of the source table, then copies the items with a parallel scan and batch writes.
+// It takes the DynamoDBManager of the source table, the one of the destination table, which may be the same,
+// and the copy options as input.
+// With Verify set, the item counts and checksums of both tables are compared once the items are copied,
+// the source table must not change during the copy for them to match.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, or an error if the copy fails.
+func ExecuteCopy(src *client.DynamoDBManager, dst *client.DynamoDBManager, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	table, err := DescribeTableClient(src, opts.Source)
+	if err != nil {
+		return err
+	}
+	_, err = DescribeTableClient(dst, opts.Destination)
+	switch {
+	case opts.DataOnly && err != nil:
+		return err
+	case !opts.DataOnly && err == nil:
+		return errors.New(fmt.Sprintf("destination table:%s already exists, use data-only to copy the items into it", opts.Destination))
+	case !opts.DataOnly && !errors.Is(err, client.ErrTableNotFound):
+		return err
+	}
+
+	if opts.DryRun {
+		itemCount := aws.ToInt64(table.ItemCount)
+		plan := fmt.Sprintf("create table:%s and copy about %d item(s) into it", opts.Destination, itemCount)
+		if opts.SchemaOnly {
+			plan = fmt.Sprintf("create table:%s", opts.Destination)
+		} else if opts.DataOnly {
+			plan = fmt.Sprintf("copy about %d item(s) into table:%s", itemCount, opts.Destination)
+		}
+		return fmt.Errorf("copy of table:%s would %s - %w", opts.Source, plan, client.ErrPendingChanges)
+	}
+
+	if opts.DataOnly {
+		question := fmt.Sprintf("Copy the items of table:%s into the existing table:%s, overwriting the items with the same keys?", opts.Source, opts.Destination)
+		if err = prompt.Approve(opts.Yes, question); err != nil {
+			return err
+		}
+	} else if err = createTable(src, dst, table, &opts); err != nil {
+		return err
+	}
+	if opts.SchemaOnly {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	copied, err := copyItems(ctx, src, dst, &opts)
+	if err != nil {
+		return fmt.Errorf("Failed to copy the items of table:%s after %d item(s) - %w", opts.Source, copied, err)
+	}
+	src.Logger.Infof("Copied %d item(s) of table:%s into table:%s", copied, opts.Source, opts.Destination)
+
+	if opts.Verify {
+		return verify(ctx, src, dst, &opts)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/audit"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+var ExecuteAuditTagsTask = audit.ExecuteAuditTags
+
+var auditSchemaFile string
+var auditTables []string
+var auditFix bool
+
+var auditCmd = &cobra.Command{
+	Use:   "audit (tags)",
+	Short: "Audit DynamoDB tables against a policy",
+}
+
+var auditTagsCmd = &cobra.Command{
+	Use:   "tags --schema schema_file [--fix] [--table table_name... | --search table_name | --tag tag_value] [--dry-run] [--yes]",
+	Short: "Audit the tags of the tables against a required-tags schema",
+	Long: `Audit the tags of every table, or of the selected ones, against a YAML or JSON schema:
+
+caseSensitiveKeys: true
+ignoreValueCase: false
+tags:
+  - key: env
+    required: true
+    allowed: [dev, staging, prod]
+    case: lower
+    default: dev
+  - key: cost-center
+    required: true
+    pattern: '^CC-[0-9]{4}$'
+
+With --fix, the default values of the missing tags are shown and applied once confirmed, unless --yes is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if auditSchemaFile == "" {
+			return errors.New("Invalid command line arguments: schema must be provided!")
+		}
+		if len(auditTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		action = AuditTags
+		return nil
+	},
+}
+
+// runAuditTags audits the tags of the selected tables, or of every table if none is selected.
+func runAuditTags(dbmgr *client.DynamoDBManager) error {
+	return runOnTables(dbmgr, auditTables, func(tables []string) error {
+		return ExecuteAuditTagsTask(dbmgr, auditSchemaFile, tables, auditFix, viper.GetBool("dry-run"), viper.GetBool("yes"), viper.GetString("output"))
+	})
+}
+
+// initAuditCommand registers the audit command and its subcommands.
+func initAuditCommand() {
+	auditTagsCmd.Flags().StringVar(&auditSchemaFile, "schema", "", "Schema file describing the required tags")
+	auditTagsCmd.Flags().StringSliceVar(&auditTables, "table", nil, "Name of the table to audit, can be repeated")
+	auditTagsCmd.Flags().BoolVar(&auditFix, "fix", false, "Apply the default values of the missing tags")
+	auditCmd.AddCommand(auditTagsCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/backup"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+var ExecuteBackupCreateTask = backup.ExecuteCreate
+var ExecuteBackupListTask = backup.ExecuteList
+var ExecuteBackupRestoreTask = backup.ExecuteRestore
+var ExecuteBackupDeleteTask = backup.ExecuteDelete
+var ExecuteBackupPruneTask = backup.ExecutePrune
+
+var backupTables []string
+var backupNameTemplate string
+var backupTypeStr string
+var backupFromStr string
+var backupToStr string
+var backupTarget string
+var backupWait bool
+var backupArns []string
+var backupPolicyFile string
+
+// Parsed backup filters
+var backupType types.BackupTypeFilter
+var backupFrom *time.Time
+var backupTo *time.Time
+
+var backupCmd = &cobra.Command{
+	Use:   "backup (create, list, restore, delete, prune)",
+	Short: "Create, list, restore, delete and prune on-demand backups of DynamoDB tables",
+	Long:  "Create, list, restore and delete on-demand backups of a table, or of every table matched by the search and tag conditions",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create (--table table_name... | --search table_name | --tag tag_value) [--name template] [--dry-run]",
+	Short: "Create an on-demand backup of the tables",
+	Long: `Create an on-demand backup of every selected table, e.g. of every table tagged prod before a release:
+  backup create --tag prod
+The backups are named after the template, {table} and {timestamp} are replaced by the table name and the UTC creation time.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkTableSelection(backupTables); err != nil {
+			return err
+		}
+		if err := backup.ValidateNameTemplate(backupNameTemplate); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = BackupCreate
+		return nil
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list [--table table_name... | --search table_name | --tag tag_value] [--from time] [--to time] [--type (user, system, aws-backup, all)]",
+	Short: "List the backups of the tables",
+	Long:  "List the backups of the selected tables, or of every table, created within the optional time range given as RFC 3339 or YYYY-MM-DD",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkBackupFilters(); err != nil {
+			return err
+		}
+		action = BackupList
+		return nil
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore (backup_arn | --table table_name) --target table_name [--wait] [--dry-run]",
+	Short: "Restore a backup to a new table",
+	Long:  "Restore a backup to a new table, with --table the latest available backup of the table is restored",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backupArns = args
+		if (len(args) == 0) == (len(backupTables) == 0) {
+			return errors.New("Invalid command line arguments: either a backup arn or a table must be provided!")
+		}
+		if len(backupTables) > 1 {
+			return errors.New("Invalid command line arguments: only one table can be restored at a time!")
+		}
+		action = BackupRestore
+		return nil
+	},
+}
+
+var backupDeleteCmd = &cobra.Command{
+	Use:   "delete [backup_arn...] [--table table_name... | --search table_name | --tag tag_value] [--from time] [--to time] [--type (user, system, aws-backup, all)] [--dry-run] [--yes]",
+	Short: "Delete backups",
+	Long: `Delete the given backups, or the backups of the selected tables created within the optional time range.
+The backups to delete are shown and deleted once confirmed, unless --yes is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backupArns = args
+		searching := viper.GetString("search") != "" || viper.GetString("tag") != ""
+		if len(args) == 0 {
+			if err := checkTableSelection(backupTables); err != nil {
+				return err
+			}
+		} else if len(backupTables) > 0 || searching {
+			return errors.New("Invalid command line arguments: backup arns can't be used together with table, search or tag!")
+		}
+		if err := checkBackupFilters(); err != nil {
+			return err
+		}
+		action = BackupDelete
+		return nil
+	},
+}
+
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune --policy policy_file [--table table_name... | --search table_name | --tag tag_value] [--dry-run] [--yes]",
+	Short: "Delete the backups a retention policy doesn't keep",
+	Long: `Delete the user backups of every table, or of the selected ones, that their retention rule doesn't keep.
+The policy is a YAML or JSON file, the first rule matching a table by name pattern and tag applies to it:
+
+rules:
+  - tables: 'prod-*'
+    tag: env=prod
+    latest: 1    # newest backups always kept, 1 if not set
+    daily: 7     # newest backup of each of the last 7 days
+    weekly: 4    # newest backup of each of the last 4 ISO weeks
+    monthly: 12  # newest backup of each of the last 12 months
+  - tag: team
+    daily: 3
+
+The backups of the tables matching no rule are kept. The plan is shown and the backups are deleted once confirmed, unless --yes is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if backupPolicyFile == "" {
+			return errors.New("Invalid command line arguments: policy must be provided!")
+		}
+		if len(backupTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		action = BackupPrune
+		return nil
+	},
+}
+
+// checkBackupFilters parses the backup type and time range filters.
+// It returns an error if any of them is not valid.
+func checkBackupFilters() error {
+	var err error
+	if len(backupTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+		return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+	}
+	if backupType, err = backup.ParseType(backupTypeStr); err != nil {
+		return errors.New("Invalid command line arguments: " + err.Error())
+	}
+	if backupFrom, err = backup.ParseTime(backupFromStr); err != nil {
+		return errors.New("Invalid command line arguments: " + err.Error())
+	}
+	if backupTo, err = backup.ParseTime(backupToStr); err != nil {
+		return errors.New("Invalid command line arguments: " + err.Error())
+	}
+	return nil
+}
+
+// runBackup runs the backup operation selected by the action.
+func runBackup(dbmgr *client.DynamoDBManager, backupAction string) error {
+	format := viper.GetString("output")
+	switch backupAction {
+	case BackupCreate:
+		return runOnTables(dbmgr, backupTables, func(tables []string) error {
+			return ExecuteBackupCreateTask(dbmgr, tables, backupNameTemplate, viper.GetBool("dry-run"), format)
+		})
+	case BackupList:
+		if len(backupTables) == 0 && viper.GetString("search") == "" && viper.GetString("tag") == "" {
+			// A single listing covers every table
+			return ExecuteBackupListTask(dbmgr, nil, backupType, backupFrom, backupTo, format)
+		}
+		return runOnTables(dbmgr, backupTables, func(tables []string) error {
+			return ExecuteBackupListTask(dbmgr, tables, backupType, backupFrom, backupTo, format)
+		})
+	case BackupRestore:
+		var backupArn, sourceTable string
+		if len(backupArns) > 0 {
+			backupArn = backupArns[0]
+		} else {
+			sourceTable = backupTables[0]
+		}
+		return ExecuteBackupRestoreTask(dbmgr, backupArn, sourceTable, backupTarget, backupWait, viper.GetBool("dry-run"), format)
+	case BackupPrune:
+		return runOnTables(dbmgr, backupTables, func(tables []string) error {
+			return ExecuteBackupPruneTask(dbmgr, backupPolicyFile, tables, viper.GetBool("dry-run"), viper.GetBool("yes"), format)
+		})
+	default:
+		if len(backupArns) > 0 {
+			return ExecuteBackupDeleteTask(dbmgr, backupArns, nil, backupType, nil, nil, viper.GetBool("dry-run"), viper.GetBool("yes"), format)
+		}
+		return runOnTables(dbmgr, backupTables, func(tables []string) error {
+			return ExecuteBackupDeleteTask(dbmgr, nil, tables, backupType, backupFrom, backupTo, viper.GetBool("dry-run"), viper.GetBool("yes"), format)
+		})
+	}
+}
+
+// initBackupCommand registers the backup command and its operations.
+func initBackupCommand() {
+	backupCmd.PersistentFlags().StringSliceVar(&backupTables, "table", nil, "Name of the table, can be repeated")
+
+	backupCreateCmd.Flags().StringVar(&backupNameTemplate, "name", backup.DefaultNameTemplate, "Name template of the backups, {table} and {timestamp} are replaced")
+
+	for _, cmd := range []*cobra.Command{backupListCmd, backupDeleteCmd} {
+		cmd.Flags().StringVar(&backupFromStr, "from", "", "Only the backups created at or after this time")
+		cmd.Flags().StringVar(&backupToStr, "to", "", "Only the backups created at or before this time")
+		cmd.Flags().StringVar(&backupTypeStr, "type", "user", "Type of the backups (user, system, aws-backup, all)")
+	}
+
+	backupRestoreCmd.Flags().StringVar(&backupTarget, "target", "", "Name of the table to restore the backup to")
+	backupRestoreCmd.Flags().BoolVar(&backupWait, "wait", false, "Wait until the restored table is active")
+	backupRestoreCmd.MarkFlagRequired("target")
+
+	backupPruneCmd.Flags().StringVar(&backupPolicyFile, "policy", "", "Retention policy file, YAML or JSON")
+
+	backupCmd.AddCommand(backupCreateCmd, backupListCmd, backupRestoreCmd, backupDeleteCmd, backupPruneCmd)
+	rootCmd.AddCommand(backupCmd)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+// checkTableSelection checks that the tables of a command are selected either by name or by the search conditions.
+// It returns an error if both or none of them are provided.
+func checkTableSelection(tables []string) error {
+	searching := viper.GetString("search") != "" || viper.GetString("tag") != ""
+	if len(tables) == 0 && !searching {
+		return errors.New("Invalid command line arguments: any of table or search or tag param must be provided!")
+	}
+	if len(tables) > 0 && searching {
+		return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+	}
+	return nil
+}
+
+var GetTableListTask = client.GetTableList
+
+// resolveTables returns the tables a command applies to: the given table names if any,
+// otherwise the names of the tables matched by the search and tag conditions, or every table without conditions.
+// The error wraps client.ErrPartialFailure if the search skipped some tables, the matched names are still returned then.
+func resolveTables(dbmgr *client.DynamoDBManager, tables []string) ([]string, error) {
+	if len(tables) > 0 {
+		return tables, nil
+	}
+	if viper.GetString("search") == "" && viper.GetString("tag") == "" {
+		return GetTableListTask(dbmgr)
+	}
+
+	matchingTables, err := ExecuteSearchTask(dbmgr, viper.GetString("search"), viper.GetString("tag"))
+	names := make([]string, 0, len(matchingTables))
+	for _, table := range matchingTables {
+		names = append(names, table["Name"])
+	}
+	return names, err
+}
+
+// runOnTables resolves the tables of a command and runs the task on them.
+// It returns client.ErrNoMatches if no table was selected, otherwise the error of the task joined with the search error.
+func runOnTables(dbmgr *client.DynamoDBManager, tables []string, task func(tables []string) error) error {
+	resolved, errSearch := resolveTables(dbmgr, tables)
+	if len(resolved) == 0 {
+		if errSearch != nil {
+			return errSearch
+		}
+		return client.ErrNoMatches
+	}
+	return errors.Join(task(resolved), errSearch)
+}
+
+var CreateOtherManager = client.CreateNewDynamoDBManager
+
+// otherManager creates the manager of a table living elsewhere, e.g. in another account or region,
+// the given manager if neither the profile, region, endpoint nor role is given.
+// The credentials come from the profile and role if any of them is given, otherwise they are the ones of the given manager,
+// and the region or endpoint can be overridden on their own.
+func otherManager(dbmgr *client.DynamoDBManager, profile string, region string, endpoint string, roleArn string) (*client.DynamoDBManager, error) {
+	if profile == "" && region == "" && endpoint == "" && roleArn == "" {
+		return dbmgr, nil
+	}
+
+	opts, err := managerOptions()
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" || roleArn != "" {
+		opts.Profile, opts.RoleArn = profile, roleArn
+		opts.ExternalID, opts.MFASerial, opts.SessionName = "", "", ""
+	}
+	if region != "" {
+		opts.Region = region
+	}
+	if endpoint != "" {
+		opts.Endpoint = endpoint
+	}
+
+	othermgr, err := CreateOtherManager(opts)
+	if err != nil {
+		return nil, err
+	}
+	othermgr.Logger = dbmgr.Logger
+	return othermgr, nil
+}
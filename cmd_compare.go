@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/compare"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/search"
+)
+
+var ExecuteCompareTask = compare.ExecuteCompare
+var ExecuteCompareEnvironmentsTask = compare.ExecuteCompareEnvironments
+
+var compareTableA string
+var compareTableB string
+var compareIgnore []string
+var bProfile string
+var bRegion string
+var bEndpoint string
+var bRoleArn string
+var compareTemplateStr string
+var compareEnvSpecs []string
+
+// Parsed naming template and environments
+var compareTemplate *search.NameTemplate
+var compareEnvs []envSpec
+
+// envSpec is an environment given on the command line and where its tables live.
+type envSpec struct {
+	name, profile, region, endpoint, roleArn string
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare table_a table_b [--b-profile profile_name] [--b-region region] [--b-endpoint url] [--b-role-arn arn] [--ignore field...]",
+	Short: "Compare the configuration of two tables, possibly in different accounts or regions",
+	Long: `Compare the key schema, attributes, indexes and projections, billing mode and capacity, stream, time to live,
+point in time recovery, encryption, table class, deletion protection and tags of two tables.
+Table B is reached with the same credentials and region unless the --b-* flags are given.
+The fields are shown side by side, the differing ones marked: ~ changed, - only in table A, + only in table B,
+and colored on a terminal unless NO_COLOR is set. With --output json only the differences are written.
+Fields can be left out of the comparison with --ignore glob patterns, e.g. --ignore 'tag.*' --ignore '*capacity'.
+The exit code is 16 if the tables differ.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		compareTableA, compareTableB = args[0], args[1]
+		if err := compare.ValidateIgnore(compareIgnore); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = Compare
+		return nil
+	},
+}
+
+var compareEnvsCmd = &cobra.Command{
+	Use:   "compare-envs --template naming_template --env env[,profile=profile_name][,region=region][,endpoint=url][,role-arn=arn]... [--ignore field...]",
+	Short: "Compare the tables of two or more environments following a naming convention",
+	Long: `Pair the tables of two or more environments by their name stripped of the environment, following the naming template,
+e.g. --template '{env}-{service}-{entity}' pairs prod-orders-items with staging-orders-items as {env}-orders-items.
+The tables missing in an environment are reported, and the configuration of the others is compared as by the compare command,
+the first environment that has a table being its baseline.
+Each environment is reached with the default credentials and region unless its profile, region, endpoint or role-arn is given,
+e.g. --env prod,profile=prod-account --env staging,profile=staging-account,region=eu-west-1.
+The exit code is 16 if the environments differ.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		if compareTemplate, err = search.ParseNameTemplate(compareTemplateStr); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		if len(compareEnvSpecs) < 2 {
+			return errors.New("Invalid command line arguments: at least two env params must be provided!")
+		}
+		compareEnvs = nil
+		for _, spec := range compareEnvSpecs {
+			env, err := parseEnvSpec(spec)
+			if err != nil {
+				return errors.New("Invalid command line arguments: " + err.Error())
+			}
+			compareEnvs = append(compareEnvs, env)
+		}
+		if err = compare.ValidateIgnore(compareIgnore); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = CompareEnvs
+		return nil
+	},
+}
+
+// parseEnvSpec parses an environment given as name[,profile=profile_name][,region=region][,endpoint=url][,role-arn=arn].
+// It returns an error if the name is missing or a setting is unknown.
+func parseEnvSpec(spec string) (envSpec, error) {
+	settings := strings.Split(spec, ",")
+	env := envSpec{name: strings.TrimSpace(settings[0])}
+	if env.name == "" || strings.Contains(env.name, "=") {
+		return env, errors.New(fmt.Sprintf("invalid env:%s, the name of the environment is expected first", spec))
+	}
+	for _, setting := range settings[1:] {
+		key, value, _ := strings.Cut(setting, "=")
+		switch strings.TrimSpace(key) {
+		case "profile":
+			env.profile = value
+		case "region":
+			env.region = value
+		case "endpoint":
+			env.endpoint = value
+		case "role-arn":
+			env.roleArn = value
+		default:
+			return env, errors.New(fmt.Sprintf("invalid env:%s, unknown setting:%s, profile, region, endpoint or role-arn is expected", spec, key))
+		}
+	}
+	return env, nil
+}
+
+// location describes where the tables of a profile, region and endpoint live, empty if none of them is given.
+func location(profile string, region string, endpoint string) string {
+	var parts []string
+	if profile != "" {
+		parts = append(parts, "profile "+profile)
+	}
+	if region != "" {
+		parts = append(parts, "region "+region)
+	}
+	if endpoint != "" {
+		parts = append(parts, "endpoint "+endpoint)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runCompare compares the configuration of table A with the one of table B.
+func runCompare(dbmgr *client.DynamoDBManager) error {
+	bmgr, err := otherManager(dbmgr, bProfile, bRegion, bEndpoint, bRoleArn)
+	if err != nil {
+		return err
+	}
+
+	a, b := compare.Side{Table: compareTableA}, compare.Side{Table: compareTableB}
+	if bmgr != dbmgr {
+		// The locations tell the tables apart once they live in different places
+		a.Location = location(viper.GetString("profile"), viper.GetString("region"), viper.GetString("endpoint"))
+		b.Location = location(bProfile, bRegion, bEndpoint)
+	}
+	return ExecuteCompareTask(dbmgr, bmgr, a, b, compareIgnore, viper.GetString("output"))
+}
+
+// runCompareEnvs compares the tables of the environments.
+func runCompareEnvs(dbmgr *client.DynamoDBManager) error {
+	environments := make([]compare.Environment, 0, len(compareEnvs))
+	for _, env := range compareEnvs {
+		envmgr, err := otherManager(dbmgr, env.profile, env.region, env.endpoint, env.roleArn)
+		if err != nil {
+			return err
+		}
+		environments = append(environments, compare.Environment{
+			Name:     env.name,
+			Location: location(env.profile, env.region, env.endpoint),
+			Manager:  envmgr,
+		})
+	}
+	return ExecuteCompareEnvironmentsTask(environments, compareTemplate, compareIgnore, viper.GetString("output"))
+}
+
+// initCompareCommand registers the compare and compare-envs commands.
+func initCompareCommand() {
+	compareCmd.Flags().StringVar(&bProfile, "b-profile", "", "AWS shared config profile of table B")
+	compareCmd.Flags().StringVar(&bRegion, "b-region", "", "AWS region of table B")
+	compareCmd.Flags().StringVar(&bEndpoint, "b-endpoint", "", "DynamoDB endpoint URL of table B")
+	compareCmd.Flags().StringVar(&bRoleArn, "b-role-arn", "", "ARN of the role to assume for table B")
+	compareCmd.Flags().StringSliceVar(&compareIgnore, "ignore", nil, "Glob pattern of the fields left out of the comparison, can be repeated")
+	rootCmd.AddCommand(compareCmd)
+
+	compareEnvsCmd.Flags().StringVar(&compareTemplateStr, "template", "", "Naming template of the tables, with the {env} placeholder, e.g. {env}-{service}-{entity}")
+	compareEnvsCmd.Flags().StringArrayVar(&compareEnvSpecs, "env", nil, "Environment as name[,profile=profile_name][,region=region][,endpoint=url][,role-arn=arn], can be repeated")
+	compareEnvsCmd.Flags().StringSliceVar(&compareIgnore, "ignore", nil, "Glob pattern of the fields left out of the comparison, can be repeated")
+	compareEnvsCmd.MarkFlagRequired("template")
+	rootCmd.AddCommand(compareEnvsCmd)
+}
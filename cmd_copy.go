@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/clone"
+)
+
+var ExecuteCopyTask = clone.ExecuteCopy
+
+var copyOpts clone.Options
+var destProfile string
+var destRegion string
+var destEndpoint string
+var destRoleArn string
+
+var copyCmd = &cobra.Command{
+	Use:   "copy source_table destination_table [--dest-profile profile_name] [--dest-region region] [--dest-endpoint url] [--dest-role-arn arn] [--schema-only | --data-only] [--segments n] [--read-capacity rcu] [--write-capacity wcu] [--verify] [--dry-run] [--yes]",
+	Short: "Copy a DynamoDB table, its schema and items, to another table, region or account",
+	Long: `Create the destination table with the key schema, indexes, billing mode and capacity, time to live and tags of the source table,
+then copy the items with a parallel scan and batch writes, throttled by the read and write capacity budgets.
+The destination is reached with the same credentials and region unless the --dest-* flags are given.
+With --verify the item counts and checksums of both tables are compared once the items are copied.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		copyOpts.Source, copyOpts.Destination = args[0], args[1]
+		copyOpts.DryRun = viper.GetBool("dry-run")
+		copyOpts.Yes = viper.GetBool("yes")
+		if err := copyOpts.Validate(); err != nil {
+			return err
+		}
+		action = Copy
+		return nil
+	},
+}
+
+// destinationManager creates the manager of the destination table, the source one if no --dest-* flag is given.
+func destinationManager(dbmgr *client.DynamoDBManager) (*client.DynamoDBManager, error) {
+	return otherManager(dbmgr, destProfile, destRegion, destEndpoint, destRoleArn)
+}
+
+// runCopy copies the source table to the destination one.
+func runCopy(dbmgr *client.DynamoDBManager) error {
+	dstmgr, err := destinationManager(dbmgr)
+	if err != nil {
+		return err
+	}
+	return ExecuteCopyTask(dbmgr, dstmgr, copyOpts)
+}
+
+// initCopyCommand registers the copy command.
+func initCopyCommand() {
+	copyCmd.Flags().StringVar(&destProfile, "dest-profile", "", "AWS shared config profile of the destination")
+	copyCmd.Flags().StringVar(&destRegion, "dest-region", "", "AWS region of the destination")
+	copyCmd.Flags().StringVar(&destEndpoint, "dest-endpoint", "", "DynamoDB endpoint URL of the destination")
+	copyCmd.Flags().StringVar(&destRoleArn, "dest-role-arn", "", "ARN of the role to assume for the destination")
+	copyCmd.Flags().BoolVar(&copyOpts.SchemaOnly, "schema-only", false, "Only create the destination table")
+	copyCmd.Flags().BoolVar(&copyOpts.DataOnly, "data-only", false, "Only copy the items into the existing destination table")
+	copyCmd.Flags().IntVar(&copyOpts.Segments, "segments", clone.DefaultSegments, "Number of segments scanned in parallel")
+	copyCmd.Flags().Float64Var(&copyOpts.ReadCapacity, "read-capacity", 0, "Read capacity units consumed per second at most, unlimited if 0")
+	copyCmd.Flags().Float64Var(&copyOpts.WriteCapacity, "write-capacity", 0, "Write capacity units consumed per second at most, unlimited if 0")
+	copyCmd.Flags().IntVar(&copyOpts.MaxRetries, "max-retries", clone.DefaultMaxRetries, "Retries of the unprocessed items of a batch before the copy fails")
+	copyCmd.Flags().BoolVar(&copyOpts.Verify, "verify", false, "Compare the item counts and checksums of both tables once copied")
+	rootCmd.AddCommand(copyCmd)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/metrics"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/pricing"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/recommend"
+)
+
+var ExecuteCostTask = pricing.ExecuteCost
+var ExecuteEstimateChangeTask = pricing.ExecuteEstimateChange
+var ExecuteBreakEvenTask = pricing.ExecuteBreakEven
+
+var costTables []string
+var costDays int
+var costReadRate float64
+var costWriteRate float64
+var costMetricsFile string
+var breakEvenMinSavings float64
+var breakEvenManifest string
+var breakEvenOptions recommend.Options
+
+var costCmd = &cobra.Command{
+	Use:   "cost [--table table_name... | --search table_name | --tag tag_value] [--read-rate units --write-rate units | --days days | --metrics-file file] [--price-file file]",
+	Short: "Estimate the monthly cost of the tables",
+	Long: `Estimate the monthly cost of the selected tables, or of every table, from their billing mode, capacity, table class and size.
+The cost of the provisioned capacity is shown along with the cost of the request volume in on-demand mode, to compare the billing modes.
+The request volume is the mean consumed capacity over the last days, fetched from CloudWatch or read from a metrics file,
+unless the mean read and write request units per second are given.
+The prices come from the price file, or the embedded list prices of the main regions. The global secondary indexes are left out.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(costTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		if costDays <= 0 {
+			return errors.New("Invalid command line arguments: days must be positive!")
+		}
+		if cmd.Flags().Changed("read-rate") != cmd.Flags().Changed("write-rate") {
+			return errors.New("Invalid command line arguments: read-rate and write-rate must be given together!")
+		}
+		if costReadRate < 0 || costWriteRate < 0 {
+			return errors.New("Invalid command line arguments: read-rate and write-rate can't be negative!")
+		}
+		action = Cost
+		return nil
+	},
+}
+
+var breakEvenCmd = &cobra.Command{
+	Use:   "breakeven [--table table_name... | --search table_name | --tag tag_value] [--days days] [--percentile pct] [--headroom ratio] [--min-savings amount] [--metrics-file file] [--price-file file] [--save-manifest file]",
+	Short: "Compare the cost of the tables in both billing modes and flag the switches saving money",
+	Long: `Compare the monthly cost of the selected tables, or of every table, in their current billing mode with the other billing mode,
+for the consumed capacity over the last days, fetched from CloudWatch or read from a metrics file.
+A provisioned table is compared with its request volume in on-demand mode, an on-demand table with the capacity recommend would set:
+the percentile of the consumed capacity per second plus the headroom, or its peak if the table was throttled.
+The switches saving at least --min-savings per month are flagged, the break-even traffic showing how much the traffic can grow
+or shrink before the billing modes cost the same. The switches of the tables with global secondary indexes are skipped,
+their capacity is left out of the estimate.
+With --save-manifest the flagged switches are written to a manifest, applied with: dynamodb-manager --manifest file [--dry-run]`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(costTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		if costDays <= 0 {
+			return errors.New("Invalid command line arguments: days must be positive!")
+		}
+		if breakEvenMinSavings < 0 {
+			return errors.New("Invalid command line arguments: min-savings can't be negative!")
+		}
+		breakEvenOptions.Window = metrics.NewWindow(costDays, recommend.DefaultPeriod)
+		breakEvenOptions.MinUtilization = recommend.DefaultMinUtilization
+		if err := breakEvenOptions.Validate(); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = BreakEven
+		return nil
+	},
+}
+
+// observedTraffic returns the request volume of the tables observed through the metrics file, or CloudWatch,
+// over the given number of days.
+func observedTraffic(dbmgr *client.DynamoDBManager, days int, metricsFile string) (pricing.Traffic, error) {
+	source, err := metricsSource(dbmgr, metricsFile)
+	return pricing.Traffic{Source: source, Window: metrics.NewWindow(days, pricing.TrafficPeriod)}, err
+}
+
+// runCost estimates the monthly cost of the tables, for the request volume given on the command line or the observed one.
+func runCost(dbmgr *client.DynamoDBManager) error {
+	prices, err := pricing.Load(viper.GetString("price-file"))
+	if err != nil {
+		return err
+	}
+	traffic := pricing.Traffic{ReadRate: costReadRate, WriteRate: costWriteRate, Supplied: true}
+	if !costCmd.Flags().Changed("read-rate") {
+		if traffic, err = observedTraffic(dbmgr, costDays, costMetricsFile); err != nil {
+			return err
+		}
+	}
+	return runOnTables(dbmgr, costTables, func(tables []string) error {
+		return ExecuteCostTask(dbmgr, prices, tables, traffic, viper.GetString("output"))
+	})
+}
+
+// estimateUpdateCost logs the estimated monthly cost of the table before and after the update, for a dry run.
+// It returns an error if the cost can't be estimated.
+func estimateUpdateCost(dbmgr *client.DynamoDBManager) error {
+	prices, err := pricing.Load(viper.GetString("price-file"))
+	if err != nil {
+		return err
+	}
+	traffic, err := observedTraffic(dbmgr, pricing.DefaultDays, "")
+	if err != nil {
+		return err
+	}
+	change := pricing.Change{
+		Rcu:         rcuValueStr,
+		Wcu:         wcuValueStr,
+		OnDemand:    onDemand,
+		Provisioned: provisioned,
+		TableClass:  settings.TableClass,
+	}
+	return ExecuteEstimateChangeTask(dbmgr, prices, updateTable, change, traffic)
+}
+
+// runBreakEven compares the cost of the tables in both billing modes, for the traffic of the metrics file or CloudWatch.
+func runBreakEven(dbmgr *client.DynamoDBManager) error {
+	prices, err := pricing.Load(viper.GetString("price-file"))
+	if err != nil {
+		return err
+	}
+	source, err := metricsSource(dbmgr, costMetricsFile)
+	if err != nil {
+		return err
+	}
+	return runOnTables(dbmgr, costTables, func(tables []string) error {
+		return ExecuteBreakEvenTask(dbmgr, prices, source, tables, breakEvenOptions, breakEvenMinSavings, breakEvenManifest,
+			viper.GetString("output"))
+	})
+}
+
+// initCostCommand registers the cost and breakeven commands.
+func initCostCommand() {
+	costCmd.Flags().StringSliceVar(&costTables, "table", nil, "Name of the table, can be repeated")
+	costCmd.Flags().IntVar(&costDays, "days", pricing.DefaultDays, "Number of days the request volume is observed over")
+	costCmd.Flags().Float64Var(&costReadRate, "read-rate", 0, "Mean read request units per second, instead of the observed ones")
+	costCmd.Flags().Float64Var(&costWriteRate, "write-rate", 0, "Mean write request units per second, instead of the observed ones")
+	costCmd.Flags().StringVar(&costMetricsFile, "metrics-file", "", "JSON file of the metrics of the tables, instead of CloudWatch")
+	rootCmd.AddCommand(costCmd)
+
+	breakEvenCmd.Flags().StringSliceVar(&costTables, "table", nil, "Name of the table, can be repeated")
+	breakEvenCmd.Flags().IntVar(&costDays, "days", pricing.DefaultDays, "Number of days the traffic is observed over")
+	breakEvenCmd.Flags().Float64Var(&breakEvenOptions.Percentile, "percentile", recommend.DefaultPercentile, "Percentile of the consumed capacity the provisioned capacity covers")
+	breakEvenCmd.Flags().Float64Var(&breakEvenOptions.Headroom, "headroom", recommend.DefaultHeadroom, "Share of provisioned capacity added on top of the percentile")
+	breakEvenCmd.Flags().Float64Var(&breakEvenMinSavings, "min-savings", pricing.DefaultMinSavings, "Monthly savings from which a switch is flagged")
+	breakEvenCmd.Flags().StringVar(&costMetricsFile, "metrics-file", "", "JSON file of the metrics of the tables, instead of CloudWatch")
+	breakEvenCmd.Flags().StringVar(&breakEvenManifest, "save-manifest", "", "File the manifest of the flagged switches is written to")
+	rootCmd.AddCommand(breakEvenCmd)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/schema"
+)
+
+var ExecuteCreateTask = schema.ExecuteCreate
+
+var createFile string
+var createName string
+var createTimeout time.Duration
+
+var createCmd = &cobra.Command{
+	Use:   "create -f spec_file [--name table_name] [--timeout duration] [--dry-run]",
+	Short: "Create tables from a declarative spec file",
+	Long: `Create the tables declared by a YAML or JSON spec file, the format written by schema export --format spec:
+
+name: orders
+attributes:
+  - {name: pk, type: S}
+  - {name: sk, type: N}
+  - {name: status, type: S}
+partitionKey: pk
+sortKey: sk
+billingMode: PROVISIONED     # or PAY_PER_REQUEST, capacities default to 5 RCU and 5 WCU
+readCapacity: 10
+globalSecondaryIndexes:
+  - {name: by-status, partitionKey: status, projection: KEYS_ONLY}
+stream: NEW_AND_OLD_IMAGES
+timeToLive: expiresAt
+pointInTimeRecovery: true
+encryption: {type: KMS}
+deletionProtection: true
+tags: {team: orders}
+
+The specs are validated locally before any table is created, then each table is created and awaited until active,
+before its time to live and point in time recovery are enabled. Several tables can be declared as YAML documents.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if createFile == "" {
+			return errors.New("Invalid command line arguments: file must be provided!")
+		}
+		action = Create
+		return nil
+	},
+}
+
+// runCreate creates the tables of the spec file.
+func runCreate(dbmgr *client.DynamoDBManager) error {
+	return ExecuteCreateTask(dbmgr, createFile, createName, createTimeout, viper.GetBool("dry-run"), viper.GetString("output"))
+}
+
+// initCreateCommand registers the create command.
+func initCreateCommand() {
+	createCmd.Flags().StringVarP(&createFile, "file", "f", "", "Spec file declaring the tables, YAML or JSON")
+	createCmd.Flags().StringVar(&createName, "name", "", "Name of the table, overriding the name of the spec")
+	createCmd.Flags().DurationVar(&createTimeout, "timeout", schema.DefaultCreateTimeout, "How long to wait for each table to become active")
+	rootCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/deletion"
+)
+
+var ExecuteDeleteTask = deletion.ExecuteDelete
+
+var deleteTables []string
+var deleteOpts deletion.Options
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete (table_name... | --search table_name | --tag tag_value) [--protect pattern...] [--protect-tag key[=value]...] [--backup] [--dry-run] [--yes]",
+	Short: "Delete tables, unless they are protected",
+	Long: `Delete the given tables, or every table matched by the search and tag conditions, e.g. the stale preview tables:
+  delete --search pr- --backup
+The tables with deletion protection enabled, or matching a protected pattern or tag, are refused. The protections are best kept
+in the config file, so they apply to every deletion:
+  protect: ['prod-*', '*-prod']
+  protect-tag: ['env=prod', 'keep']
+The plan is shown and the tables are deleted once the table name, or the number of tables for a batch, is typed, unless --yes is given.
+With --backup a final on-demand backup of each table is taken, and awaited, before it is deleted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deleteTables = args
+		if err := checkTableSelection(args); err != nil {
+			return err
+		}
+		deleteOpts.Protected = viper.GetStringSlice("protect")
+		deleteOpts.ProtectedTags = viper.GetStringSlice("protect-tag")
+		deleteOpts.DryRun = viper.GetBool("dry-run")
+		deleteOpts.Yes = viper.GetBool("yes")
+		if err := deleteOpts.Validate(); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = Delete
+		return nil
+	},
+}
+
+// runDelete deletes the selected tables.
+func runDelete(dbmgr *client.DynamoDBManager) error {
+	return runOnTables(dbmgr, deleteTables, func(tables []string) error {
+		return ExecuteDeleteTask(dbmgr, tables, deleteOpts, viper.GetString("output"))
+	})
+}
+
+// initDeleteCommand registers the delete command, its protections can be set in the config file as well.
+func initDeleteCommand() {
+	deleteCmd.Flags().StringSlice("protect", nil, "Glob pattern of the table names never deleted, can be repeated")
+	deleteCmd.Flags().StringSlice("protect-tag", nil, "Tag, as key or key=value, of the tables never deleted, can be repeated")
+	deleteCmd.Flags().BoolVar(&deleteOpts.Backup, "backup", false, "Take a final on-demand backup of each table before deleting it")
+	deleteCmd.Flags().StringVar(&deleteOpts.BackupTemplate, "backup-name", deletion.DefaultBackupNameTemplate, "Name template of the final backups, {table} and {timestamp} are replaced")
+	viper.BindPFlag("protect", deleteCmd.Flags().Lookup("protect"))
+	viper.BindPFlag("protect-tag", deleteCmd.Flags().Lookup("protect-tag"))
+	rootCmd.AddCommand(deleteCmd)
+}
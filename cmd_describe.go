@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/describe"
+)
+
+var ExecuteDescribeTask = describe.ExecuteDescribe
+
+var describeTable string
+
+var describeCmd = &cobra.Command{
+	Use:   "describe table_name [--output (text, json)]",
+	Short: "Describe the full configuration of a DynamoDB table",
+	Long:  "Describe the key schema, attributes, indexes, billing mode, stream, encryption, table class, TTL, PITR, deletion protection, replicas, size and tags of a DynamoDB table",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		describeTable = args[0]
+		action = Describe
+		return nil
+	},
+}
+
+// initDescribeCommand registers the describe command.
+func initDescribeCommand() {
+	rootCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/export"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+)
+
+var ExecuteExportTask = export.ExecuteExport
+
+var exportOpts export.Options
+
+var exportCmd = &cobra.Command{
+	Use:   "export table_name [--format (jsonl, ddb-json, csv)] [--columns attribute[=header],...] [--out file] [--gzip] [--segments n] [--read-capacity rcu] [--checkpoint file]",
+	Short: "Export the items of a DynamoDB table with a parallel scan",
+	Long: `Export the items of a table with a parallel segmented scan, as plain JSON lines, DynamoDB typed JSON lines or CSV.
+The progress is checkpointed after every page, rerun an interrupted export with the same options to resume it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exportOpts.Table = args[0]
+		if err := exportOpts.Validate(); err != nil {
+			return err
+		}
+		action = Export
+		return nil
+	},
+}
+
+// runExport exports the items of the table.
+func runExport(dbmgr *client.DynamoDBManager) error {
+	return ExecuteExportTask(dbmgr, exportOpts)
+}
+
+// initExportCommand registers the export command.
+func initExportCommand() {
+	exportCmd.Flags().StringVar(&exportOpts.Format, "format", items.JSONL, "Item format (jsonl, ddb-json, csv)")
+	exportCmd.Flags().StringSliceVar(&exportOpts.Columns, "columns", nil, "CSV columns as attribute or attribute=header")
+	exportCmd.Flags().StringVar(&exportOpts.File, "out", "", "Output file, stdout if not provided")
+	exportCmd.Flags().BoolVar(&exportOpts.Gzip, "gzip", false, "Compress the output with gzip")
+	exportCmd.Flags().IntVar(&exportOpts.Segments, "segments", export.DefaultSegments, "Number of segments scanned in parallel")
+	exportCmd.Flags().Float64Var(&exportOpts.ReadCapacity, "read-capacity", 0, "Read capacity units consumed per second at most, unlimited if 0")
+	exportCmd.Flags().StringVar(&exportOpts.Checkpoint, "checkpoint", "", "Checkpoint file, an output file is required (default the output file with the .checkpoint suffix)")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/importer"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+)
+
+var ExecuteImportTask = importer.ExecuteImport
+
+var importOpts importer.Options
+
+var importCmd = &cobra.Command{
+	Use:   "import table_name -f file [--format (jsonl, ddb-json, csv)] [--types column=type,...] [--write-capacity wcu] [--max-retries n] [--reject-file file] [--dry-run]",
+	Short: "Import items into a DynamoDB table with batch writes",
+	Long: `Import items into a table with BatchWriteItem calls of up to 25 items, from plain JSON lines, DynamoDB typed JSON lines or CSV.
+Every item is validated against the key schema of the table before it is written, the records that fail are written to the reject file.
+CSV cells are strings unless typed with --types, e.g. --types id=N,active=BOOL,tags=SS (S, N, B, BOOL, NULL, SS, NS, BS, L, M, JSON).
+With --dry-run the items are only validated.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		importOpts.Table = args[0]
+		importOpts.DryRun = viper.GetBool("dry-run")
+		if err := importOpts.Validate(); err != nil {
+			return err
+		}
+		action = Import
+		return nil
+	},
+}
+
+// runImport imports the items into the table.
+func runImport(dbmgr *client.DynamoDBManager) error {
+	return ExecuteImportTask(dbmgr, importOpts)
+}
+
+// initImportCommand registers the import command.
+func initImportCommand() {
+	importCmd.Flags().StringVarP(&importOpts.File, "file", "f", "", "Input file, stdin if - (gzip compressed input is detected)")
+	importCmd.Flags().StringVar(&importOpts.Format, "format", items.JSONL, "Item format (jsonl, ddb-json, csv)")
+	importCmd.Flags().StringSliceVar(&importOpts.Types, "types", nil, "CSV column types as column=type, columns without a type are strings")
+	importCmd.Flags().Float64Var(&importOpts.WriteCapacity, "write-capacity", 0, "Write capacity units consumed per second at most, unlimited if 0")
+	importCmd.Flags().IntVar(&importOpts.MaxRetries, "max-retries", importer.DefaultMaxRetries, "Retries of the unprocessed items of a batch before they are rejected")
+	importCmd.Flags().StringVar(&importOpts.RejectFile, "reject-file", "", "File the rejected records are written to (default the input file with the .rejects suffix)")
+	importCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(importCmd)
+}
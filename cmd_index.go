@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/gsi"
+)
+
+var ExecuteIndexStatusTask = gsi.ExecuteStatus
+var ExecuteIndexCreateTask = gsi.ExecuteCreate
+var ExecuteIndexDeleteTask = gsi.ExecuteDelete
+
+var indexTables []string
+var indexTable string
+var indexName string
+var indexPartitionKey string
+var indexSortKey string
+var indexProjection string
+var indexInclude []string
+var indexNoWait bool
+var indexTimeout time.Duration
+
+// Parsed index definition
+var indexDefinition gsi.Definition
+
+var indexCmd = &cobra.Command{
+	Use:   "index (status, create, delete)",
+	Short: "Manage the global secondary indexes of DynamoDB tables",
+	Long:  "Show the global secondary indexes of the tables and their backfill progress, add an index to a table or delete one",
+}
+
+var indexStatusCmd = &cobra.Command{
+	Use:   "status [--table table_name... | --search table_name | --tag tag_value]",
+	Short: "Show the global secondary indexes of the tables and whether they are backfilling",
+	Long:  "Show the global secondary indexes of the selected tables, or of every table, with their status and backfill progress",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(indexTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		action = IndexStatus
+		return nil
+	},
+}
+
+var indexCreateCmd = &cobra.Command{
+	Use:   "create table_name --name index_name --partition-key name:type [--sort-key name:type] [--projection type] [--include attribute...] [--rcu rcu_value --wcu wcu_value] [--no-wait] [--timeout duration] [--dry-run]",
+	Short: "Add a global secondary index to a table",
+	Long: `Add a global secondary index to a table and track its backfill until the index is active, unless --no-wait is given.
+The key attributes are given as name:type, the type being S, N or B. The projection is ALL unless KEYS_ONLY or INCLUDE is given.
+The index of a provisioned table gets the --rcu and --wcu capacity, the index of an on-demand table has none.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		indexTable = args[0]
+		partitionKey, err := gsi.ParseKeyAttribute(indexPartitionKey)
+		if err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		indexDefinition = gsi.Definition{
+			Name:             indexName,
+			PartitionKey:     partitionKey,
+			Projection:       indexProjection,
+			NonKeyAttributes: indexInclude,
+		}
+		if indexDefinition.ReadCapacity, err = parseCapacity("rcu"); err != nil {
+			return err
+		}
+		if indexDefinition.WriteCapacity, err = parseCapacity("wcu"); err != nil {
+			return err
+		}
+		if indexSortKey != "" {
+			sortKey, err := gsi.ParseKeyAttribute(indexSortKey)
+			if err != nil {
+				return errors.New("Invalid command line arguments: " + err.Error())
+			}
+			indexDefinition.SortKey = &sortKey
+		}
+		if err = indexDefinition.Validate(); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = IndexCreate
+		return nil
+	},
+}
+
+var indexDeleteCmd = &cobra.Command{
+	Use:   "delete table_name --name index_name [--no-wait] [--timeout duration] [--dry-run] [--yes]",
+	Short: "Delete a global secondary index of a table",
+	Long: `Delete a global secondary index of a table and wait until it is gone, unless --no-wait is given.
+The index is shown and deleted once confirmed, unless --yes is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		indexTable = args[0]
+		action = IndexDelete
+		return nil
+	},
+}
+
+// parseCapacity parses the capacity units of the given flag, 0 if not set.
+func parseCapacity(flag string) (int64, error) {
+	valueStr := viper.GetString(flag)
+	if valueStr == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("Invalid command line arguments: %sValue:%s - error:%v", flag, valueStr, err))
+	}
+	return value, nil
+}
+
+// runIndex runs the index operation selected by the action.
+func runIndex(dbmgr *client.DynamoDBManager, indexAction string) error {
+	format := viper.GetString("output")
+	switch indexAction {
+	case IndexStatus:
+		return runOnTables(dbmgr, indexTables, func(tables []string) error {
+			return ExecuteIndexStatusTask(dbmgr, tables, format)
+		})
+	case IndexCreate:
+		return ExecuteIndexCreateTask(dbmgr, indexTable, indexDefinition, !indexNoWait, indexTimeout, viper.GetBool("dry-run"), format)
+	default:
+		return ExecuteIndexDeleteTask(dbmgr, indexTable, indexName, !indexNoWait, indexTimeout, viper.GetBool("dry-run"), viper.GetBool("yes"), format)
+	}
+}
+
+// initIndexCommand registers the index command and its operations.
+func initIndexCommand() {
+	indexStatusCmd.Flags().StringSliceVar(&indexTables, "table", nil, "Name of the table, can be repeated")
+
+	for _, cmd := range []*cobra.Command{indexCreateCmd, indexDeleteCmd} {
+		cmd.Flags().StringVar(&indexName, "name", "", "Name of the index")
+		cmd.Flags().BoolVar(&indexNoWait, "no-wait", false, "Return once the update is accepted, without tracking the index")
+		cmd.Flags().DurationVar(&indexTimeout, "timeout", gsi.DefaultTimeout, "How long to track the index until it is active, or gone")
+		cmd.MarkFlagRequired("name")
+	}
+
+	indexCreateCmd.Flags().StringVar(&indexPartitionKey, "partition-key", "", "Partition key attribute of the index, as name:type")
+	indexCreateCmd.Flags().StringVar(&indexSortKey, "sort-key", "", "Sort key attribute of the index, as name:type")
+	indexCreateCmd.Flags().StringVar(&indexProjection, "projection", "", "Projection of the index (ALL, KEYS_ONLY, INCLUDE) (default ALL)")
+	indexCreateCmd.Flags().StringSliceVar(&indexInclude, "include", nil, "Non-key attribute projected by the INCLUDE projection, can be repeated")
+	indexCreateCmd.MarkFlagRequired("partition-key")
+
+	indexCmd.AddCommand(indexStatusCmd, indexCreateCmd, indexDeleteCmd)
+	rootCmd.AddCommand(indexCmd)
+}
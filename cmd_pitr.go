@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/backup"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/pitr"
+)
+
+var ExecutePitrStatusTask = pitr.ExecuteStatus
+var ExecutePitrSetTask = pitr.ExecuteSetRecovery
+var ExecutePitrRestoreTask = pitr.ExecuteRestore
+
+var pitrTables []string
+var pitrSource string
+var pitrToStr string
+var pitrTarget string
+var pitrTimeout time.Duration
+
+// Parsed restore time
+var pitrTo time.Time
+
+var pitrCmd = &cobra.Command{
+	Use:   "pitr (status, enable, disable, restore)",
+	Short: "Manage the point in time recovery of DynamoDB tables",
+	Long:  "Show, enable and disable the point in time recovery of a table, or of every table matched by the search and tag conditions, and restore a table as it was at a point in time",
+}
+
+var pitrStatusCmd = &cobra.Command{
+	Use:   "status [--table table_name... | --search table_name | --tag tag_value]",
+	Short: "Show the point in time recovery status and restorable time window of the tables",
+	Long:  "Show the point in time recovery status and restorable time window of the selected tables, or of every table",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(pitrTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		action = PitrStatus
+		return nil
+	},
+}
+
+var pitrEnableCmd = &cobra.Command{
+	Use:   "enable (--table table_name... | --search table_name | --tag tag_value) [--dry-run]",
+	Short: "Enable the point in time recovery of the tables",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkTableSelection(pitrTables); err != nil {
+			return err
+		}
+		action = PitrEnable
+		return nil
+	},
+}
+
+var pitrDisableCmd = &cobra.Command{
+	Use:   "disable (--table table_name... | --search table_name | --tag tag_value) [--dry-run] [--yes]",
+	Short: "Disable the point in time recovery of the tables",
+	Long: `Disable the point in time recovery of the selected tables, their restorable history is lost.
+The tables to update are shown and updated once confirmed, unless --yes is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkTableSelection(pitrTables); err != nil {
+			return err
+		}
+		action = PitrDisable
+		return nil
+	},
+}
+
+var pitrRestoreCmd = &cobra.Command{
+	Use:   "restore table_name --to time --target table_name [--timeout duration] [--dry-run]",
+	Short: "Restore a table as it was at a point in time to a new table",
+	Long: `Restore a table as it was at the given time, RFC 3339 or YYYY-MM-DD, to a new table and wait until the new table is active.
+The time must lie within the restorable time window shown by pitr status.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pitrSource = args[0]
+		to, err := backup.ParseTime(pitrToStr)
+		if err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		if to == nil {
+			return errors.New("Invalid command line arguments: to must be provided!")
+		}
+		if pitrTarget == pitrSource {
+			return errors.New("Invalid command line arguments: target must differ from the restored table!")
+		}
+		pitrTo = *to
+		action = PitrRestore
+		return nil
+	},
+}
+
+// runPitr runs the point in time recovery operation selected by the action.
+func runPitr(dbmgr *client.DynamoDBManager, pitrAction string) error {
+	format := viper.GetString("output")
+	switch pitrAction {
+	case PitrStatus:
+		return runOnTables(dbmgr, pitrTables, func(tables []string) error {
+			return ExecutePitrStatusTask(dbmgr, tables, format)
+		})
+	case PitrEnable, PitrDisable:
+		return runOnTables(dbmgr, pitrTables, func(tables []string) error {
+			return ExecutePitrSetTask(dbmgr, tables, pitrAction == PitrEnable, viper.GetBool("dry-run"), viper.GetBool("yes"), format)
+		})
+	default:
+		return ExecutePitrRestoreTask(dbmgr, pitrSource, pitrTarget, pitrTo, pitrTimeout, viper.GetBool("dry-run"), format)
+	}
+}
+
+// initPitrCommand registers the pitr command and its operations.
+func initPitrCommand() {
+	for _, cmd := range []*cobra.Command{pitrStatusCmd, pitrEnableCmd, pitrDisableCmd} {
+		cmd.Flags().StringSliceVar(&pitrTables, "table", nil, "Name of the table, can be repeated")
+	}
+
+	pitrRestoreCmd.Flags().StringVar(&pitrToStr, "to", "", "Time to restore the table at")
+	pitrRestoreCmd.Flags().StringVar(&pitrTarget, "target", "", "Name of the table to restore to")
+	pitrRestoreCmd.Flags().DurationVar(&pitrTimeout, "timeout", pitr.DefaultRestoreTimeout, "How long to wait for the restored table to become active")
+	pitrRestoreCmd.MarkFlagRequired("to")
+	pitrRestoreCmd.MarkFlagRequired("target")
+
+	pitrCmd.AddCommand(pitrStatusCmd, pitrEnableCmd, pitrDisableCmd, pitrRestoreCmd)
+	rootCmd.AddCommand(pitrCmd)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/query"
+)
+
+var ExecuteGetTask = query.ExecuteGet
+var ExecuteQueryTask = query.ExecuteQuery
+var ExecuteScanTask = query.ExecuteScan
+
+// readOpts holds the options of the get, query and scan commands, only one of them runs
+var readOpts query.Options
+
+// readCommand returns the RunE of a read command, it validates the options and selects the action.
+func readCommand(readAction string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		readOpts.Table = args[0]
+		if err := readOpts.Validate(); err != nil {
+			return err
+		}
+		action = readAction
+		return nil
+	}
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get table_name --key json [--projection attribute,...] [--consistent-read]",
+	Short: "Get an item of a DynamoDB table by its primary key",
+	Long: `Get an item by its primary key given as plain JSON, e.g. --key '{"id":"a","version":3}'.
+Exits with the no-matches status if the item doesn't exist.`,
+	Args: cobra.ExactArgs(1),
+	RunE: readCommand(Get),
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query table_name --key-condition expression [--filter expression] [--names #placeholder=name,...] [--values json] [--index index_name] [--projection attribute,...] [--consistent-read] [--limit n] [--descending]",
+	Short: "Query the items of a DynamoDB table or index",
+	Long: `Query a table or index with a key condition expression, all the pages are read up to the limit.
+Placeholders are given as --names '#s=status' and --values '{":id":"a",":s":"active"}' in plain JSON, e.g.
+  query orders --key-condition 'id = :id' --filter '#s = :s' --names '#s=status' --values '{":id":"a",":s":"active"}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: readCommand(Query),
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan table_name [--filter expression] [--names #placeholder=name,...] [--values json] [--index index_name] [--projection attribute,...] [--consistent-read] [--limit n]",
+	Short: "Scan the items of a DynamoDB table or index",
+	Long: `Scan a table or index, optionally filtered, all the pages are read up to the limit.
+Placeholders are given as --names '#s=status' and --values '{":s":"active"}' in plain JSON.`,
+	Args: cobra.ExactArgs(1),
+	RunE: readCommand(Scan),
+}
+
+// runRead reads the items with the selected read command and writes them in the output format.
+func runRead(dbmgr *client.DynamoDBManager, readAction string) error {
+	switch readAction {
+	case Get:
+		return ExecuteGetTask(dbmgr, readOpts, viper.GetString("output"))
+	case Query:
+		return ExecuteQueryTask(dbmgr, readOpts, viper.GetString("output"))
+	default:
+		return ExecuteScanTask(dbmgr, readOpts, viper.GetString("output"))
+	}
+}
+
+// addExpressionFlags adds the flags of the filter and the expression placeholders.
+func addExpressionFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&readOpts.Filter, "filter", "", "Filter expression")
+	flags.StringSliceVar(&readOpts.Names, "names", nil, "Expression attribute names as #placeholder=name")
+	flags.StringVar(&readOpts.Values, "values", "", "Expression attribute values as a plain JSON object, e.g. {\":id\":\"a\"}")
+	flags.StringVar(&readOpts.Index, "index", "", "Index to read instead of the table")
+	flags.IntVar(&readOpts.Limit, "limit", 0, "Items to return at most, all if 0")
+}
+
+// initReadCommands registers the get, query and scan commands.
+func initReadCommands() {
+	for _, cmd := range []*cobra.Command{getCmd, queryCmd, scanCmd} {
+		cmd.Flags().StringSliceVar(&readOpts.Projection, "projection", nil, "Attributes or document paths, e.g. a.b[0], to return, all if not provided")
+		cmd.Flags().BoolVar(&readOpts.ConsistentRead, "consistent-read", false, "Use strongly consistent reads")
+	}
+
+	getCmd.Flags().StringVar(&readOpts.Key, "key", "", "Primary key of the item as plain JSON, e.g. {\"id\":\"a\"}")
+	getCmd.MarkFlagRequired("key")
+
+	queryCmd.Flags().StringVar(&readOpts.KeyCondition, "key-condition", "", "Key condition expression")
+	queryCmd.Flags().BoolVar(&readOpts.Descending, "descending", false, "Return the items in descending sort key order")
+	queryCmd.MarkFlagRequired("key-condition")
+	addExpressionFlags(queryCmd.Flags())
+	addExpressionFlags(scanCmd.Flags())
+
+	rootCmd.AddCommand(getCmd, queryCmd, scanCmd)
+}
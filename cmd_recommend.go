@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/metrics"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/recommend"
+)
+
+var ExecuteRecommendTask = recommend.ExecuteRecommend
+
+var recommendTables []string
+var recommendDays int
+var recommendPeriod time.Duration
+var recommendMetricsFile string
+var recommendApply bool
+var recommendOptions recommend.Options
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend [--table table_name... | --search table_name | --tag tag_value] [--days days] [--period duration] [--percentile pct] [--headroom ratio] [--min-utilization ratio] [--metrics-file file] [--apply [--dry-run] [--yes]]",
+	Short: "Recommend the billing mode and capacity of the tables from their consumed capacity",
+	Long: `Recommend the billing mode and capacity of the selected tables, or of every table, from their consumed read and write capacity
+and throttle events over the last days, fetched from CloudWatch or read from a metrics file.
+The capacity covers the percentile of the consumed capacity per second plus the headroom, or its peak if the table was throttled.
+On-demand is recommended for the tables without traffic, or with spiky traffic: the mean consumed capacity would use
+less than --min-utilization of the capacity absorbing the peaks, for both reads and writes.
+With --apply the recommendations are shown and applied as by the update command once confirmed, unless --yes is given.
+The global secondary indexes are left out.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(recommendTables) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table can't be used together with search or tag!")
+		}
+		if recommendDays <= 0 {
+			return errors.New("Invalid command line arguments: days must be positive!")
+		}
+		recommendOptions.Window = metrics.NewWindow(recommendDays, recommendPeriod)
+		if err := recommendOptions.Validate(); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = Recommend
+		return nil
+	},
+}
+
+// metricsSource returns the source of the metrics of the tables: the metrics file if given, CloudWatch otherwise.
+// It returns an error if the file can't be loaded.
+func metricsSource(dbmgr *client.DynamoDBManager, metricsFile string) (metrics.Source, error) {
+	if metricsFile == "" {
+		return metrics.NewCloudWatchSource(dbmgr), nil
+	}
+	return metrics.LoadFile(metricsFile)
+}
+
+// runRecommend recommends the capacity of the tables from the metrics of the file, or CloudWatch.
+func runRecommend(dbmgr *client.DynamoDBManager) error {
+	source, err := metricsSource(dbmgr, recommendMetricsFile)
+	if err != nil {
+		return err
+	}
+	return runOnTables(dbmgr, recommendTables, func(tables []string) error {
+		return ExecuteRecommendTask(dbmgr, source, tables, recommendOptions, recommendApply,
+			viper.GetBool("dry-run"), viper.GetBool("yes"), viper.GetString("output"))
+	})
+}
+
+// initRecommendCommand registers the recommend command.
+func initRecommendCommand() {
+	recommendCmd.Flags().StringSliceVar(&recommendTables, "table", nil, "Name of the table, can be repeated")
+	recommendCmd.Flags().IntVar(&recommendDays, "days", recommend.DefaultDays, "Number of days of metrics the recommendation is based on")
+	recommendCmd.Flags().DurationVar(&recommendPeriod, "period", recommend.DefaultPeriod, "Length of the metric periods, a multiple of a minute")
+	recommendCmd.Flags().Float64Var(&recommendOptions.Percentile, "percentile", recommend.DefaultPercentile, "Percentile of the consumed capacity the capacity covers")
+	recommendCmd.Flags().Float64Var(&recommendOptions.Headroom, "headroom", recommend.DefaultHeadroom, "Share of capacity added on top of the percentile")
+	recommendCmd.Flags().Float64Var(&recommendOptions.MinUtilization, "min-utilization", recommend.DefaultMinUtilization, "Mean utilization of the capacity absorbing the peaks below which on-demand is recommended")
+	recommendCmd.Flags().StringVar(&recommendMetricsFile, "metrics-file", "", "JSON file of the metrics of the tables, instead of CloudWatch")
+	recommendCmd.Flags().BoolVar(&recommendApply, "apply", false, "Apply the recommended billing mode and capacity to the tables")
+	rootCmd.AddCommand(recommendCmd)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/schema"
+)
+
+var ExecuteSchemaExportTask = schema.ExecuteExport
+
+var schemaTables []string
+var schemaFormat string
+var schemaDir string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema (export)",
+	Short: "Export the schema of DynamoDB tables as infrastructure as code",
+}
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export [table_name...] [--search table_name | --tag tag_value] [--format (spec, create-table, cloudformation, terraform)] [--dir directory]",
+	Short: "Export the schema of the tables as table specs, CreateTable input JSON, a CloudFormation template or a Terraform configuration",
+	Long: `Export the keys, attributes, indexes, billing mode and capacity, stream, encryption, table class, deletion protection,
+time to live, point in time recovery and tags of the given tables, of every table matched by the search and tag conditions,
+or of every table, e.g. to bring the tables created by hand under infrastructure as code:
+  schema export --search orders --format terraform --dir ./tables
+The CloudFormation resources are retained on deletion and the Terraform resources come with an import block, so the existing tables are imported.
+The table specs are read back by the create command. The specs and the CloudFormation template are YAML, or JSON with --output json. Without --dir a single document declaring all the tables is written to stdout.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemaTables = args
+		if len(args) > 0 && (viper.GetString("search") != "" || viper.GetString("tag") != "") {
+			return errors.New("Invalid command line arguments: table names can't be used together with search or tag!")
+		}
+		if err := schema.ValidateFormat(schemaFormat); err != nil {
+			return errors.New("Invalid command line arguments: " + err.Error())
+		}
+		action = SchemaExport
+		return nil
+	},
+}
+
+// runSchemaExport exports the schema of the selected tables.
+func runSchemaExport(dbmgr *client.DynamoDBManager) error {
+	return runOnTables(dbmgr, schemaTables, func(tables []string) error {
+		return ExecuteSchemaExportTask(dbmgr, tables, schemaFormat, schemaDir, viper.GetString("output"))
+	})
+}
+
+// initSchemaCommand registers the schema command and its operations.
+func initSchemaCommand() {
+	schemaExportCmd.Flags().StringVar(&schemaFormat, "format", schema.FormatCreateTable, "Schema format (spec, create-table, cloudformation, terraform)")
+	schemaExportCmd.Flags().StringVar(&schemaDir, "dir", "", "Directory to write one file per table to, stdout if not provided")
+
+	schemaCmd.AddCommand(schemaExportCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/partiql"
+)
+
+var ExecuteSQLTask = partiql.ExecuteSQL
+var ExecuteBatchSQLTask = partiql.ExecuteBatchSQL
+var ExecuteInteractiveTask = partiql.ExecuteInteractive
+
+var sqlStatement string
+var sqlParameters string
+var sqlFile string
+var sqlConsistentRead bool
+var sqlLimit int
+
+var sqlCmd = &cobra.Command{
+	Use:   "sql [statement] [--params json_array] [--file statement_file [--dry-run]] [--consistent-read] [--limit n]",
+	Short: "Run PartiQL statements, or start an interactive PartiQL shell",
+	Long: `Run a PartiQL statement, all the pages of its results are read up to the limit.
+The values of the '?' placeholders are given as a plain JSON array, e.g.
+  sql 'SELECT * FROM "orders" WHERE id = ? AND version > ?' --params '["a", 3]'
+With --file the statements of the file, "-" for stdin, are run with BatchExecuteStatement calls of up to 25 reads or 25 writes,
+one statement per line as plain PartiQL or as {"statement":"...","parameters":[...]}. With --dry-run they are only validated.
+Without statement nor file an interactive shell is started, with history and table name completion.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqlStatement = ""
+		if len(args) == 1 {
+			sqlStatement = args[0]
+		}
+		if sqlStatement != "" && sqlFile != "" {
+			return errors.New("Invalid command line arguments: a statement can't be used together with file!")
+		}
+		if sqlParameters != "" && sqlStatement == "" {
+			return errors.New("Invalid command line arguments: params can only be used together with a statement!")
+		}
+		if viper.GetBool("dry-run") && sqlFile == "" {
+			return errors.New("Invalid command line arguments: dry-run can only be used together with file!")
+		}
+		if sqlLimit < 0 {
+			return errors.New("Invalid command line arguments: limit must not be negative!")
+		}
+		if sqlStatement != "" {
+			if _, err := partiql.NewStatement(sqlStatement, sqlParameters); err != nil {
+				return err
+			}
+		}
+		action = SQL
+		return nil
+	},
+}
+
+// runSQL runs the statement, the statement file or the interactive shell.
+func runSQL(dbmgr *client.DynamoDBManager) error {
+	switch {
+	case sqlStatement != "":
+		return ExecuteSQLTask(dbmgr, sqlStatement, sqlParameters, sqlConsistentRead, sqlLimit, viper.GetString("output"))
+	case sqlFile != "":
+		return ExecuteBatchSQLTask(dbmgr, sqlFile, sqlConsistentRead, viper.GetBool("dry-run"), viper.GetString("output"))
+	default:
+		return ExecuteInteractiveTask(dbmgr, sqlConsistentRead, viper.GetString("output"))
+	}
+}
+
+// initSQLCommand registers the sql command.
+func initSQLCommand() {
+	sqlCmd.Flags().StringVar(&sqlParameters, "params", "", "Values of the '?' placeholders as a plain JSON array")
+	sqlCmd.Flags().StringVarP(&sqlFile, "file", "f", "", "File of statements run in batches, - for stdin")
+	sqlCmd.Flags().BoolVar(&sqlConsistentRead, "consistent-read", false, "Use strongly consistent reads")
+	sqlCmd.Flags().IntVar(&sqlLimit, "limit", 0, "Items to return at most, all if 0")
+	rootCmd.AddCommand(sqlCmd)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/tagging"
+)
+
+var ExecuteTagTask = tagging.ExecuteTag
+
+var tagOperation string
+var tagTables []string
+var tagValues map[string]string
+var tagKeys []string
+
+var tagCmd = &cobra.Command{
+	Use:   "tag (add, remove, set)",
+	Short: "Add, remove or set the tags of DynamoDB tables",
+	Long:  "Add, remove or set the tags of a table, or of every table matched by the search and tag conditions",
+}
+
+// newTagOperationCommand creates the subcommand of a tag operation.
+func newTagOperationCommand(operation string, use string, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tagOperation = operation
+			if err := checkTagCommand(args); err != nil {
+				return err
+			}
+			action = Tag
+			return nil
+		},
+	}
+}
+
+// checkTagCommand checks and parses the arguments of a tag operation.
+// It returns an error if the arguments are not valid.
+func checkTagCommand(args []string) error {
+	if err := checkTableSelection(tagTables); err != nil {
+		return err
+	}
+
+	if tagOperation == tagging.Remove {
+		for _, key := range args {
+			if strings.Contains(key, "=") {
+				return errors.New("Invalid command line arguments: tag remove expects tag keys, not key=value!")
+			}
+			if client.IsReservedTag(key) {
+				return errors.New(fmt.Sprintf("Invalid command line arguments: tag key reserved for AWS:%s, the %s prefix can't be removed", key, client.ReservedTagPrefix))
+			}
+		}
+		tagKeys = args
+		return nil
+	}
+
+	tags, err := tagging.ParseTags(args)
+	if err != nil {
+		return errors.New("Invalid command line arguments: " + err.Error())
+	}
+	tagValues = tags
+	return nil
+}
+
+// runTag runs the tag operation on the selected tables.
+func runTag(dbmgr *client.DynamoDBManager) error {
+	return runOnTables(dbmgr, tagTables, func(tables []string) error {
+		return ExecuteTagTask(dbmgr, tagOperation, tables, tagValues, tagKeys, viper.GetBool("dry-run"), viper.GetBool("yes"), viper.GetString("output"))
+	})
+}
+
+// initTagCommand registers the tag command and its operations.
+func initTagCommand() {
+	selection := "(--table table_name... | --search table_name | --tag tag_value) [--dry-run] [--yes]"
+	tagCmd.PersistentFlags().StringSliceVar(&tagTables, "table", nil, "Name of the table to tag, can be repeated")
+	tagCmd.AddCommand(
+		newTagOperationCommand(tagging.Add, "add key=value... "+selection, "Add tags, overwriting the values of existing keys"),
+		newTagOperationCommand(tagging.Remove, "remove key... "+selection, "Remove the tags with the given keys"),
+		newTagOperationCommand(tagging.Set, "set key=value... "+selection, "Replace all the tags with the given ones, except the tags reserved for AWS"),
+	)
+	rootCmd.AddCommand(tagCmd)
+}
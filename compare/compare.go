@@ -0,0 +1,338 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/schema"
+)
+
+// ErrDifferences is returned when the compared tables aren't configured alike.
+var ErrDifferences = errors.New("tables differ")
+
+// Kinds of difference of a field
+const (
+	Same    string = "same"
+	Changed string = "changed"
+	OnlyA   string = "only-a"
+	OnlyB   string = "only-b"
+)
+
+var GetDefinitionTask = schema.GetDefinition
+
+// Field is a configuration setting of a table, named by its path, e.g. gsi.byCustomer.projection.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Difference is the comparison of a field of both tables, the value is nil if the table lacks the field.
+type Difference struct {
+	Field string  `json:"field"`
+	Kind  string  `json:"kind"`
+	A     *string `json:"a"`
+	B     *string `json:"b"`
+}
+
+// Side is one of the compared tables and where it lives.
+type Side struct {
+	Table    string `json:"table"`
+	Location string `json:"location,omitempty"` // Profile, region or endpoint of the table, if it differs from the default one
+}
+
+// Comparison is the outcome of the comparison of two tables.
+type Comparison struct {
+	A           Side         `json:"a"`
+	B           Side         `json:"b"`
+	Identical   bool         `json:"identical"`
+	Differences []Difference `json:"differences"`
+	fields      []Difference // Every field, the same ones included, for the side-by-side view
+}
+
+// attributeTypes maps the attribute names of a definition to their types.
+func attributeTypes(definition *schema.Definition) map[string]string {
+	attributes := map[string]string{}
+	for _, attribute := range definition.Input.AttributeDefinitions {
+		attributes[aws.ToString(attribute.AttributeName)] = string(attribute.AttributeType)
+	}
+	return attributes
+}
+
+// formatKeys formats a key schema as its attribute names and types, e.g. "customerId (S), createdAt (N)".
+func formatKeys(keySchema []types.KeySchemaElement, attributes map[string]string) string {
+	keys := make([]string, 0, len(keySchema))
+	for _, key := range keySchema {
+		name := aws.ToString(key.AttributeName)
+		keys = append(keys, fmt.Sprintf("%s (%s)", name, attributes[name]))
+	}
+	return strings.Join(keys, ", ")
+}
+
+// formatProjection formats the projection of an index, with the included attributes if any.
+func formatProjection(projection *types.Projection) string {
+	if projection == nil {
+		return string(types.ProjectionTypeAll)
+	}
+	if len(projection.NonKeyAttributes) > 0 {
+		return fmt.Sprintf("%s %s", projection.ProjectionType, strings.Join(projection.NonKeyAttributes, ","))
+	}
+	return string(projection.ProjectionType)
+}
+
+// formatCapacity formats a provisioned capacity.
+func formatCapacity(throughput *types.ProvisionedThroughput) string {
+	return fmt.Sprintf("read %d, write %d", aws.ToInt64(throughput.ReadCapacityUnits), aws.ToInt64(throughput.WriteCapacityUnits))
+}
+
+// Fields flattens the definition of a table into its configuration settings:
+// key schema, attributes, indexes, billing mode and capacity, stream, time to live, point in time recovery,
+// encryption, table class, deletion protection and tags.
+func Fields(definition *schema.Definition) []Field {
+	input := definition.Input
+	attributes := attributeTypes(definition)
+	var fields []Field
+	add := func(name string, value string) {
+		fields = append(fields, Field{Name: name, Value: value})
+	}
+
+	add("keySchema", formatKeys(input.KeySchema, attributes))
+	for _, attribute := range input.AttributeDefinitions {
+		add("attribute."+aws.ToString(attribute.AttributeName), string(attribute.AttributeType))
+	}
+	add("billingMode", string(input.BillingMode))
+	if input.ProvisionedThroughput != nil {
+		add("capacity", formatCapacity(input.ProvisionedThroughput))
+	}
+	for _, index := range input.GlobalSecondaryIndexes {
+		prefix := "gsi." + aws.ToString(index.IndexName)
+		add(prefix+".keySchema", formatKeys(index.KeySchema, attributes))
+		add(prefix+".projection", formatProjection(index.Projection))
+		if index.ProvisionedThroughput != nil {
+			add(prefix+".capacity", formatCapacity(index.ProvisionedThroughput))
+		}
+	}
+	for _, index := range input.LocalSecondaryIndexes {
+		prefix := "lsi." + aws.ToString(index.IndexName)
+		add(prefix+".keySchema", formatKeys(index.KeySchema, attributes))
+		add(prefix+".projection", formatProjection(index.Projection))
+	}
+
+	stream := "off"
+	if input.StreamSpecification != nil {
+		stream = string(input.StreamSpecification.StreamViewType)
+	}
+	add("stream", stream)
+	ttl := "off"
+	if definition.TimeToLive != nil {
+		ttl = aws.ToString(definition.TimeToLive.AttributeName)
+	}
+	add("timeToLive", ttl)
+	pitr := "off"
+	if definition.PointInTimeRecovery {
+		pitr = "on"
+	}
+	add("pointInTimeRecovery", pitr)
+	encryption := "owned"
+	if sse := input.SSESpecification; sse != nil {
+		encryption = fmt.Sprintf("%s %s", sse.SSEType, kmsKeyName(aws.ToString(sse.KMSMasterKeyId)))
+	}
+	add("encryption", encryption)
+	tableClass := string(input.TableClass)
+	if tableClass == "" {
+		tableClass = string(types.TableClassStandard)
+	}
+	add("tableClass", tableClass)
+	deletionProtection := "off"
+	if aws.ToBool(input.DeletionProtectionEnabled) {
+		deletionProtection = "on"
+	}
+	add("deletionProtection", deletionProtection)
+	for _, tag := range input.Tags {
+		add("tag."+aws.ToString(tag.Key), aws.ToString(tag.Value))
+	}
+	return fields
+}
+
+// kmsKeyName returns the key ID or alias of a KMS key given as ID, alias or ARN, without the account and region of the ARN,
+// so tables encrypted with the same key alias or ID in other accounts or regions compare equal.
+// It returns "aws-managed" for the key managed by AWS for DynamoDB, used when no key is given.
+func kmsKeyName(key string) string {
+	if strings.HasPrefix(key, "arn:") {
+		// arn:partition:kms:region:account:key/id or arn:partition:kms:region:account:alias/name
+		if parts := strings.SplitN(key, ":", 6); len(parts) == 6 {
+			key = parts[5]
+		}
+	}
+	if key == "" || key == "alias/aws/dynamodb" {
+		return "aws-managed"
+	}
+	return strings.TrimPrefix(key, "key/")
+}
+
+// ignored reports whether the field matches any of the ignored glob patterns, e.g. tag.* or *.capacity.
+func ignored(field string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, field); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateIgnore checks the glob patterns of the ignored fields.
+// It returns an error if any of them is invalid.
+func ValidateIgnore(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New(fmt.Sprintf("invalid ignore pattern:%s", pattern))
+		}
+	}
+	return nil
+}
+
+// Diff compares the fields of both tables, the ignored ones aside.
+// The fields are in the order of table A, followed by the ones only table B has.
+func Diff(a []Field, b []Field, ignore []string) Comparison {
+	valuesB := map[string]string{}
+	for _, field := range b {
+		valuesB[field.Name] = field.Value
+	}
+	seen := map[string]bool{}
+	comparison := Comparison{Identical: true, Differences: []Difference{}}
+	add := func(difference Difference) {
+		comparison.fields = append(comparison.fields, difference)
+		if difference.Kind != Same {
+			comparison.Identical = false
+			comparison.Differences = append(comparison.Differences, difference)
+		}
+	}
+
+	for _, field := range a {
+		seen[field.Name] = true
+		if ignored(field.Name, ignore) {
+			continue
+		}
+		valueA := field.Value
+		difference := Difference{Field: field.Name, Kind: OnlyA, A: &valueA}
+		if valueB, exists := valuesB[field.Name]; exists {
+			difference.B, difference.Kind = &valueB, Changed
+			if valueA == valueB {
+				difference.Kind = Same
+			}
+		}
+		add(difference)
+	}
+	for _, field := range b {
+		if seen[field.Name] || ignored(field.Name, ignore) {
+			continue
+		}
+		valueB := field.Value
+		add(Difference{Field: field.Name, Kind: OnlyB, B: &valueB})
+	}
+	return comparison
+}
+
+// Markers and colors of the kinds of difference
+var (
+	kindMarkers = map[string]string{Same: " ", Changed: "~", OnlyA: "-", OnlyB: "+"}
+	kindColors  = map[string]string{Same: "", Changed: output.Yellow, OnlyA: output.Red, OnlyB: output.Green}
+)
+
+// label names a side of the comparison in the header of the side-by-side view.
+func label(side Side) string {
+	if side.Location == "" {
+		return side.Table
+	}
+	return fmt.Sprintf("%s (%s)", side.Table, side.Location)
+}
+
+// valueOf returns the value of a field, "-" if the table lacks it.
+func valueOf(value *string) string {
+	if value == nil {
+		return "-"
+	}
+	return *value
+}
+
+// writeColumns writes the rows with their columns aligned, each row in its color.
+// The columns are padded by hand, the color codes would throw a tab writer off.
+func writeColumns(w io.Writer, rows [][]string, colors []string) {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		fmt.Fprintln(w, output.Colorize(colors[r], strings.TrimRight(strings.Join(cells, "  "), " ")))
+	}
+}
+
+// WriteSideBySide writes every field of both tables side by side, the differing ones marked and colored:
+// ~ for a changed value, - for a field only table A has and + for a field only table B has.
+func WriteSideBySide(w io.Writer, comparison Comparison) {
+	rows := [][]string{{" ", "FIELD", label(comparison.A), label(comparison.B)}}
+	colors := []string{""}
+	for _, field := range comparison.fields {
+		rows = append(rows, []string{kindMarkers[field.Kind], field.Field, valueOf(field.A), valueOf(field.B)})
+		colors = append(colors, kindColors[field.Kind])
+	}
+	writeColumns(w, rows, colors)
+
+	if comparison.Identical {
+		fmt.Fprintf(w, "\nTables %s and %s are configured alike\n", label(comparison.A), label(comparison.B))
+	} else {
+		fmt.Fprintf(w, "\n%d difference(s) between tables %s and %s\n", len(comparison.Differences), label(comparison.A), label(comparison.B))
+	}
+}
+
+// WriteComparison writes the comparison side by side, or its differences as JSON.
+func WriteComparison(w io.Writer, comparison Comparison, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, comparison)
+	}
+	WriteSideBySide(w, comparison)
+	return nil
+}
+
+// ExecuteCompare compares the configuration of two tables, possibly in different accounts or regions.
+// It takes the DynamoDBManager of each table, the table names and locations, the glob patterns of the fields to ignore
+// and the output format as input.
+// It returns an error wrapping ErrDifferences if the tables aren't configured alike, or an error if a table can't be described.
+func ExecuteCompare(dbmgrA *client.DynamoDBManager, dbmgrB *client.DynamoDBManager, a Side, b Side, ignore []string, format string) error {
+	definitionA, err := GetDefinitionTask(dbmgrA, a.Table)
+	if err != nil {
+		return err
+	}
+	definitionB, err := GetDefinitionTask(dbmgrB, b.Table)
+	if err != nil {
+		return err
+	}
+
+	comparison := Diff(Fields(definitionA), Fields(definitionB), ignore)
+	comparison.A, comparison.B = a, b
+	if err = WriteComparison(output.Stdout, comparison, format); err != nil {
+		return err
+	}
+	if !comparison.Identical {
+		return fmt.Errorf("%d difference(s) between table:%s and table:%s - %w", len(comparison.Differences), a.Table, b.Table, ErrDifferences)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/schema"
+)
+
+// definition returns the definition of an on-demand table keyed by id, encrypted as given and with the given tags.
+func definition(sse *types.SSESpecification, tags ...types.Tag) *schema.Definition {
+	return &schema.Definition{Input: &dynamodb.CreateTableInput{
+		TableName:            aws.String("orders"),
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS}},
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash}},
+		BillingMode:          types.BillingModePayPerRequest,
+		SSESpecification:     sse,
+		Tags:                 tags,
+	}}
+}
+
+// value returns the value of the named field, empty if there is none.
+func value(fields []Field, name string) string {
+	for _, field := range fields {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+func TestFieldsEncryption(t *testing.T) {
+	kms := func(key string) *types.SSESpecification {
+		sse := &types.SSESpecification{Enabled: aws.Bool(true), SSEType: types.SSETypeKms}
+		if key != "" {
+			sse.KMSMasterKeyId = aws.String(key)
+		}
+		return sse
+	}
+	tests := []struct {
+		name string
+		sse  *types.SSESpecification
+		want string
+	}{
+		{"owned by DynamoDB", nil, "owned"},
+		{"managed by AWS", kms(""), "KMS aws-managed"},
+		{"managed by AWS as alias", kms("alias/aws/dynamodb"), "KMS aws-managed"},
+		{"key ARN", kms("arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"), "KMS 1234abcd-12ab-34cd-56ef-1234567890ab"},
+		{"key ID", kms("1234abcd-12ab-34cd-56ef-1234567890ab"), "KMS 1234abcd-12ab-34cd-56ef-1234567890ab"},
+		{"alias ARN", kms("arn:aws:kms:us-east-1:444455556666:alias/orders"), "KMS alias/orders"},
+		{"alias", kms("alias/orders"), "KMS alias/orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := value(Fields(definition(tt.sse)), "encryption"); got != tt.want {
+				t.Errorf("encryption = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := Fields(definition(&types.SSESpecification{
+		Enabled: aws.Bool(true), SSEType: types.SSETypeKms,
+		KMSMasterKeyId: aws.String("arn:aws:kms:eu-west-1:111122223333:alias/orders"),
+	}, types.Tag{Key: aws.String("env"), Value: aws.String("prod")}, types.Tag{Key: aws.String("team"), Value: aws.String("sales")}))
+	b := Fields(definition(&types.SSESpecification{
+		Enabled: aws.Bool(true), SSEType: types.SSETypeKms,
+		KMSMasterKeyId: aws.String("arn:aws:kms:us-east-1:444455556666:alias/orders"),
+	}, types.Tag{Key: aws.String("env"), Value: aws.String("dev")}, types.Tag{Key: aws.String("owner"), Value: aws.String("ops")}))
+
+	comparison := Diff(a, b, nil)
+	want := map[string]string{"tag.env": Changed, "tag.team": OnlyA, "tag.owner": OnlyB}
+	if comparison.Identical || len(comparison.Differences) != len(want) {
+		t.Fatalf("Diff() = %+v, want the differences %v", comparison.Differences, want)
+	}
+	for _, difference := range comparison.Differences {
+		if want[difference.Field] != difference.Kind {
+			t.Errorf("difference of %s = %s, want %s", difference.Field, difference.Kind, want[difference.Field])
+		}
+	}
+
+	if comparison = Diff(a, b, []string{"tag.*"}); !comparison.Identical {
+		t.Errorf("Diff() ignoring the tags = %+v, want identical", comparison.Differences)
+	}
+}
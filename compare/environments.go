@@ -0,0 +1,224 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/search"
+)
+
+var GetTableListClient = client.GetTableList
+
+// Environment is a set of tables following the naming template, e.g. prod, possibly in its own account or region.
+type Environment struct {
+	Name     string
+	Location string // Profile, region or endpoint of the environment, if it differs from the default one
+	Manager  *client.DynamoDBManager
+}
+
+// TableReport is the comparison of a table across the environments, the first environment that has it being the baseline.
+type TableReport struct {
+	Name        string            `json:"name"`   // Name of the table with the environment stripped, e.g. {env}-orders-items
+	Tables      map[string]string `json:"tables"` // Name of the table in each environment that has it
+	Missing     []string          `json:"missing,omitempty"`
+	Comparisons []Comparison      `json:"comparisons,omitempty"` // Baseline against each other environment, the differing ones only
+	Error       string            `json:"error,omitempty"`
+	err         error
+}
+
+// EnvironmentsReport is the outcome of the comparison of the environments.
+type EnvironmentsReport struct {
+	Template     string        `json:"template"`
+	Environments []string      `json:"environments"`
+	Identical    bool          `json:"identical"`
+	Tables       []TableReport `json:"tables"`
+}
+
+// environmentTables lists the tables of every environment following the naming template, by their stripped name.
+// It returns an error if the tables of an environment can't be listed.
+func environmentTables(environments []Environment, template *search.NameTemplate) ([]map[string]string, error) {
+	tables := make([]map[string]string, len(environments))
+	for i, env := range environments {
+		names, err := GetTableListClient(env.Manager)
+		if err != nil {
+			return nil, fmt.Errorf("tables of environment:%s can't be listed - %w", env.Name, err)
+		}
+		tables[i] = map[string]string{}
+		for _, name := range names {
+			if stripped, ok := template.Strip(name, env.Name); ok {
+				tables[i][stripped] = name
+			}
+		}
+		env.Manager.Logger.Debugf("Environment:%s has %d of %d table(s) following the template:%s", env.Name, len(tables[i]), len(names), template)
+	}
+	return tables, nil
+}
+
+// compareTable compares the table of the baseline environment with the one of each other environment that has it.
+func compareTable(report *TableReport, environments []Environment, ignore []string) {
+	var baseline *Environment
+	var baselineFields []Field
+	for i := range environments {
+		env := &environments[i]
+		tableName, exists := report.Tables[env.Name]
+		if !exists {
+			continue
+		}
+		definition, err := GetDefinitionTask(env.Manager, tableName)
+		if err != nil {
+			report.Error, report.err = err.Error(), err
+			return
+		}
+		if baseline == nil {
+			baseline, baselineFields = env, Fields(definition)
+			continue
+		}
+
+		comparison := Diff(baselineFields, Fields(definition), ignore)
+		if !comparison.Identical {
+			comparison.A = Side{Table: report.Tables[baseline.Name], Location: baseline.Location}
+			comparison.B = Side{Table: tableName, Location: env.Location}
+			report.Comparisons = append(report.Comparisons, comparison)
+		}
+	}
+}
+
+// CompareEnvironments pairs the tables of the environments by their name stripped of the environment,
+// then compares the configuration of every table across the environments that have it.
+// It returns the report, the tables being sorted by their stripped name, and an error if the tables of an environment can't be listed.
+func CompareEnvironments(environments []Environment, template *search.NameTemplate, ignore []string) (EnvironmentsReport, error) {
+	report := EnvironmentsReport{Template: template.String(), Identical: true, Tables: []TableReport{}}
+	for _, env := range environments {
+		report.Environments = append(report.Environments, env.Name)
+	}
+	tables, err := environmentTables(environments, template)
+	if err != nil {
+		return report, err
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, envTables := range tables {
+		for name := range envTables {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		table := TableReport{Name: name, Tables: map[string]string{}}
+		for i, env := range environments {
+			if tableName, exists := tables[i][name]; exists {
+				table.Tables[env.Name] = tableName
+			} else {
+				table.Missing = append(table.Missing, env.Name)
+			}
+		}
+		compareTable(&table, environments, ignore)
+		report.Identical = report.Identical && len(table.Missing) == 0 && len(table.Comparisons) == 0
+		report.Tables = append(report.Tables, table)
+	}
+	return report, nil
+}
+
+// status sums up the comparison of a table, with the color it is shown in.
+func (r *TableReport) status() (string, string) {
+	var problems []string
+	if len(r.Missing) > 0 {
+		problems = append(problems, "missing in "+strings.Join(r.Missing, ", "))
+	}
+	differences := 0
+	for _, comparison := range r.Comparisons {
+		differences += len(comparison.Differences)
+	}
+	if differences > 0 {
+		problems = append(problems, fmt.Sprintf("%d difference(s)", differences))
+	}
+	switch {
+	case r.Error != "":
+		return "error: " + r.Error, output.Red
+	case len(problems) > 0:
+		return strings.Join(problems, ", "), output.Yellow
+	}
+	return "alike", ""
+}
+
+// WriteEnvironments writes a line per table with its status, followed by the differences of the tables that differ,
+// or the whole report as JSON.
+func WriteEnvironments(w io.Writer, report EnvironmentsReport, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, report)
+	}
+
+	rows := [][]string{append(append([]string{"TABLE"}, report.Environments...), "STATUS")}
+	colors := []string{""}
+	for _, table := range report.Tables {
+		row := []string{table.Name}
+		for _, env := range report.Environments {
+			tableName, exists := table.Tables[env]
+			if !exists {
+				tableName = "-"
+			}
+			row = append(row, tableName)
+		}
+		status, color := table.status()
+		rows = append(rows, append(row, status))
+		colors = append(colors, color)
+	}
+	writeColumns(w, rows, colors)
+
+	for _, table := range report.Tables {
+		for _, comparison := range table.Comparisons {
+			fmt.Fprintf(w, "\n%s vs %s:\n", label(comparison.A), label(comparison.B))
+			for _, difference := range comparison.Differences {
+				fmt.Fprintln(w, output.Colorize(kindColors[difference.Kind], fmt.Sprintf("  %s %s: %s -> %s",
+					kindMarkers[difference.Kind], difference.Field, valueOf(difference.A), valueOf(difference.B))))
+			}
+		}
+	}
+	return nil
+}
+
+// ExecuteCompareEnvironments compares the tables of two or more environments, possibly in different accounts or regions,
+// paired by their name stripped of the environment according to the naming template.
+// It takes the environments, the naming template, the glob patterns of the fields to ignore and the output format as input.
+// It returns an error wrapping ErrDifferences if a table is missing in an environment or configured differently,
+// joined with client.ErrPartialFailure if some tables couldn't be compared, or an error if the tables of an environment can't be listed.
+func ExecuteCompareEnvironments(environments []Environment, template *search.NameTemplate, ignore []string, format string) error {
+	if len(environments) < 2 {
+		return errors.New("at least two environments are expected to compare")
+	}
+	report, err := CompareEnvironments(environments, template, ignore)
+	if err != nil {
+		return err
+	}
+	if err = WriteEnvironments(output.Stdout, report, format); err != nil {
+		return err
+	}
+
+	var errs []error
+	failed := 0
+	for _, table := range report.Tables {
+		if table.err != nil {
+			failed++
+		}
+	}
+	if !report.Identical {
+		errs = append(errs, fmt.Errorf("environments %s differ - %w", strings.Join(report.Environments, ", "), ErrDifferences))
+	}
+	if failed > 0 {
+		errs = append(errs, fmt.Errorf("comparison failed for %d of %d table(s) - %w", failed, len(report.Tables), client.ErrPartialFailure))
+	}
+	if len(report.Tables) == 0 {
+		errs = append(errs, fmt.Errorf("no table follows the naming template:%s - %w", template, client.ErrNoMatches))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,299 @@
+package deletion
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/backup"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/tagging"
+)
+
+// DefaultBackupNameTemplate names the final backups after their table and creation time
+const DefaultBackupNameTemplate = "{table}-final-{timestamp}"
+
+// Statuses of a table deletion
+const (
+	StatusPending string = "pending"
+	StatusRefused string = "refused"
+	StatusDeleted string = "deleted"
+	StatusFailed         = outcome.StatusFailed
+)
+
+// backupTimeout is how long a final backup may take to become available, the table can't be deleted before
+const backupTimeout = 30 * time.Minute
+
+var (
+	DescribeTableClient  = client.DescribeTable
+	DeleteTableClient    = client.DeleteTable
+	CreateBackupClient   = client.CreateBackup
+	DescribeBackupClient = client.DescribeBackup
+	GetCurrentTagsTask   = tagging.GetCurrentTags
+)
+
+// BackupPollInterval is the delay between the checks of the final backup status
+var BackupPollInterval = 5 * time.Second
+
+// Options describes a deletion of tables.
+type Options struct {
+	Protected      []string // Glob patterns of the table names that are never deleted, e.g. prod-*
+	ProtectedTags  []string // Tags, as key or key=value, of the tables that are never deleted
+	Backup         bool     // Take a final on-demand backup of each table before deleting it
+	BackupTemplate string   // Name template of the final backups, see backup.BackupName
+	DryRun         bool
+	Yes            bool
+}
+
+// Validate checks the protected patterns and the backup name template.
+// It returns an error if any of them is invalid.
+func (o *Options) Validate() error {
+	for _, pattern := range o.Protected {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New(fmt.Sprintf("invalid protected pattern:%s", pattern))
+		}
+	}
+	for _, tag := range o.ProtectedTags {
+		if key, _, _ := strings.Cut(tag, "="); key == "" {
+			return errors.New(fmt.Sprintf("invalid protected tag:%s, key or key=value is expected", tag))
+		}
+	}
+	if o.Backup {
+		return backup.ValidateNameTemplate(o.BackupTemplate)
+	}
+	return nil
+}
+
+// Result is the plan and then the outcome of the deletion of a table.
+type Result struct {
+	Table string `json:"table"`
+	Items int64  `json:"itemCount"`
+	Size  int64  `json:"sizeBytes"`
+	outcome.Outcome
+	Reason string `json:"reason,omitempty"`
+	Backup string `json:"backupArn,omitempty"`
+}
+
+// matchesTag reports whether the tags hold the protected tag, given as key or key=value.
+func matchesTag(tags map[string]string, protectedTag string) bool {
+	key, value, hasValue := strings.Cut(protectedTag, "=")
+	current, exists := tags[key]
+	return exists && (!hasValue || current == value)
+}
+
+// protection returns why a table must not be deleted: its deletion protection, a protected pattern or tag it matches,
+// or an empty string if it can be deleted.
+func protection(table *types.TableDescription, tags map[string]string, opts Options) string {
+	tableName := aws.ToString(table.TableName)
+	if aws.ToBool(table.DeletionProtectionEnabled) {
+		return "deletion protection is enabled"
+	}
+	for _, pattern := range opts.Protected {
+		if matched, _ := path.Match(pattern, tableName); matched {
+			return "matches protected pattern " + pattern
+		}
+	}
+	for _, tag := range opts.ProtectedTags {
+		if matchesTag(tags, tag) {
+			return "has protected tag " + tag
+		}
+	}
+	return ""
+}
+
+// Plan decides for every table whether it can be deleted, the tags are only read if protected tags are given.
+// Tables that can't be described, or whose tags can't be read, are failed: they aren't deleted without their protection checked.
+func Plan(dbmgr *client.DynamoDBManager, tables []string, opts Options) []Result {
+	results := make([]Result, 0, len(tables))
+	for _, tableName := range tables {
+		result := Result{Table: tableName, Outcome: outcome.Outcome{Status: StatusPending}}
+		table, err := DescribeTableClient(dbmgr, tableName)
+		if err != nil {
+			result.Fail(err)
+			results = append(results, result)
+			continue
+		}
+		result.Items, result.Size = aws.ToInt64(table.ItemCount), aws.ToInt64(table.TableSizeBytes)
+
+		var tags map[string]string
+		if len(opts.ProtectedTags) > 0 {
+			if _, tags, err = GetCurrentTagsTask(dbmgr, tableName); err != nil {
+				result.Fail(fmt.Errorf("protected tags can't be checked - %w", err))
+				results = append(results, result)
+				continue
+			}
+		}
+		if reason := protection(table, tags, opts); reason != "" {
+			result.Status, result.Reason = StatusRefused, reason
+			dbmgr.Logger.Warnf("Refusing to delete table:%s, it %s", tableName, reason)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// WriteResults writes the deletion plan or outcome in the given output format.
+func WriteResults(w io.Writer, results []Result, format string) error {
+	if format == output.JSON {
+		if results == nil {
+			results = []Result{}
+		}
+		return output.WriteJSON(w, results)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tITEMS\tSIZE\tSTATUS\tDETAILS\n")
+	for _, result := range results {
+		details := result.Reason
+		switch {
+		case result.Error != "":
+			details = result.Error
+		case result.Backup != "":
+			details = "final backup " + result.Backup
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", result.Table, result.Items, output.FormatBytes(result.Size), result.Status, details)
+	}
+	return tw.Flush()
+}
+
+// waitForBackup waits until the backup is available, the table of a backup being created can't be deleted.
+// It returns an error if the backup fails or isn't available in time.
+func waitForBackup(dbmgr *client.DynamoDBManager, backupArn string) error {
+	deadline := time.Now().Add(backupTimeout)
+	for {
+		description, err := DescribeBackupClient(dbmgr, backupArn)
+		if err != nil {
+			return err
+		}
+		status := types.BackupStatusCreating
+		if description != nil && description.BackupDetails != nil {
+			status = description.BackupDetails.BackupStatus
+		}
+		switch {
+		case status == types.BackupStatusAvailable:
+			return nil
+		case status != types.BackupStatusCreating:
+			return errors.New(fmt.Sprintf("backup:%s is %s", backupArn, status))
+		case time.Now().After(deadline):
+			return errors.New(fmt.Sprintf("backup:%s isn't available after %s", backupArn, backupTimeout))
+		}
+		time.Sleep(BackupPollInterval)
+	}
+}
+
+// deleteTable takes the final backup of the table if requested, then deletes it.
+func deleteTable(dbmgr *client.DynamoDBManager, result *Result, opts Options, now time.Time) {
+	if opts.Backup {
+		name, err := backup.BackupName(opts.BackupTemplate, result.Table, now)
+		if err != nil {
+			result.Fail(err)
+			return
+		}
+		details, err := CreateBackupClient(dbmgr, result.Table, name)
+		if err != nil {
+			result.Fail(fmt.Errorf("final backup failed, table not deleted - %w", err))
+			return
+		}
+		result.Backup = aws.ToString(details.BackupArn)
+		if err = waitForBackup(dbmgr, result.Backup); err != nil {
+			result.Fail(fmt.Errorf("final backup failed, table not deleted - %w", err))
+			return
+		}
+		dbmgr.Logger.Infof("Created final backup:%s of table:%s", name, result.Table)
+	}
+
+	if _, err := DeleteTableClient(dbmgr, result.Table); err != nil {
+		result.Fail(err)
+		return
+	}
+	result.Status = StatusDeleted
+	dbmgr.Logger.Infof("Deleted table:%s", result.Table)
+}
+
+// confirm asks the user to type the name of the table to delete, or the number of tables for a batch deletion.
+func confirm(pending []string, backupFirst bool) error {
+	question := fmt.Sprintf("Delete %d table(s) and all of their items, this can't be undone?", len(pending))
+	if backupFirst {
+		question = fmt.Sprintf("Delete %d table(s) and all of their items, after a final backup of each?", len(pending))
+	}
+	expected := strconv.Itoa(len(pending))
+	if len(pending) == 1 {
+		expected = pending[0]
+	}
+	if !prompt.ConfirmByTyping(question, expected) {
+		return prompt.ErrAborted
+	}
+	return nil
+}
+
+// ExecuteDelete deletes the given tables, except the protected ones: with deletion protection enabled, or matching a protected pattern or tag.
+// It takes a DynamoDBManager, the table names, the deletion options and the output format as input.
+// The plan is shown first and the user confirms by typing the table name, or the number of tables for a batch, unless 'yes' is set.
+// It returns an error wrapping client.ErrPolicyViolation if some tables are protected, joined with
+// an error wrapping client.ErrPendingChanges for a dry run, client.ErrPartialFailure if some deletions failed,
+// or the error of the failure if all of them failed.
+func ExecuteDelete(dbmgr *client.DynamoDBManager, tables []string, opts Options, format string) error {
+	results := Plan(dbmgr, tables, opts)
+
+	var errs []error
+	if refused := outcome.CountStatus(results, StatusRefused); refused > 0 {
+		errs = append(errs, fmt.Errorf("%d of %d table(s) are protected and won't be deleted - %w", refused, len(results), client.ErrPolicyViolation))
+	}
+	var pending []string
+	for _, result := range results {
+		if result.Status == StatusPending {
+			pending = append(pending, result.Table)
+		}
+	}
+
+	switch {
+	case opts.DryRun || len(pending) == 0:
+		if err := WriteResults(output.Stdout, results, format); err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			errs = append(errs, fmt.Errorf("deletion pending for %d table(s) - %w", len(pending), client.ErrPendingChanges))
+		}
+		return errors.Join(append(errs, summarize(results))...)
+	case !opts.Yes:
+		if err := prompt.ShowPlan(func(w io.Writer) error { return WriteResults(w, results, output.Text) }); err != nil {
+			return err
+		}
+		if err := confirm(pending, opts.Backup); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for i := range results {
+		if results[i].Status == StatusPending {
+			deleteTable(dbmgr, &results[i], opts, now)
+		}
+	}
+
+	if err := WriteResults(output.Stdout, results, format); err != nil {
+		return err
+	}
+	return errors.Join(append(errs, summarize(results))...)
+}
+
+// summarize returns the error summing up the deletions, the refused tables aside, see outcome.Summary.
+func summarize(results []Result) error {
+	attempted := make([]Result, 0, len(results))
+	for _, result := range results {
+		if result.Status != StatusRefused {
+			attempted = append(attempted, result)
+		}
+	}
+	return outcome.Summarize("deletion", "table(s)", attempted)
+}
@@ -0,0 +1,437 @@
+package describe
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+var (
+	DescribeTableClient             = client.DescribeTable
+	DescribeTimeToLiveClient        = client.DescribeTimeToLive
+	DescribeContinuousBackupsClient = client.DescribeContinuousBackups
+	GetTableTagsClient              = client.GetTableTags
+)
+
+// KeyElement is an attribute of a key schema with its key type (HASH or RANGE) and attribute type.
+type KeyElement struct {
+	Name          string `json:"name"`
+	KeyType       string `json:"keyType"`
+	AttributeType string `json:"attributeType,omitempty"`
+}
+
+// Attribute is an attribute definition of the table.
+type Attribute struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Throughput is the provisioned capacity of a table or an index, with its change history.
+type Throughput struct {
+	ReadCapacityUnits      int64      `json:"readCapacityUnits"`
+	WriteCapacityUnits     int64      `json:"writeCapacityUnits"`
+	LastIncreaseDateTime   *time.Time `json:"lastIncreaseDateTime,omitempty"`
+	LastDecreaseDateTime   *time.Time `json:"lastDecreaseDateTime,omitempty"`
+	NumberOfDecreasesToday int64      `json:"numberOfDecreasesToday"`
+}
+
+// Index is a global or local secondary index of the table.
+type Index struct {
+	Name             string       `json:"name"`
+	KeySchema        []KeyElement `json:"keySchema"`
+	ProjectionType   string       `json:"projectionType"`
+	NonKeyAttributes []string     `json:"nonKeyAttributes,omitempty"`
+	Status           string       `json:"status,omitempty"`
+	Backfilling      bool         `json:"backfilling"`
+	Throughput       *Throughput  `json:"throughput,omitempty"`
+	ItemCount        int64        `json:"itemCount"`
+	SizeBytes        int64        `json:"sizeBytes"`
+}
+
+// BillingMode is the billing mode of the table and when it was last switched to on-demand.
+type BillingMode struct {
+	Mode                              string      `json:"mode"`
+	LastUpdateToPayPerRequestDateTime *time.Time  `json:"lastUpdateToPayPerRequestDateTime,omitempty"`
+	Throughput                        *Throughput `json:"throughput,omitempty"`
+}
+
+// Stream is the stream specification of the table.
+type Stream struct {
+	Enabled   bool   `json:"enabled"`
+	ViewType  string `json:"viewType,omitempty"`
+	LatestArn string `json:"latestArn,omitempty"`
+}
+
+// Encryption is the server-side encryption of the table, owned by DynamoDB if Status is empty.
+type Encryption struct {
+	Status    string `json:"status,omitempty"`
+	Type      string `json:"type,omitempty"`
+	KMSKeyArn string `json:"kmsKeyArn,omitempty"`
+}
+
+// TimeToLive is the time to live setting of the table.
+type TimeToLive struct {
+	Status    string `json:"status"`
+	Attribute string `json:"attribute,omitempty"`
+}
+
+// PointInTimeRecovery is the continuous backups setting of the table.
+type PointInTimeRecovery struct {
+	ContinuousBackupsStatus    string     `json:"continuousBackupsStatus"`
+	Status                     string     `json:"status"`
+	EarliestRestorableDateTime *time.Time `json:"earliestRestorableDateTime,omitempty"`
+	LatestRestorableDateTime   *time.Time `json:"latestRestorableDateTime,omitempty"`
+}
+
+// Replica is a replica of a global table.
+type Replica struct {
+	Region     string `json:"region"`
+	Status     string `json:"status"`
+	TableClass string `json:"tableClass,omitempty"`
+	KMSKeyId   string `json:"kmsKeyId,omitempty"`
+}
+
+// TableDetails gathers the full configuration of a DynamoDB table.
+type TableDetails struct {
+	Name                   string               `json:"name"`
+	Arn                    string               `json:"arn"`
+	Status                 string               `json:"status"`
+	CreationDateTime       *time.Time           `json:"creationDateTime,omitempty"`
+	KeySchema              []KeyElement         `json:"keySchema"`
+	Attributes             []Attribute          `json:"attributes"`
+	BillingMode            BillingMode          `json:"billingMode"`
+	GlobalSecondaryIndexes []Index              `json:"globalSecondaryIndexes,omitempty"`
+	LocalSecondaryIndexes  []Index              `json:"localSecondaryIndexes,omitempty"`
+	Stream                 Stream               `json:"stream"`
+	Encryption             Encryption           `json:"encryption"`
+	TableClass             string               `json:"tableClass"`
+	TimeToLive             *TimeToLive          `json:"timeToLive,omitempty"`
+	PointInTimeRecovery    *PointInTimeRecovery `json:"pointInTimeRecovery,omitempty"`
+	DeletionProtection     bool                 `json:"deletionProtection"`
+	Replicas               []Replica            `json:"replicas,omitempty"`
+	ItemCount              int64                `json:"itemCount"`
+	SizeBytes              int64                `json:"sizeBytes"`
+	Tags                   map[string]string    `json:"tags"`
+}
+
+// AttributeType returns the type of an attribute from its definitions, or an empty string if it is not defined.
+func (d *TableDetails) AttributeType(name string) string {
+	for _, attribute := range d.Attributes {
+		if attribute.Name == name {
+			return attribute.Type
+		}
+	}
+	return ""
+}
+
+// SortedTagKeys returns the tag keys of the table in alphabetical order.
+func (d *TableDetails) SortedTagKeys() []string {
+	keys := make([]string, 0, len(d.Tags))
+	for key := range d.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// convertThroughput converts the provisioned throughput of a table or an index, it returns nil if there is none.
+func convertThroughput(throughput *types.ProvisionedThroughputDescription) *Throughput {
+	if throughput == nil {
+		return nil
+	}
+	return &Throughput{
+		ReadCapacityUnits:      aws.ToInt64(throughput.ReadCapacityUnits),
+		WriteCapacityUnits:     aws.ToInt64(throughput.WriteCapacityUnits),
+		LastIncreaseDateTime:   throughput.LastIncreaseDateTime,
+		LastDecreaseDateTime:   throughput.LastDecreaseDateTime,
+		NumberOfDecreasesToday: aws.ToInt64(throughput.NumberOfDecreasesToday),
+	}
+}
+
+// convertKeySchema converts a key schema, resolving the attribute types from the attribute definitions.
+func convertKeySchema(keySchema []types.KeySchemaElement, definitions []types.AttributeDefinition) []KeyElement {
+	elements := make([]KeyElement, 0, len(keySchema))
+	for _, element := range keySchema {
+		keyElement := KeyElement{
+			Name:    aws.ToString(element.AttributeName),
+			KeyType: string(element.KeyType),
+		}
+		for _, definition := range definitions {
+			if aws.ToString(definition.AttributeName) == keyElement.Name {
+				keyElement.AttributeType = string(definition.AttributeType)
+			}
+		}
+		elements = append(elements, keyElement)
+	}
+	return elements
+}
+
+// convertProjection returns the projection type and the non-key attributes of an index projection.
+func convertProjection(projection *types.Projection) (string, []string) {
+	if projection == nil {
+		return "", nil
+	}
+	return string(projection.ProjectionType), projection.NonKeyAttributes
+}
+
+// NewTableDetails converts the DynamoDB table description into the table details.
+// The time to live, point in time recovery and tags are left empty, they are filled by GetTableDetails.
+func NewTableDetails(table *types.TableDescription) *TableDetails {
+	details := TableDetails{
+		Name:               aws.ToString(table.TableName),
+		Arn:                aws.ToString(table.TableArn),
+		Status:             string(table.TableStatus),
+		CreationDateTime:   table.CreationDateTime,
+		KeySchema:          convertKeySchema(table.KeySchema, table.AttributeDefinitions),
+		DeletionProtection: aws.ToBool(table.DeletionProtectionEnabled),
+		ItemCount:          aws.ToInt64(table.ItemCount),
+		SizeBytes:          aws.ToInt64(table.TableSizeBytes),
+		TableClass:         string(types.TableClassStandard),
+		Tags:               map[string]string{},
+	}
+
+	for _, definition := range table.AttributeDefinitions {
+		details.Attributes = append(details.Attributes, Attribute{
+			Name: aws.ToString(definition.AttributeName),
+			Type: string(definition.AttributeType),
+		})
+	}
+
+	// Tables created before on-demand existed don't have a billing mode summary, they are provisioned
+	details.BillingMode.Mode = string(types.BillingModeProvisioned)
+	if table.BillingModeSummary != nil {
+		details.BillingMode.Mode = string(table.BillingModeSummary.BillingMode)
+		details.BillingMode.LastUpdateToPayPerRequestDateTime = table.BillingModeSummary.LastUpdateToPayPerRequestDateTime
+	}
+	details.BillingMode.Throughput = convertThroughput(table.ProvisionedThroughput)
+
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		index := Index{
+			Name:        aws.ToString(gsi.IndexName),
+			KeySchema:   convertKeySchema(gsi.KeySchema, table.AttributeDefinitions),
+			Status:      string(gsi.IndexStatus),
+			Backfilling: aws.ToBool(gsi.Backfilling),
+			Throughput:  convertThroughput(gsi.ProvisionedThroughput),
+			ItemCount:   aws.ToInt64(gsi.ItemCount),
+			SizeBytes:   aws.ToInt64(gsi.IndexSizeBytes),
+		}
+		index.ProjectionType, index.NonKeyAttributes = convertProjection(gsi.Projection)
+		details.GlobalSecondaryIndexes = append(details.GlobalSecondaryIndexes, index)
+	}
+
+	for _, lsi := range table.LocalSecondaryIndexes {
+		index := Index{
+			Name:      aws.ToString(lsi.IndexName),
+			KeySchema: convertKeySchema(lsi.KeySchema, table.AttributeDefinitions),
+			ItemCount: aws.ToInt64(lsi.ItemCount),
+			SizeBytes: aws.ToInt64(lsi.IndexSizeBytes),
+		}
+		index.ProjectionType, index.NonKeyAttributes = convertProjection(lsi.Projection)
+		details.LocalSecondaryIndexes = append(details.LocalSecondaryIndexes, index)
+	}
+
+	if table.StreamSpecification != nil {
+		details.Stream.Enabled = aws.ToBool(table.StreamSpecification.StreamEnabled)
+		details.Stream.ViewType = string(table.StreamSpecification.StreamViewType)
+	}
+	details.Stream.LatestArn = aws.ToString(table.LatestStreamArn)
+
+	if table.SSEDescription != nil {
+		details.Encryption = Encryption{
+			Status:    string(table.SSEDescription.Status),
+			Type:      string(table.SSEDescription.SSEType),
+			KMSKeyArn: aws.ToString(table.SSEDescription.KMSMasterKeyArn),
+		}
+	}
+
+	if table.TableClassSummary != nil && table.TableClassSummary.TableClass != "" {
+		details.TableClass = string(table.TableClassSummary.TableClass)
+	}
+
+	for _, replica := range table.Replicas {
+		tableClass := ""
+		if replica.ReplicaTableClassSummary != nil {
+			tableClass = string(replica.ReplicaTableClassSummary.TableClass)
+		}
+		details.Replicas = append(details.Replicas, Replica{
+			Region:     aws.ToString(replica.RegionName),
+			Status:     string(replica.ReplicaStatus),
+			TableClass: tableClass,
+			KMSKeyId:   aws.ToString(replica.KMSMasterKeyId),
+		})
+	}
+
+	return &details
+}
+
+// GetTableDetails retrieves the full configuration of a DynamoDB table through DescribeTable, DescribeTimeToLive,
+// DescribeContinuousBackups and ListTagsOfResource.
+// It returns an error if the table can't be described, the other settings are left empty with a warning if they can't be retrieved.
+func GetTableDetails(dbmgr *client.DynamoDBManager, tableName string) (*TableDetails, error) {
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		return nil, err
+	}
+	details := NewTableDetails(table)
+
+	ttl, err := DescribeTimeToLiveClient(dbmgr, tableName)
+	if err != nil {
+		dbmgr.Logger.Warnf("Failed to get the time to live of table:%s - error:%v", tableName, err)
+	} else if ttl != nil {
+		details.TimeToLive = &TimeToLive{
+			Status:    string(ttl.TimeToLiveStatus),
+			Attribute: aws.ToString(ttl.AttributeName),
+		}
+	}
+
+	backups, err := DescribeContinuousBackupsClient(dbmgr, tableName)
+	if err != nil {
+		dbmgr.Logger.Warnf("Failed to get the continuous backups of table:%s - error:%v", tableName, err)
+	} else if backups != nil {
+		details.PointInTimeRecovery = &PointInTimeRecovery{
+			ContinuousBackupsStatus: string(backups.ContinuousBackupsStatus),
+		}
+		if pitr := backups.PointInTimeRecoveryDescription; pitr != nil {
+			details.PointInTimeRecovery.Status = string(pitr.PointInTimeRecoveryStatus)
+			details.PointInTimeRecovery.EarliestRestorableDateTime = pitr.EarliestRestorableDateTime
+			details.PointInTimeRecovery.LatestRestorableDateTime = pitr.LatestRestorableDateTime
+		}
+	}
+
+	tags, err := GetTableTagsClient(dbmgr, details.Arn)
+	if err != nil {
+		dbmgr.Logger.Warnf("Failed to get the tags of table:%s - error:%v", tableName, err)
+	}
+	for _, tag := range tags {
+		details.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return details, nil
+}
+
+// formatKeySchema formats a key schema as "name (HASH, S), name (RANGE, N)".
+func formatKeySchema(keySchema []KeyElement) string {
+	elements := make([]string, 0, len(keySchema))
+	for _, element := range keySchema {
+		elements = append(elements, fmt.Sprintf("%s (%s, %s)", element.Name, element.KeyType, element.AttributeType))
+	}
+	return strings.Join(elements, ", ")
+}
+
+// formatThroughput formats the read and write capacity units, or "on-demand" if the capacity is not provisioned.
+func formatThroughput(throughput *Throughput) string {
+	if throughput == nil || (throughput.ReadCapacityUnits == 0 && throughput.WriteCapacityUnits == 0) {
+		return "on-demand"
+	}
+	return fmt.Sprintf("RCU:%d WCU:%d", throughput.ReadCapacityUnits, throughput.WriteCapacityUnits)
+}
+
+// formatProjection formats the projection of an index with its non-key attributes.
+func formatProjection(index Index) string {
+	if len(index.NonKeyAttributes) == 0 {
+		return index.ProjectionType
+	}
+	return fmt.Sprintf("%s (%s)", index.ProjectionType, strings.Join(index.NonKeyAttributes, ", "))
+}
+
+// WriteText writes the table details in the human readable format.
+func WriteText(w io.Writer, details *TableDetails) error {
+	tw := output.NewTabWriter(w)
+
+	fmt.Fprintf(tw, "Table:\t%s\n", details.Name)
+	fmt.Fprintf(tw, "ARN:\t%s\n", details.Arn)
+	fmt.Fprintf(tw, "Status:\t%s\n", details.Status)
+	fmt.Fprintf(tw, "Created:\t%s\n", output.FormatTime(details.CreationDateTime))
+	fmt.Fprintf(tw, "Item count:\t%d\n", details.ItemCount)
+	fmt.Fprintf(tw, "Size:\t%s\n", output.FormatBytes(details.SizeBytes))
+	fmt.Fprintf(tw, "Table class:\t%s\n", details.TableClass)
+	fmt.Fprintf(tw, "Deletion protection:\t%t\n", details.DeletionProtection)
+	fmt.Fprintf(tw, "Key schema:\t%s\n", formatKeySchema(details.KeySchema))
+
+	attributes := make([]string, 0, len(details.Attributes))
+	for _, attribute := range details.Attributes {
+		attributes = append(attributes, fmt.Sprintf("%s (%s)", attribute.Name, attribute.Type))
+	}
+	fmt.Fprintf(tw, "Attributes:\t%s\n", strings.Join(attributes, ", "))
+
+	fmt.Fprintf(tw, "Billing mode:\t%s\n", details.BillingMode.Mode)
+	fmt.Fprintf(tw, "  Last switch to on-demand:\t%s\n", output.FormatTime(details.BillingMode.LastUpdateToPayPerRequestDateTime))
+	if throughput := details.BillingMode.Throughput; throughput != nil {
+		fmt.Fprintf(tw, "  Capacity:\t%s\n", formatThroughput(throughput))
+		fmt.Fprintf(tw, "  Last increase:\t%s\n", output.FormatTime(throughput.LastIncreaseDateTime))
+		fmt.Fprintf(tw, "  Last decrease:\t%s\n", output.FormatTime(throughput.LastDecreaseDateTime))
+		fmt.Fprintf(tw, "  Decreases today:\t%d\n", throughput.NumberOfDecreasesToday)
+	}
+
+	if details.Stream.Enabled {
+		fmt.Fprintf(tw, "Stream:\tenabled (%s) %s\n", details.Stream.ViewType, details.Stream.LatestArn)
+	} else {
+		fmt.Fprintf(tw, "Stream:\tdisabled\n")
+	}
+
+	if details.Encryption.Status == "" {
+		fmt.Fprintf(tw, "Encryption:\tDynamoDB owned key\n")
+	} else {
+		fmt.Fprintf(tw, "Encryption:\t%s %s %s\n", details.Encryption.Status, details.Encryption.Type, details.Encryption.KMSKeyArn)
+	}
+
+	if details.TimeToLive != nil {
+		fmt.Fprintf(tw, "Time to live:\t%s %s\n", details.TimeToLive.Status, details.TimeToLive.Attribute)
+	}
+	if pitr := details.PointInTimeRecovery; pitr != nil {
+		fmt.Fprintf(tw, "Point in time recovery:\t%s\n", pitr.Status)
+		if pitr.EarliestRestorableDateTime != nil {
+			fmt.Fprintf(tw, "  Restorable:\t%s - %s\n", output.FormatTime(pitr.EarliestRestorableDateTime), output.FormatTime(pitr.LatestRestorableDateTime))
+		}
+	}
+
+	for _, index := range details.GlobalSecondaryIndexes {
+		fmt.Fprintf(tw, "Global index:\t%s\n", index.Name)
+		fmt.Fprintf(tw, "  Key schema:\t%s\n", formatKeySchema(index.KeySchema))
+		fmt.Fprintf(tw, "  Projection:\t%s\n", formatProjection(index))
+		fmt.Fprintf(tw, "  Status:\t%s (backfilling: %t)\n", index.Status, index.Backfilling)
+		fmt.Fprintf(tw, "  Capacity:\t%s\n", formatThroughput(index.Throughput))
+		fmt.Fprintf(tw, "  Items:\t%d (%s)\n", index.ItemCount, output.FormatBytes(index.SizeBytes))
+	}
+	for _, index := range details.LocalSecondaryIndexes {
+		fmt.Fprintf(tw, "Local index:\t%s\n", index.Name)
+		fmt.Fprintf(tw, "  Key schema:\t%s\n", formatKeySchema(index.KeySchema))
+		fmt.Fprintf(tw, "  Projection:\t%s\n", formatProjection(index))
+		fmt.Fprintf(tw, "  Items:\t%d (%s)\n", index.ItemCount, output.FormatBytes(index.SizeBytes))
+	}
+
+	for _, replica := range details.Replicas {
+		fmt.Fprintf(tw, "Replica:\t%s %s %s\n", replica.Region, replica.Status, replica.TableClass)
+	}
+
+	fmt.Fprintf(tw, "Tags:\t\n")
+	for _, key := range details.SortedTagKeys() {
+		fmt.Fprintf(tw, "  %s:\t%s\n", key, details.Tags[key])
+	}
+
+	return tw.Flush()
+}
+
+// ExecuteDescribe retrieves the full configuration of a DynamoDB table and writes it in the given output format.
+// It takes a DynamoDBManager, a table name and an output format as input.
+// It returns an error if the table can't be described or the output can't be written.
+func ExecuteDescribe(dbmgr *client.DynamoDBManager, tableName string, format string) error {
+	dbmgr.Logger.Infof("Begin to describe the table:%s, ...", tableName)
+	details, err := GetTableDetails(dbmgr, tableName)
+	if err != nil {
+		return err
+	}
+
+	if format == output.JSON {
+		return output.WriteJSON(output.Stdout, details)
+	}
+	return WriteText(output.Stdout, details)
+}
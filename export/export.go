@@ -0,0 +1,478 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+)
+
+const (
+	DefaultSegments = 4
+	// checkpointSuffix is appended to the output file name to get the default checkpoint file
+	checkpointSuffix = ".checkpoint"
+)
+
+var ScanPageClient = client.ScanPage
+
+// Options describes an export of the items of a table.
+type Options struct {
+	Table        string
+	Format       string   // items.JSONL, items.DynamoDBJSON or items.CSV
+	Columns      []string // CSV columns as "attribute" or "attribute=header"
+	File         string   // Output file, stdout if empty or "-"
+	Gzip         bool     // Compress the output with gzip
+	Segments     int      // Number of segments scanned in parallel
+	ReadCapacity float64  // Read capacity units consumed per second at most, unlimited if 0
+	Checkpoint   string   // Checkpoint file, the output file name with the ".checkpoint" suffix if empty
+}
+
+// column is a CSV column with the attribute it is read from.
+type column struct {
+	Attribute string
+	Header    string
+}
+
+// parseColumns parses the CSV column spec.
+// It returns the columns and an error if an entry is empty.
+func parseColumns(spec []string) ([]column, error) {
+	columns := make([]column, 0, len(spec))
+	for _, entry := range spec {
+		attribute, header := entry, entry
+		if idx := strings.Index(entry, "="); idx >= 0 {
+			attribute, header = entry[:idx], entry[idx+1:]
+		}
+		if attribute == "" || header == "" {
+			return nil, errors.New(fmt.Sprintf("invalid column:%s, attribute or attribute=header is expected", entry))
+		}
+		columns = append(columns, column{Attribute: attribute, Header: header})
+	}
+	return columns, nil
+}
+
+// Validate checks the export options and applies the defaults.
+// It returns an error if any of them is invalid.
+func (o *Options) Validate() error {
+	if o.Table == "" {
+		return errors.New("table must be provided!")
+	}
+	if err := items.ValidateFormat(o.Format); err != nil {
+		return err
+	}
+	if o.Format == items.CSV && len(o.Columns) == 0 {
+		return errors.New("columns must be provided for the csv format!")
+	}
+	if _, err := parseColumns(o.Columns); err != nil {
+		return err
+	}
+	if o.Segments == 0 {
+		o.Segments = DefaultSegments
+	}
+	if o.Segments < 0 || o.Segments > 1000000 {
+		return errors.New(fmt.Sprintf("segments must be between 1 and 1000000:%d", o.Segments))
+	}
+	if o.ReadCapacity < 0 {
+		return errors.New(fmt.Sprintf("read capacity must not be negative:%v", o.ReadCapacity))
+	}
+	if o.File == "-" {
+		o.File = ""
+	}
+	// The output is truncated back to the checkpoint on resume, which stdout doesn't allow
+	if o.Checkpoint != "" && o.File == "" {
+		return errors.New("checkpoint can only be used with an output file!")
+	}
+	if o.Checkpoint == "" && o.File != "" {
+		o.Checkpoint = o.File + checkpointSuffix
+	}
+	return nil
+}
+
+// segmentState is the progress of a segment saved in the checkpoint.
+type segmentState struct {
+	Segment int             `json:"segment"`
+	LastKey json.RawMessage `json:"lastKey,omitempty"` // DynamoDB typed JSON of the last evaluated key
+	Done    bool            `json:"done"`
+	Items   int64           `json:"items"`
+}
+
+// checkpoint is the progress of an export, it allows an interrupted export to resume.
+type checkpoint struct {
+	Table    string         `json:"table"`
+	Format   string         `json:"format"`
+	Columns  []string       `json:"columns,omitempty"`
+	Segments int            `json:"segments"`
+	Gzip     bool           `json:"gzip"`
+	States   []segmentState `json:"states"`
+	// Offset is the size of the output once the pages of the states were written, it ends with a complete gzip member.
+	// The output is truncated back to it on resume, dropping what was written after the last checkpoint.
+	Offset *int64 `json:"offset,omitempty"`
+}
+
+// countingWriter counts the bytes written to the output, to record the size of the output file in the checkpoint.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes to the underlying writer and counts the bytes written.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// loadCheckpoint reads the checkpoint of an interrupted export matching the options.
+// It returns nil if there is no checkpoint, or an error if it doesn't match the options.
+func loadCheckpoint(opts *Options) (*checkpoint, error) {
+	if opts.Checkpoint == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(opts.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state checkpoint
+	if err = json.Unmarshal(content, &state); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse checkpoint:%s - error:%v", opts.Checkpoint, err))
+	}
+	if state.Table != opts.Table || state.Format != opts.Format || state.Segments != opts.Segments || state.Gzip != opts.Gzip ||
+		strings.Join(state.Columns, ",") != strings.Join(opts.Columns, ",") || len(state.States) != state.Segments {
+		return nil, errors.New(fmt.Sprintf("checkpoint:%s was saved with other options (table:%s - format:%s - segments:%d - gzip:%t), remove it or rerun with the same options",
+			opts.Checkpoint, state.Table, state.Format, state.Segments, state.Gzip))
+	}
+	return &state, nil
+}
+
+// exporter writes the pages scanned by the segments to the output and saves the checkpoint after each page.
+type exporter struct {
+	dbmgr   *client.DynamoDBManager
+	opts    *Options
+	columns []column
+	limiter *client.TokenBucket
+
+	mu       sync.Mutex
+	state    checkpoint
+	file     *os.File
+	counter  *countingWriter
+	gzip     *gzip.Writer
+	buffered *bufio.Writer
+	csv      *csv.Writer
+}
+
+// open opens the output and writes the CSV header of a new export.
+// When resuming, the output is truncated back to the offset of the checkpoint and appended to.
+func (e *exporter) open(resuming bool) error {
+	e.counter = &countingWriter{w: os.Stdout}
+	if e.opts.File != "" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if resuming {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		file, err := os.OpenFile(e.opts.File, flags, 0644)
+		if err != nil {
+			return err
+		}
+		e.file = file
+		e.counter.w = file
+		// The checkpoints saved before the offset was recorded are resumed at the end of the output
+		if resuming && e.state.Offset != nil {
+			if err = file.Truncate(*e.state.Offset); err != nil {
+				return err
+			}
+			e.counter.n = *e.state.Offset
+		}
+	}
+	var out io.Writer = e.counter
+
+	if e.opts.Gzip {
+		// Every page is written as a gzip member, readers decompress the concatenated members as one stream
+		e.gzip = gzip.NewWriter(out)
+		out = e.gzip
+	}
+	e.buffered = bufio.NewWriter(out)
+
+	if e.opts.Format == items.CSV {
+		e.csv = csv.NewWriter(e.buffered)
+		if !resuming {
+			headers := make([]string, 0, len(e.columns))
+			for _, col := range e.columns {
+				headers = append(headers, col.Header)
+			}
+			if err := e.csv.Write(headers); err != nil {
+				return err
+			}
+			return e.flush()
+		}
+	}
+	return nil
+}
+
+// close flushes and closes the output.
+func (e *exporter) close() error {
+	var errs []error
+	if e.csv != nil {
+		e.csv.Flush()
+		errs = append(errs, e.csv.Error())
+	}
+	errs = append(errs, e.buffered.Flush())
+	if e.gzip != nil {
+		errs = append(errs, e.gzip.Close())
+	}
+	if e.file != nil {
+		errs = append(errs, e.file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// flush pushes the written items down to the output file and completes the gzip member,
+// so the checkpoint never gets ahead of the output. It records the size of the output in the checkpoint.
+func (e *exporter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.buffered.Flush(); err != nil {
+		return err
+	}
+	if e.gzip != nil {
+		if err := e.gzip.Close(); err != nil {
+			return err
+		}
+		e.gzip.Reset(e.counter)
+	}
+	if e.file != nil {
+		offset := e.counter.n
+		e.state.Offset = &offset
+	}
+	return nil
+}
+
+// saveCheckpoint writes the checkpoint through a temporary file, so it is never left half written.
+func (e *exporter) saveCheckpoint() error {
+	if e.opts.Checkpoint == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(e.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := e.opts.Checkpoint + ".tmp"
+	if err = os.WriteFile(tmpFile, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, e.opts.Checkpoint)
+}
+
+// writePage writes the items of a page and records the progress of its segment.
+func (e *exporter) writePage(segment int, page []items.Item, lastKey items.Item) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, item := range page {
+		if e.csv != nil {
+			record := make([]string, 0, len(e.columns))
+			for _, col := range e.columns {
+				record = append(record, items.FormatCell(item[col.Attribute]))
+			}
+			if err := e.csv.Write(record); err != nil {
+				return err
+			}
+			continue
+		}
+
+		line, err := items.Marshal(item, e.opts.Format)
+		if err != nil {
+			return err
+		}
+		e.buffered.Write(line)
+		if err = e.buffered.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+
+	state := &e.state.States[segment]
+	state.Items += int64(len(page))
+	state.LastKey = nil
+	state.Done = len(lastKey) == 0
+	if !state.Done {
+		encoded, err := json.Marshal(items.ToDynamoDBItem(lastKey))
+		if err != nil {
+			return err
+		}
+		state.LastKey = encoded
+	}
+	return e.saveCheckpoint()
+}
+
+// startKey returns the key a segment resumes from, nil to start from the beginning.
+func (e *exporter) startKey(segment int) (items.Item, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	lastKey := e.state.States[segment].LastKey
+	if len(lastKey) == 0 {
+		return nil, nil
+	}
+	var typed map[string]json.RawMessage
+	if err := json.Unmarshal(lastKey, &typed); err != nil {
+		return nil, err
+	}
+	return items.FromDynamoDBItem(typed)
+}
+
+// scanSegment scans a segment page by page until it is done or the context is cancelled.
+func (e *exporter) scanSegment(ctx context.Context, segment int) error {
+	startKey, err := e.startKey(segment)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		input := &dynamodb.ScanInput{
+			TableName:              aws.String(e.opts.Table),
+			Segment:                aws.Int32(int32(segment)),
+			TotalSegments:          aws.Int32(int32(e.opts.Segments)),
+			ExclusiveStartKey:      startKey,
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		}
+		output, err := ScanPageClient(ctx, e.dbmgr, input)
+		if err != nil {
+			return err
+		}
+
+		if err = e.writePage(segment, output.Items, output.LastEvaluatedKey); err != nil {
+			return err
+		}
+		e.dbmgr.Logger.Debugf("Segment:%d of table:%s - exported %d item(s)", segment, e.opts.Table, len(output.Items))
+
+		if e.limiter != nil && output.ConsumedCapacity != nil {
+			if err = e.limiter.Wait(ctx, aws.ToFloat64(output.ConsumedCapacity.CapacityUnits)); err != nil {
+				return err
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = output.LastEvaluatedKey
+	}
+}
+
+// exportedItems returns the number of items exported so far and whether every segment is done.
+func (e *exporter) exportedItems() (int64, bool) {
+	var total int64
+	done := true
+	for _, state := range e.state.States {
+		total += state.Items
+		done = done && state.Done
+	}
+	return total, done
+}
+
+// ExecuteExport exports the items of a table with a parallel segmented scan.
+// It takes a DynamoDBManager and the export options as input.
+// The progress is checkpointed after every page: an interrupted export resumes from the checkpoint when rerun with the same options,
+// the output written after the last checkpoint is dropped then.
+// It returns an error if the export fails or is interrupted.
+func ExecuteExport(dbmgr *client.DynamoDBManager, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	columns, _ := parseColumns(opts.Columns)
+
+	previous, err := loadCheckpoint(&opts)
+	if err != nil {
+		return err
+	}
+
+	e := exporter{dbmgr: dbmgr, opts: &opts, columns: columns}
+	if opts.ReadCapacity > 0 {
+		e.limiter = client.NewTokenBucket(opts.ReadCapacity, int(opts.ReadCapacity))
+	}
+	if previous != nil {
+		e.state = *previous
+		dbmgr.Logger.Infof("Resuming the export of table:%s from checkpoint:%s", opts.Table, opts.Checkpoint)
+	} else {
+		e.state = checkpoint{Table: opts.Table, Format: opts.Format, Columns: opts.Columns, Segments: opts.Segments, Gzip: opts.Gzip}
+		for segment := 0; segment < opts.Segments; segment++ {
+			e.state.States = append(e.state.States, segmentState{Segment: segment})
+		}
+	}
+
+	if err = e.open(previous != nil); err != nil {
+		return errors.New(fmt.Sprintf("Failed to open the output:%s - error:%v", opts.File, err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dbmgr.Logger.Infof("Begin to export table:%s with %d segment(s) as %s, ...", opts.Table, opts.Segments, opts.Format)
+	var wg sync.WaitGroup
+	errs := make([]error, opts.Segments)
+	for segment := 0; segment < opts.Segments; segment++ {
+		if e.state.States[segment].Done {
+			continue
+		}
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			if err := e.scanSegment(ctx, segment); err != nil {
+				errs[segment] = err
+				// Stop the other segments, the export resumes from the checkpoint
+				cancel()
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	errClose := e.close()
+	total, done := e.exportedItems()
+	err = errors.Join(errors.Join(errs...), errClose)
+	if err == nil && !done {
+		err = errors.New("some segments are not done")
+	}
+	if err != nil {
+		if opts.Checkpoint != "" {
+			e.saveCheckpoint()
+			dbmgr.Logger.Warnf("Export of table:%s interrupted after %d item(s), rerun the same command to resume from checkpoint:%s", opts.Table, total, opts.Checkpoint)
+		}
+		return fmt.Errorf("Failed to export table:%s - %w", opts.Table, err)
+	}
+
+	if opts.Checkpoint != "" {
+		os.Remove(opts.Checkpoint)
+	}
+	dbmgr.Logger.Infof("Exported %d item(s) of table:%s", total, opts.Table)
+	return nil
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string // Part of the error message, empty if the options are valid
+	}{
+		{"stdout", Options{Table: "orders", Format: items.JSONL}, ""},
+		{"output file", Options{Table: "orders", Format: items.JSONL, File: "orders.jsonl"}, ""},
+		{"checkpoint with output file", Options{Table: "orders", Format: items.JSONL, File: "orders.jsonl", Checkpoint: "orders.state"}, ""},
+		{"checkpoint with stdout", Options{Table: "orders", Format: items.JSONL, Checkpoint: "orders.state"}, "output file"},
+		{"checkpoint with dash", Options{Table: "orders", Format: items.JSONL, File: "-", Checkpoint: "orders.state"}, "output file"},
+		{"csv without columns", Options{Table: "orders", Format: items.CSV}, "columns must be provided"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate() error = %v", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("Validate() returned no error, want %q", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	opts := Options{Table: "orders", Format: items.JSONL, File: "orders.jsonl"}
+	if err := opts.Validate(); err != nil || opts.Checkpoint != "orders.jsonl"+checkpointSuffix {
+		t.Errorf("Validate() checkpoint = %q, error = %v", opts.Checkpoint, err)
+	}
+}
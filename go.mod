@@ -6,23 +6,28 @@ require (
 	github.com/ForrestIsARealGoodman/dynamodb-manager/client v0.0.0-20240221110741-558121082fe7
 	github.com/ForrestIsARealGoodman/dynamodb-manager/search v0.0.0-20240221110741-558121082fe7
 	github.com/ForrestIsARealGoodman/dynamodb-manager/update v0.0.0-20240221110741-558121082fe7
+	github.com/aws/aws-sdk-go-v2 v1.25.0
+	github.com/aws/aws-sdk-go-v2/service/cloudwatch v1.34.0
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.29.1
 	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.18.2
+	golang.org/x/term v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/ForrestIsARealGoodman/dynamodb-manager/logging v0.0.0-20240221102801-90c8c4ef4e1f // indirect
-	github.com/aws/aws-sdk-go-v2 v1.25.0 // indirect
+	github.com/ForrestIsARealGoodman/dynamodb-manager/logging v0.0.0-20240221110741-558121082fe7 // indirect
 	github.com/aws/aws-sdk-go-v2/config v1.27.1 // indirect
 	github.com/aws/aws-sdk-go-v2/credentials v1.17.1 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.15.0 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.0 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.0 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/ini v1.8.0 // indirect
-	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.29.1 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.11.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/endpoint-discovery v1.9.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.11.0 // indirect
+	github.com/aws/aws-sdk-go-v2/service/kms v1.28.2 // indirect
 	github.com/aws/aws-sdk-go-v2/service/sso v1.19.1 // indirect
 	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.22.1 // indirect
 	github.com/aws/aws-sdk-go-v2/service/sts v1.27.1 // indirect
@@ -39,7 +44,6 @@ require (
 	github.com/sourcegraph/conc v0.3.0 // indirect
 	github.com/spf13/afero v1.11.0 // indirect
 	github.com/spf13/cast v1.6.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
 	github.com/texttheater/golang-levenshtein/levenshtein v0.0.0-20200805054039-cae8b0eaed6c // indirect
 	go.uber.org/multierr v1.10.0 // indirect
@@ -48,5 +52,11 @@ require (
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/ForrestIsARealGoodman/dynamodb-manager/client => ./client
+	github.com/ForrestIsARealGoodman/dynamodb-manager/logging => ./logging
+	github.com/ForrestIsARealGoodman/dynamodb-manager/search => ./search
+	github.com/ForrestIsARealGoodman/dynamodb-manager/update => ./update
 )
@@ -0,0 +1,405 @@
+package gsi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+)
+
+// DefaultTimeout is how long the creation or deletion of an index is tracked, the backfill of a large table may take hours
+const DefaultTimeout = 6 * time.Hour
+
+// Statuses of an index operation, besides the index statuses reported by DynamoDB
+const (
+	StatusPending   string = "PENDING"
+	StatusUnchanged string = "UNCHANGED"
+	StatusDeleted   string = "DELETED"
+	StatusFailed           = outcome.StatusFailed
+)
+
+var (
+	DescribeTableClient = client.DescribeTable
+	UpdateTableClient   = client.UpdateTable
+)
+
+// PollInterval is the delay between the checks of the index status
+var PollInterval = 10 * time.Second
+
+// validIndexName matches the names DynamoDB accepts for indexes
+var validIndexName = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+// KeyAttribute is a key attribute of an index and its scalar type.
+type KeyAttribute struct {
+	Name string
+	Type string // S, N or B
+}
+
+// ParseKeyAttribute parses a key attribute given as name:type, e.g. customerId:S.
+// It returns an error if the name or the type is missing or the type isn't scalar.
+func ParseKeyAttribute(value string) (KeyAttribute, error) {
+	name, attributeType, found := strings.Cut(value, ":")
+	attribute := KeyAttribute{Name: name, Type: strings.ToUpper(attributeType)}
+	if !found || name == "" {
+		return attribute, errors.New(fmt.Sprintf("invalid key attribute:%s, name:type is expected, e.g. customerId:S", value))
+	}
+	for _, scalar := range types.ScalarAttributeType("").Values() {
+		if string(scalar) == attribute.Type {
+			return attribute, nil
+		}
+	}
+	return attribute, errors.New(fmt.Sprintf("invalid type of key attribute:%s, S, N or B is expected", value))
+}
+
+// Definition describes a global secondary index to create.
+type Definition struct {
+	Name             string
+	PartitionKey     KeyAttribute
+	SortKey          *KeyAttribute
+	Projection       string // ALL if not set, KEYS_ONLY or INCLUDE
+	NonKeyAttributes []string
+	ReadCapacity     int64 // Provisioned tables only, client.DefaultRcu if not set
+	WriteCapacity    int64 // Provisioned tables only, client.DefaultWcu if not set
+}
+
+// Validate checks the definition locally, the checks against the table are done on creation.
+// It returns an error if the name, the projection or the capacity is invalid.
+func (d *Definition) Validate() error {
+	if !validIndexName.MatchString(d.Name) {
+		return errors.New(fmt.Sprintf("invalid index name:%s", d.Name))
+	}
+	if d.SortKey != nil && d.SortKey.Name == d.PartitionKey.Name {
+		return errors.New(fmt.Sprintf("index:%s uses attribute:%s as both partition and sort key", d.Name, d.PartitionKey.Name))
+	}
+	switch {
+	case d.Projection != "" && d.Projection != string(types.ProjectionTypeAll) &&
+		d.Projection != string(types.ProjectionTypeKeysOnly) && d.Projection != string(types.ProjectionTypeInclude):
+		return errors.New(fmt.Sprintf("invalid projection:%s, ALL, KEYS_ONLY or INCLUDE is expected", d.Projection))
+	case d.Projection == string(types.ProjectionTypeInclude) && len(d.NonKeyAttributes) == 0:
+		return errors.New("the INCLUDE projection needs the non-key attributes to include")
+	case d.Projection != string(types.ProjectionTypeInclude) && len(d.NonKeyAttributes) > 0:
+		return errors.New("non-key attributes can only be included with the INCLUDE projection")
+	}
+	if d.ReadCapacity < 0 || d.WriteCapacity < 0 {
+		return errors.New(fmt.Sprintf("index:%s has a negative capacity", d.Name))
+	}
+	return nil
+}
+
+// keyAttributes returns the key attributes of the index.
+func (d *Definition) keyAttributes() []KeyAttribute {
+	attributes := []KeyAttribute{d.PartitionKey}
+	if d.SortKey != nil {
+		attributes = append(attributes, *d.SortKey)
+	}
+	return attributes
+}
+
+// Index is the state of a global secondary index, or the outcome of an operation on it.
+type Index struct {
+	Table string `json:"table"`
+	Name  string `json:"index"`
+	outcome.Outcome
+	Backfilling  bool   `json:"backfilling"`
+	PartitionKey string `json:"partitionKey,omitempty"`
+	SortKey      string `json:"sortKey,omitempty"`
+	Projection   string `json:"projection,omitempty"`
+	Items        int64  `json:"itemCount"`
+	Size         int64  `json:"sizeBytes"`
+}
+
+// newIndex converts the description of a global secondary index.
+func newIndex(tableName string, description types.GlobalSecondaryIndexDescription) Index {
+	index := Index{
+		Table:       tableName,
+		Name:        aws.ToString(description.IndexName),
+		Backfilling: aws.ToBool(description.Backfilling),
+		Items:       aws.ToInt64(description.ItemCount),
+		Size:        aws.ToInt64(description.IndexSizeBytes),
+	}
+	index.Status = string(description.IndexStatus)
+	for _, key := range description.KeySchema {
+		if key.KeyType == types.KeyTypeHash {
+			index.PartitionKey = aws.ToString(key.AttributeName)
+		} else {
+			index.SortKey = aws.ToString(key.AttributeName)
+		}
+	}
+	if description.Projection != nil {
+		index.Projection = string(description.Projection.ProjectionType)
+		if len(description.Projection.NonKeyAttributes) > 0 {
+			index.Projection += " " + strings.Join(description.Projection.NonKeyAttributes, ",")
+		}
+	}
+	return index
+}
+
+// findIndex returns the description of the named global secondary index of the table, or nil if the table has no such index.
+func findIndex(table *types.TableDescription, indexName string) *types.GlobalSecondaryIndexDescription {
+	for i := range table.GlobalSecondaryIndexes {
+		if aws.ToString(table.GlobalSecondaryIndexes[i].IndexName) == indexName {
+			return &table.GlobalSecondaryIndexes[i]
+		}
+	}
+	return nil
+}
+
+// WriteIndexes writes the index states or operation outcomes in the given output format.
+func WriteIndexes(w io.Writer, indexes []Index, format string) error {
+	if format == output.JSON {
+		if indexes == nil {
+			indexes = []Index{}
+		}
+		return output.WriteJSON(w, indexes)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tINDEX\tSTATUS\tBACKFILLING\tKEYS\tPROJECTION\tITEMS\tSIZE\tERROR\n")
+	for _, index := range indexes {
+		keys := index.PartitionKey
+		if index.SortKey != "" {
+			keys += ", " + index.SortKey
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%d\t%s\t%s\n", index.Table, index.Name, index.Status, index.Backfilling,
+			keys, index.Projection, index.Items, output.FormatBytes(index.Size), index.Error)
+	}
+	return tw.Flush()
+}
+
+// ExecuteStatus writes the state of the global secondary indexes of every given table in the given output format,
+// including whether they are still backfilling.
+// It takes a DynamoDBManager, the table names and the output format as input.
+// It returns client.ErrPartialFailure if some tables couldn't be described, or the error of the failure if none could.
+func ExecuteStatus(dbmgr *client.DynamoDBManager, tables []string, format string) error {
+	var indexes []Index
+	failed := 0
+	var lastErr error
+	for _, tableName := range tables {
+		table, err := DescribeTableClient(dbmgr, tableName)
+		if err != nil {
+			index := Index{Table: tableName}
+			index.Fail(err)
+			indexes = append(indexes, index)
+			failed, lastErr = failed+1, err
+			continue
+		}
+		for _, description := range table.GlobalSecondaryIndexes {
+			indexes = append(indexes, newIndex(tableName, description))
+		}
+	}
+
+	if err := WriteIndexes(output.Stdout, indexes, format); err != nil {
+		return err
+	}
+	return outcome.Summary("status", "table(s)", failed, len(tables), lastErr)
+}
+
+// createInput builds the update of the table adding the index, with the definitions of its key attributes
+// and the capacity matching the billing mode of the table.
+// It returns an error if a key attribute is already defined by the table with another type,
+// or a capacity is given for an on-demand table.
+func createInput(table *types.TableDescription, definition Definition) (*dynamodb.UpdateTableInput, error) {
+	defined := map[string]types.ScalarAttributeType{}
+	for _, attribute := range table.AttributeDefinitions {
+		defined[aws.ToString(attribute.AttributeName)] = attribute.AttributeType
+	}
+	var attributes []types.AttributeDefinition
+	var keySchema []types.KeySchemaElement
+	for i, key := range definition.keyAttributes() {
+		if current, exists := defined[key.Name]; exists && string(current) != key.Type {
+			return nil, errors.New(fmt.Sprintf("key attribute:%s is defined as %s by the table, not %s", key.Name, current, key.Type))
+		}
+		attributes = append(attributes, types.AttributeDefinition{AttributeName: aws.String(key.Name), AttributeType: types.ScalarAttributeType(key.Type)})
+		keyType := types.KeyTypeHash
+		if i > 0 {
+			keyType = types.KeyTypeRange
+		}
+		keySchema = append(keySchema, types.KeySchemaElement{AttributeName: aws.String(key.Name), KeyType: keyType})
+	}
+
+	projection := &types.Projection{ProjectionType: types.ProjectionTypeAll}
+	if definition.Projection != "" {
+		projection = &types.Projection{ProjectionType: types.ProjectionType(definition.Projection), NonKeyAttributes: definition.NonKeyAttributes}
+	}
+	create := &types.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String(definition.Name),
+		KeySchema:  keySchema,
+		Projection: projection,
+	}
+
+	onDemand := table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode == types.BillingModePayPerRequest
+	switch {
+	case onDemand && (definition.ReadCapacity > 0 || definition.WriteCapacity > 0):
+		return nil, errors.New(fmt.Sprintf("table:%s is on-demand, its indexes have no provisioned capacity", aws.ToString(table.TableName)))
+	case !onDemand:
+		rcu, wcu := definition.ReadCapacity, definition.WriteCapacity
+		if rcu == 0 {
+			rcu = client.DefaultRcu
+		}
+		if wcu == 0 {
+			wcu = client.DefaultWcu
+		}
+		create.ProvisionedThroughput = &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(rcu), WriteCapacityUnits: aws.Int64(wcu)}
+	}
+
+	return &dynamodb.UpdateTableInput{
+		TableName:                   table.TableName,
+		AttributeDefinitions:        attributes,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{Create: create}},
+	}, nil
+}
+
+// waitForIndex polls the index until it is active, or gone once deleted, logging its status and backfill progress as they change.
+// It returns the last state of the index, and an error if the table can't be described or the timeout expires.
+func waitForIndex(dbmgr *client.DynamoDBManager, index Index, deleted bool, timeout time.Duration) (Index, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		table, err := DescribeTableClient(dbmgr, index.Table)
+		if err != nil {
+			return index, err
+		}
+		description := findIndex(table, index.Name)
+		if description == nil {
+			if deleted {
+				index.Status, index.Backfilling = StatusDeleted, false
+				return index, nil
+			}
+			return index, errors.New(fmt.Sprintf("index:%s of table:%s doesn't exist", index.Name, index.Table))
+		}
+
+		current := newIndex(index.Table, *description)
+		if current.Status != index.Status || current.Backfilling != index.Backfilling {
+			dbmgr.Logger.Infof("Index:%s of table:%s is %s (backfilling: %t)", current.Name, current.Table, current.Status, current.Backfilling)
+		}
+		index = current
+		switch {
+		case !deleted && index.Status == string(types.IndexStatusActive):
+			return index, nil
+		case time.Now().After(deadline):
+			return index, errors.New(fmt.Sprintf("index:%s of table:%s is still %s after %s", index.Name, index.Table, index.Status, timeout))
+		}
+		time.Sleep(PollInterval)
+	}
+}
+
+// finish writes the outcome of an operation on an index and returns its error.
+func finish(index Index, operation string, format string) error {
+	if err := WriteIndexes(output.Stdout, []Index{index}, format); err != nil {
+		return err
+	}
+	switch index.Status {
+	case StatusPending:
+		return fmt.Errorf("%s of index:%s of table:%s pending - %w", operation, index.Name, index.Table, client.ErrPendingChanges)
+	case StatusFailed:
+		return index.Err()
+	}
+	return nil
+}
+
+// ExecuteCreate adds a global secondary index to a table, and tracks it until it is backfilled and active unless 'wait' is false.
+// It takes a DynamoDBManager, the table name, the index definition, the wait flag and timeout, the dry-run flag
+// and the output format of the report as input.
+// An existing index of the same name is left as it is, without comparing it with the definition.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, or an error if the definition doesn't fit the table,
+// the update fails or the index isn't active in time.
+func ExecuteCreate(dbmgr *client.DynamoDBManager, tableName string, definition Definition, wait bool, timeout time.Duration,
+	dryRun bool, format string) error {
+	if err := definition.Validate(); err != nil {
+		return err
+	}
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		return err
+	}
+	if existing := findIndex(table, definition.Name); existing != nil {
+		dbmgr.Logger.Warnf("No need to create, as table:%s already has index:%s!", tableName, definition.Name)
+		index := newIndex(tableName, *existing)
+		index.Status = StatusUnchanged
+		return finish(index, "creation", format)
+	}
+	input, err := createInput(table, definition)
+	if err != nil {
+		return err
+	}
+
+	index := Index{Table: tableName, Name: definition.Name, Outcome: outcome.Outcome{Status: StatusPending}, PartitionKey: definition.PartitionKey.Name,
+		Projection: string(input.GlobalSecondaryIndexUpdates[0].Create.Projection.ProjectionType)}
+	if definition.SortKey != nil {
+		index.SortKey = definition.SortKey.Name
+	}
+	if dryRun {
+		return finish(index, "creation", format)
+	}
+
+	if _, err = UpdateTableClient(dbmgr, input); err != nil {
+		index.Fail(err)
+		return finish(index, "creation", format)
+	}
+	index.Status = string(types.IndexStatusCreating)
+	dbmgr.Logger.Infof("Creating index:%s of table:%s", definition.Name, tableName)
+	if wait {
+		if index, err = waitForIndex(dbmgr, index, false, timeout); err != nil {
+			index.Fail(err)
+		}
+	}
+	return finish(index, "creation", format)
+}
+
+// ExecuteDelete deletes a global secondary index of a table, and tracks it until it is gone unless 'wait' is false.
+// It takes a DynamoDBManager, the table name, the index name, the wait flag and timeout, the dry-run and confirmation flags
+// and the output format of the report as input.
+// The index is deleted once the user confirms, unless 'yes' is set, a missing index is left as it is.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, or an error if the update fails or the index isn't gone in time.
+func ExecuteDelete(dbmgr *client.DynamoDBManager, tableName string, indexName string, wait bool, timeout time.Duration,
+	dryRun bool, yes bool, format string) error {
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		return err
+	}
+	existing := findIndex(table, indexName)
+	if existing == nil {
+		dbmgr.Logger.Warnf("No need to delete, as table:%s has no index:%s!", tableName, indexName)
+		return finish(Index{Table: tableName, Name: indexName, Outcome: outcome.Outcome{Status: StatusUnchanged}}, "deletion", format)
+	}
+
+	index := newIndex(tableName, *existing)
+	index.Status = StatusPending
+	if dryRun {
+		return finish(index, "deletion", format)
+	}
+	plan := func(w io.Writer) error { return WriteIndexes(w, []Index{index}, output.Text) }
+	question := fmt.Sprintf("Delete index:%s of table:%s? Queries on it will fail.", indexName, tableName)
+	if err = prompt.ApprovePlan(yes, question, plan); err != nil {
+		return err
+	}
+
+	_, err = UpdateTableClient(dbmgr, &dynamodb.UpdateTableInput{
+		TableName: aws.String(tableName),
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{
+			Delete: &types.DeleteGlobalSecondaryIndexAction{IndexName: aws.String(indexName)},
+		}},
+	})
+	if err != nil {
+		index.Fail(err)
+		return finish(index, "deletion", format)
+	}
+	index.Status = string(types.IndexStatusDeleting)
+	dbmgr.Logger.Infof("Deleting index:%s of table:%s", indexName, tableName)
+	if wait {
+		if index, err = waitForIndex(dbmgr, index, true, timeout); err != nil {
+			index.Fail(err)
+		}
+	}
+	return finish(index, "deletion", format)
+}
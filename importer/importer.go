@@ -0,0 +1,544 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+)
+
+const (
+	BatchSize         = 25 // Maximum number of requests of a BatchWriteItem call
+	DefaultMaxRetries = 8
+	// rejectSuffix is appended to the input file name to get the default reject file
+	rejectSuffix   = ".rejects"
+	initialBackoff = 100 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+var (
+	DescribeTableClient  = client.DescribeTable
+	BatchWriteItemClient = client.BatchWriteItem
+)
+
+// Options describes an import of items into a table.
+type Options struct {
+	Table         string
+	File          string   // Input file, stdin if empty or "-", gzip compressed input is detected
+	Format        string   // items.JSONL, items.DynamoDBJSON or items.CSV
+	Types         []string // CSV column types as "column=type", columns without a type are strings
+	WriteCapacity float64  // Write capacity units consumed per second at most, unlimited if 0
+	MaxRetries    int      // Retries of the unprocessed items of a batch before they are rejected
+	RejectFile    string   // Reject file, the input file name with the ".rejects" suffix if empty
+	DryRun        bool     // Only read and validate the items
+}
+
+// parseTypes parses the CSV column types.
+// It returns the type of each column and an error if an entry is malformed or the type is not recognized.
+func parseTypes(spec []string) (map[string]string, error) {
+	columnTypes := make(map[string]string, len(spec))
+	for _, entry := range spec {
+		idx := strings.Index(entry, "=")
+		if idx <= 0 {
+			return nil, errors.New(fmt.Sprintf("invalid column type:%s, column=type is expected", entry))
+		}
+		column, attributeType := entry[:idx], strings.ToUpper(entry[idx+1:])
+		if err := items.ValidateType(attributeType); err != nil {
+			return nil, err
+		}
+		columnTypes[column] = attributeType
+	}
+	return columnTypes, nil
+}
+
+// Validate checks the import options and applies the defaults.
+// It returns an error if any of them is invalid.
+func (o *Options) Validate() error {
+	if o.Table == "" {
+		return errors.New("table must be provided!")
+	}
+	if err := items.ValidateFormat(o.Format); err != nil {
+		return err
+	}
+	if len(o.Types) > 0 && o.Format != items.CSV {
+		return errors.New("types can only be provided for the csv format!")
+	}
+	if _, err := parseTypes(o.Types); err != nil {
+		return err
+	}
+	if o.WriteCapacity < 0 {
+		return errors.New(fmt.Sprintf("write capacity must not be negative:%v", o.WriteCapacity))
+	}
+	if o.MaxRetries < 0 {
+		return errors.New(fmt.Sprintf("max retries must not be negative:%d", o.MaxRetries))
+	}
+	if o.File == "-" {
+		o.File = ""
+	}
+	if o.RejectFile == "" && o.File != "" {
+		o.RejectFile = o.File + rejectSuffix
+	}
+	return nil
+}
+
+// record is an item read from the input with the line it starts at.
+// 'err' is set if the line couldn't be parsed into an item.
+type record struct {
+	Line int
+	Raw  string
+	Item items.Item
+	err  error
+}
+
+// recordReader reads the records of the input one by one, it returns io.EOF after the last one.
+type recordReader interface {
+	next() (*record, error)
+}
+
+// lineReader reads one JSON item per line, in plain or DynamoDB typed JSON.
+type lineReader struct {
+	reader *bufio.Reader
+	format string
+	line   int
+}
+
+func (r *lineReader) next() (*record, error) {
+	for {
+		content, err := r.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(content) == 0) {
+			return nil, err
+		}
+		r.line++
+		content = bytes.TrimSpace(content)
+		if len(content) == 0 {
+			continue
+		}
+		item, errParse := items.Unmarshal(content, r.format)
+		return &record{Line: r.line, Raw: string(content), Item: item, err: errParse}, nil
+	}
+}
+
+// csvReader reads one item per CSV record, the header holds the attribute names.
+// Empty cells are left out of the item.
+type csvReader struct {
+	reader  *csv.Reader
+	header  []string
+	columns map[string]string
+}
+
+// newCSVReader reads the header of the CSV input.
+// It returns an error if the header can't be read or holds an attribute more than once.
+func newCSVReader(input io.Reader, columnTypes map[string]string) (*csvReader, error) {
+	reader := csv.NewReader(input)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read the csv header - error:%v", err))
+	}
+	seen := make(map[string]bool, len(header))
+	for _, name := range header {
+		if name == "" || seen[name] {
+			return nil, errors.New(fmt.Sprintf("csv header must hold distinct non-empty attribute names:%s", strings.Join(header, ",")))
+		}
+		seen[name] = true
+	}
+	for column := range columnTypes {
+		if !seen[column] {
+			return nil, errors.New(fmt.Sprintf("column:%s of the types is not in the csv header", column))
+		}
+	}
+	return &csvReader{reader: reader, header: header, columns: columnTypes}, nil
+}
+
+// encodeCSV writes the fields back as a CSV record, quoted as needed so that a rejected record can be imported again.
+func encodeCSV(fields []string) string {
+	var content strings.Builder
+	writer := csv.NewWriter(&content)
+	_ = writer.Write(fields)
+	writer.Flush()
+	return strings.TrimSuffix(content.String(), "\n")
+}
+
+func (r *csvReader) next() (*record, error) {
+	fields, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if err != nil && !errors.As(err, &parseErr) {
+		return nil, err
+	}
+	rec := &record{Raw: encodeCSV(fields), err: err}
+	if err != nil {
+		rec.Line = parseErr.StartLine
+		return rec, nil
+	}
+	rec.Line, _ = r.reader.FieldPos(0)
+
+	rec.Item = make(items.Item, len(fields))
+	for idx, cell := range fields {
+		if cell == "" {
+			continue
+		}
+		name := r.header[idx]
+		attributeType, exists := r.columns[name]
+		if !exists {
+			attributeType = items.TypeString
+		}
+		value, errParse := items.ParseCell(cell, attributeType)
+		if errParse != nil {
+			rec.err = errors.New(fmt.Sprintf("attribute:%s - %v", name, errParse))
+			return rec, nil
+		}
+		rec.Item[name] = value
+	}
+	return rec, nil
+}
+
+// keySchema is the primary key of the table the items are validated against.
+type keySchema struct {
+	names []string
+	types map[string]types.ScalarAttributeType
+}
+
+// newKeySchema gets the primary key from the table description.
+func newKeySchema(table *types.TableDescription) keySchema {
+	schema := keySchema{types: map[string]types.ScalarAttributeType{}}
+	for _, element := range table.KeySchema {
+		schema.names = append(schema.names, aws.ToString(element.AttributeName))
+	}
+	for _, definition := range table.AttributeDefinitions {
+		schema.types[aws.ToString(definition.AttributeName)] = definition.AttributeType
+	}
+	return schema
+}
+
+// validate checks that the item holds every key attribute with the type of the key schema.
+// It returns an error describing the first mismatch.
+func (k keySchema) validate(item items.Item) error {
+	for _, name := range k.names {
+		value, exists := item[name]
+		if !exists {
+			return errors.New(fmt.Sprintf("key attribute:%s is missing", name))
+		}
+		valid := false
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			valid = k.types[name] == types.ScalarAttributeTypeS && v.Value != ""
+		case *types.AttributeValueMemberN:
+			valid = k.types[name] == types.ScalarAttributeTypeN
+		case *types.AttributeValueMemberB:
+			valid = k.types[name] == types.ScalarAttributeTypeB && len(v.Value) > 0
+		}
+		if !valid {
+			return errors.New(fmt.Sprintf("key attribute:%s must be a non-empty value of type %s", name, k.types[name]))
+		}
+	}
+	return nil
+}
+
+// id identifies the item by its key, to spot duplicated keys and match the unprocessed items.
+func (k keySchema) id(item items.Item) string {
+	encoded, _ := items.Marshal(items.Key(item, k.names), items.DynamoDBJSON)
+	return string(encoded)
+}
+
+// reject is a record that couldn't be imported, written as a JSON line to the reject file.
+type reject struct {
+	Line   int    `json:"line"`
+	Error  string `json:"error"`
+	Record string `json:"record"`
+}
+
+// importer validates the records and writes them to the table in batches.
+type importer struct {
+	ctx     context.Context
+	dbmgr   *client.DynamoDBManager
+	opts    *Options
+	keys    keySchema
+	limiter *client.TokenBucket
+
+	batch    []*record
+	batchIDs map[string]bool
+	rejects  *os.File
+
+	read     int64
+	imported int64
+	rejected int64
+}
+
+// reject records a failed record and writes it to the reject file, created on the first reject.
+func (i *importer) reject(rec *record, err error) error {
+	i.rejected++
+	if i.opts.RejectFile == "" {
+		i.dbmgr.Logger.Warnf("Rejected line:%d of the input - error:%v", rec.Line, err)
+		return nil
+	}
+	i.dbmgr.Logger.Debugf("Rejected line:%d of the input - error:%v", rec.Line, err)
+
+	if i.rejects == nil {
+		file, errCreate := os.Create(i.opts.RejectFile)
+		if errCreate != nil {
+			return errors.New(fmt.Sprintf("Failed to create the reject file:%s - error:%v", i.opts.RejectFile, errCreate))
+		}
+		i.rejects = file
+	}
+	encoded, errEncode := json.Marshal(reject{Line: rec.Line, Error: err.Error(), Record: rec.Raw})
+	if errEncode != nil {
+		return errEncode
+	}
+	_, errWrite := i.rejects.Write(append(encoded, '\n'))
+	return errWrite
+}
+
+// add validates a record and adds it to the batch, the batch is written once full.
+func (i *importer) add(rec *record) error {
+	i.read++
+	if rec.err != nil {
+		return i.reject(rec, rec.err)
+	}
+	if err := i.keys.validate(rec.Item); err != nil {
+		return i.reject(rec, err)
+	}
+	if i.opts.DryRun {
+		return nil
+	}
+
+	// A batch can't write the same key twice
+	id := i.keys.id(rec.Item)
+	if i.batchIDs[id] {
+		if err := i.flush(); err != nil {
+			return err
+		}
+	}
+	i.batch = append(i.batch, rec)
+	i.batchIDs[id] = true
+	if len(i.batch) == BatchSize {
+		return i.flush()
+	}
+	return nil
+}
+
+// backoff returns the delay before the given retry, exponential with full jitter.
+func backoff(retry int) time.Duration {
+	delay := maxBackoff
+	if retry < 16 {
+		delay = initialBackoff << retry
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// flush writes the batch and starts a new one.
+// It returns an error only if the import can't go on, e.g. the table doesn't exist or the access is denied.
+func (i *importer) flush() error {
+	pending := i.batch
+	i.batch = nil
+	i.batchIDs = make(map[string]bool, BatchSize)
+	return i.write(pending)
+}
+
+// write writes the records with a single batch, retrying its unprocessed items with backoff.
+// DynamoDB rejects the whole batch if any of its items is invalid, so the batch is split in halves
+// until the invalid records are isolated, and only those are rejected.
+// Items still unprocessed after the retries, or rejected by DynamoDB, go to the reject file.
+// It returns an error only if the import can't go on, e.g. the table doesn't exist or the access is denied.
+func (i *importer) write(pending []*record) error {
+	for retry := 0; len(pending) > 0; retry++ {
+		requests := make([]types.WriteRequest, 0, len(pending))
+		for _, rec := range pending {
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: rec.Item}})
+		}
+		output, err := BatchWriteItemClient(i.dbmgr, &dynamodb.BatchWriteItemInput{
+			RequestItems:           map[string][]types.WriteRequest{i.opts.Table: requests},
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		})
+
+		var unprocessed []types.WriteRequest
+		switch {
+		case errors.Is(err, client.ErrTableNotFound), errors.Is(err, client.ErrAccessDenied):
+			return err
+		case errors.Is(err, client.ErrThrottled):
+			// Retried as a whole like unprocessed items
+			unprocessed = requests
+		case errors.Is(err, client.ErrValidation) && len(pending) > 1:
+			half := len(pending) / 2
+			i.dbmgr.Logger.Debugf("Batch of %d item(s) of table:%s rejected, retrying it in halves - error:%v", len(pending), i.opts.Table, err)
+			if err = i.write(pending[:half]); err != nil {
+				return err
+			}
+			return i.write(pending[half:])
+		case err != nil:
+			for _, rec := range pending {
+				if errReject := i.reject(rec, err); errReject != nil {
+					return errReject
+				}
+			}
+			return nil
+		default:
+			unprocessed = output.UnprocessedItems[i.opts.Table]
+			i.imported += int64(len(pending) - len(unprocessed))
+			if i.limiter != nil {
+				var consumed float64
+				for _, capacity := range output.ConsumedCapacity {
+					consumed += aws.ToFloat64(capacity.CapacityUnits)
+				}
+				if err = i.limiter.Wait(i.ctx, consumed); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Match the unprocessed requests back to their records
+		byID := make(map[string]*record, len(pending))
+		for _, rec := range pending {
+			byID[i.keys.id(rec.Item)] = rec
+		}
+		pending = nil
+		for _, request := range unprocessed {
+			if request.PutRequest != nil {
+				pending = append(pending, byID[i.keys.id(request.PutRequest.Item)])
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		if retry >= i.opts.MaxRetries {
+			for _, rec := range pending {
+				if errReject := i.reject(rec, errors.New(fmt.Sprintf("item still unprocessed after %d retries", retry))); errReject != nil {
+					return errReject
+				}
+			}
+			return nil
+		}
+		delay := backoff(retry)
+		i.dbmgr.Logger.Debugf("Retrying %d unprocessed item(s) of table:%s in %v", len(pending), i.opts.Table, delay)
+		select {
+		case <-i.ctx.Done():
+			return i.ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return nil
+}
+
+// openInput opens the input file, or stdin, and decompresses it if it is gzip compressed.
+// It returns the reader and a function closing the input.
+func openInput(file string) (*bufio.Reader, func() error, error) {
+	var input io.ReadCloser = os.Stdin
+	if file != "" {
+		opened, err := os.Open(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		input = opened
+	}
+
+	buffered := bufio.NewReaderSize(input, 1024*1024)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			input.Close()
+			return nil, nil, err
+		}
+		return bufio.NewReaderSize(decompressed, 1024*1024), input.Close, nil
+	}
+	return buffered, input.Close, nil
+}
+
+// ExecuteImport imports items into a table with BatchWriteItem calls of up to 25 items.
+// It takes a DynamoDBManager and the import options as input.
+// Every item is validated against the key schema of the table before it is written, the records that can't be parsed,
+// fail the validation or are still unprocessed after the retries are written to the reject file with their line and error.
+// It returns an error wrapping client.ErrPendingChanges for a dry run with valid items,
+// client.ErrPartialFailure if some records were rejected, or the error stopping the import.
+func ExecuteImport(dbmgr *client.DynamoDBManager, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	columnTypes, _ := parseTypes(opts.Types)
+
+	table, err := DescribeTableClient(dbmgr, opts.Table)
+	if err != nil {
+		return err
+	}
+
+	input, closeInput, err := openInput(opts.File)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Failed to open the input:%s - error:%v", opts.File, err))
+	}
+	defer closeInput()
+
+	var reader recordReader = &lineReader{reader: input, format: opts.Format}
+	if opts.Format == items.CSV {
+		if reader, err = newCSVReader(input, columnTypes); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	i := importer{ctx: ctx, dbmgr: dbmgr, opts: &opts, keys: newKeySchema(table), batchIDs: map[string]bool{}}
+	if opts.WriteCapacity > 0 {
+		i.limiter = client.NewTokenBucket(opts.WriteCapacity, int(opts.WriteCapacity))
+	}
+
+	dbmgr.Logger.Infof("Begin to import %s items into table:%s, ...", opts.Format, opts.Table)
+	lastLine := 0
+	for err == nil {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		var rec *record
+		if rec, err = reader.next(); err != nil {
+			break
+		}
+		lastLine = rec.Line
+		err = i.add(rec)
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if err == nil && !opts.DryRun {
+		err = i.flush()
+	}
+	if i.rejects != nil {
+		err = errors.Join(err, i.rejects.Close())
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to import into table:%s after line:%d, %d item(s) imported - %w", opts.Table, lastLine, i.imported, err)
+	}
+
+	var errs []error
+	if opts.DryRun {
+		valid := i.read - i.rejected
+		dbmgr.Logger.Infof("Dry run: %d of %d item(s) are valid for table:%s", valid, i.read, opts.Table)
+		if valid > 0 {
+			errs = append(errs, fmt.Errorf("%d item(s) would be imported into table:%s - %w", valid, opts.Table, client.ErrPendingChanges))
+		}
+	} else {
+		dbmgr.Logger.Infof("Imported %d of %d item(s) into table:%s", i.imported, i.read, opts.Table)
+	}
+	if i.rejected > 0 {
+		errs = append(errs, fmt.Errorf("%d of %d item(s) rejected, see the reject file:%s - %w", i.rejected, i.read, opts.RejectFile, client.ErrPartialFailure))
+	}
+	return errors.Join(errs...)
+}
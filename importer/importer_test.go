@@ -0,0 +1,24 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVReaderRaw(t *testing.T) {
+	input := "id,note\n1,plain\n2,\"with, comma\"\n3,\"with \"\"quotes\"\"\"\n"
+	reader, err := newCSVReader(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`1,plain`, `2,"with, comma"`, `3,"with ""quotes"""`}
+	for _, raw := range want {
+		rec, err := reader.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Raw != raw {
+			t.Errorf("next() raw = %s, want %s", rec.Raw, raw)
+		}
+	}
+}
@@ -0,0 +1,500 @@
+package items
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Item formats supported by the export and import commands
+const (
+	JSONL        string = "jsonl"    // One plain JSON object per line
+	DynamoDBJSON string = "ddb-json" // One DynamoDB typed JSON object per line, e.g. {"id":{"S":"a"}}
+	CSV          string = "csv"
+)
+
+// Attribute types of the CSV cells, the counterpart of FormatCell
+const (
+	TypeString string = "S"
+	TypeNumber string = "N"
+	TypeBinary string = "B"    // Base64 encoded
+	TypeBool   string = "BOOL" // true or false
+	TypeNull   string = "NULL"
+	TypeSS     string = "SS"   // JSON array of strings
+	TypeNS     string = "NS"   // JSON array of numbers
+	TypeBS     string = "BS"   // JSON array of base64 encoded strings
+	TypeList   string = "L"    // JSON array
+	TypeMap    string = "M"    // JSON object
+	TypeJSON   string = "JSON" // Any plain JSON value
+)
+
+// Item is a DynamoDB item
+type Item = map[string]types.AttributeValue
+
+// ValidateFormat checks that the format is one of the supported item formats.
+// It returns an error if the format is not recognized.
+func ValidateFormat(format string) error {
+	switch format {
+	case JSONL, DynamoDBJSON, CSV:
+		return nil
+	}
+	return errors.New(fmt.Sprintf("unrecognized item format provided:%s", format))
+}
+
+// ToPlain converts an attribute value into a plain JSON value.
+// Numbers are kept as json.Number to preserve their precision, binary values are base64 encoded and sets become arrays.
+func ToPlain(value types.AttributeValue) interface{} {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value)
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberNULL:
+		return nil
+	case *types.AttributeValueMemberSS:
+		return v.Value
+	case *types.AttributeValueMemberNS:
+		numbers := make([]json.Number, 0, len(v.Value))
+		for _, n := range v.Value {
+			numbers = append(numbers, json.Number(n))
+		}
+		return numbers
+	case *types.AttributeValueMemberBS:
+		encoded := make([]string, 0, len(v.Value))
+		for _, b := range v.Value {
+			encoded = append(encoded, base64.StdEncoding.EncodeToString(b))
+		}
+		return encoded
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(v.Value))
+		for _, element := range v.Value {
+			list = append(list, ToPlain(element))
+		}
+		return list
+	case *types.AttributeValueMemberM:
+		return ToPlainItem(v.Value)
+	}
+	return nil
+}
+
+// ToPlainItem converts an item into a plain JSON object.
+func ToPlainItem(item Item) map[string]interface{} {
+	plain := make(map[string]interface{}, len(item))
+	for name, value := range item {
+		plain[name] = ToPlain(value)
+	}
+	return plain
+}
+
+// FromPlain converts a plain JSON value, decoded with json.Decoder.UseNumber, into an attribute value.
+// Strings become S, numbers N, booleans BOOL, null NULL, arrays L and objects M.
+// It returns an error for any other type.
+func FromPlain(value interface{}) (types.AttributeValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case string:
+		return &types.AttributeValueMemberS{Value: v}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: v.String()}, nil
+	case float64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case int:
+		return &types.AttributeValueMemberN{Value: fmt.Sprint(v)}, nil
+	case int64:
+		return &types.AttributeValueMemberN{Value: fmt.Sprint(v)}, nil
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: v}, nil
+	case []interface{}:
+		list := make([]types.AttributeValue, 0, len(v))
+		for _, element := range v {
+			converted, err := FromPlain(element)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case map[string]interface{}:
+		item, err := FromPlainItem(v)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: item}, nil
+	}
+	return nil, errors.New(fmt.Sprintf("unsupported plain JSON value type:%T", value))
+}
+
+// FromPlainItem converts a plain JSON object into an item.
+// It returns an error if any of its values can't be converted.
+func FromPlainItem(plain map[string]interface{}) (Item, error) {
+	item := make(Item, len(plain))
+	for name, value := range plain {
+		converted, err := FromPlain(value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("attribute:%s - %v", name, err))
+		}
+		item[name] = converted
+	}
+	return item, nil
+}
+
+// ToDynamoDB converts an attribute value into its DynamoDB typed JSON representation, e.g. {"S":"a"}.
+func ToDynamoDB(value types.AttributeValue) map[string]interface{} {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]interface{}{"S": v.Value}
+	case *types.AttributeValueMemberN:
+		return map[string]interface{}{"N": v.Value}
+	case *types.AttributeValueMemberB:
+		return map[string]interface{}{"B": base64.StdEncoding.EncodeToString(v.Value)}
+	case *types.AttributeValueMemberBOOL:
+		return map[string]interface{}{"BOOL": v.Value}
+	case *types.AttributeValueMemberNULL:
+		return map[string]interface{}{"NULL": true}
+	case *types.AttributeValueMemberSS:
+		return map[string]interface{}{"SS": v.Value}
+	case *types.AttributeValueMemberNS:
+		return map[string]interface{}{"NS": v.Value}
+	case *types.AttributeValueMemberBS:
+		encoded := make([]string, 0, len(v.Value))
+		for _, b := range v.Value {
+			encoded = append(encoded, base64.StdEncoding.EncodeToString(b))
+		}
+		return map[string]interface{}{"BS": encoded}
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(v.Value))
+		for _, element := range v.Value {
+			list = append(list, ToDynamoDB(element))
+		}
+		return map[string]interface{}{"L": list}
+	case *types.AttributeValueMemberM:
+		return map[string]interface{}{"M": ToDynamoDBItem(v.Value)}
+	}
+	return nil
+}
+
+// ToDynamoDBItem converts an item into its DynamoDB typed JSON representation.
+func ToDynamoDBItem(item Item) map[string]interface{} {
+	typed := make(map[string]interface{}, len(item))
+	for name, value := range item {
+		typed[name] = ToDynamoDB(value)
+	}
+	return typed
+}
+
+// dynamoDBValue is the DynamoDB typed JSON representation of an attribute value.
+type dynamoDBValue struct {
+	S    *string                    `json:"S"`
+	N    *string                    `json:"N"`
+	B    *string                    `json:"B"`
+	BOOL *bool                      `json:"BOOL"`
+	NULL *bool                      `json:"NULL"`
+	SS   []string                   `json:"SS"`
+	NS   []string                   `json:"NS"`
+	BS   []string                   `json:"BS"`
+	L    []json.RawMessage          `json:"L"`
+	M    map[string]json.RawMessage `json:"M"`
+}
+
+// FromDynamoDB converts the DynamoDB typed JSON representation of an attribute value into an attribute value.
+// It returns an error if the representation is malformed.
+func FromDynamoDB(raw json.RawMessage) (types.AttributeValue, error) {
+	var typed dynamoDBValue
+	if err := json.Unmarshal(raw, &typed); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case typed.S != nil:
+		return &types.AttributeValueMemberS{Value: *typed.S}, nil
+	case typed.N != nil:
+		return &types.AttributeValueMemberN{Value: *typed.N}, nil
+	case typed.B != nil:
+		decoded, err := base64.StdEncoding.DecodeString(*typed.B)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberB{Value: decoded}, nil
+	case typed.BOOL != nil:
+		return &types.AttributeValueMemberBOOL{Value: *typed.BOOL}, nil
+	case typed.NULL != nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case typed.SS != nil:
+		return &types.AttributeValueMemberSS{Value: typed.SS}, nil
+	case typed.NS != nil:
+		return &types.AttributeValueMemberNS{Value: typed.NS}, nil
+	case typed.BS != nil:
+		decoded := make([][]byte, 0, len(typed.BS))
+		for _, encoded := range typed.BS {
+			b, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, err
+			}
+			decoded = append(decoded, b)
+		}
+		return &types.AttributeValueMemberBS{Value: decoded}, nil
+	case typed.L != nil:
+		list := make([]types.AttributeValue, 0, len(typed.L))
+		for _, element := range typed.L {
+			converted, err := FromDynamoDB(element)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case typed.M != nil:
+		item, err := FromDynamoDBItem(typed.M)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: item}, nil
+	}
+	return nil, errors.New(fmt.Sprintf("unrecognized DynamoDB JSON value:%s", string(raw)))
+}
+
+// FromDynamoDBItem converts the DynamoDB typed JSON representation of an item into an item.
+// It returns an error if any of its values is malformed.
+func FromDynamoDBItem(typed map[string]json.RawMessage) (Item, error) {
+	item := make(Item, len(typed))
+	for name, raw := range typed {
+		converted, err := FromDynamoDB(raw)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("attribute:%s - %v", name, err))
+		}
+		item[name] = converted
+	}
+	return item, nil
+}
+
+// Marshal encodes an item as a single JSON line in the given format, jsonl or ddb-json.
+func Marshal(item Item, format string) ([]byte, error) {
+	if format == DynamoDBJSON {
+		return json.Marshal(ToDynamoDBItem(item))
+	}
+	return json.Marshal(ToPlainItem(item))
+}
+
+// Unmarshal decodes a JSON line in the given format, jsonl or ddb-json, into an item.
+// It returns an error if the line is malformed.
+func Unmarshal(line []byte, format string) (Item, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	if format == DynamoDBJSON {
+		var typed map[string]json.RawMessage
+		if err := decoder.Decode(&typed); err != nil {
+			return nil, err
+		}
+		return FromDynamoDBItem(typed)
+	}
+
+	var plain map[string]interface{}
+	if err := decoder.Decode(&plain); err != nil {
+		return nil, err
+	}
+	return FromPlainItem(plain)
+}
+
+// FormatCell formats an attribute value as a CSV cell: scalars as text, sets, lists and maps as plain JSON.
+// A missing attribute is formatted as an empty cell.
+func FormatCell(value types.AttributeValue) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return v.Value
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(v.Value)
+	case *types.AttributeValueMemberNULL:
+		return ""
+	}
+	encoded, err := json.Marshal(ToPlain(value))
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// ParseCell parses a CSV cell as an attribute value of the given type, the counterpart of FormatCell.
+// It returns an error if the cell doesn't hold a value of that type.
+func ParseCell(cell string, attributeType string) (types.AttributeValue, error) {
+	switch attributeType {
+	case TypeString:
+		return &types.AttributeValueMemberS{Value: cell}, nil
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(cell, 64); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid number:%s", cell))
+		}
+		return &types.AttributeValueMemberN{Value: cell}, nil
+	case TypeBinary:
+		decoded, err := base64.StdEncoding.DecodeString(cell)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid base64 value:%s", cell))
+		}
+		return &types.AttributeValueMemberB{Value: decoded}, nil
+	case TypeBool:
+		value, err := strconv.ParseBool(cell)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid boolean:%s", cell))
+		}
+		return &types.AttributeValueMemberBOOL{Value: value}, nil
+	case TypeNull:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(cell)))
+	decoder.UseNumber()
+	var plain interface{}
+	if err := decoder.Decode(&plain); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid JSON value:%s", cell))
+	}
+
+	switch attributeType {
+	case TypeJSON:
+		return FromPlain(plain)
+	case TypeList:
+		if _, ok := plain.([]interface{}); ok {
+			return FromPlain(plain)
+		}
+	case TypeMap:
+		if _, ok := plain.(map[string]interface{}); ok {
+			return FromPlain(plain)
+		}
+	case TypeSS, TypeNS, TypeBS:
+		elements, ok := plain.([]interface{})
+		if !ok {
+			break
+		}
+		values := make([]string, 0, len(elements))
+		for _, element := range elements {
+			if attributeType == TypeNS {
+				number, ok := element.(json.Number)
+				if !ok {
+					return nil, errors.New(fmt.Sprintf("invalid number set:%s", cell))
+				}
+				values = append(values, number.String())
+				continue
+			}
+			value, ok := element.(string)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("invalid %s set:%s", attributeType, cell))
+			}
+			values = append(values, value)
+		}
+		switch attributeType {
+		case TypeSS:
+			return &types.AttributeValueMemberSS{Value: values}, nil
+		case TypeNS:
+			return &types.AttributeValueMemberNS{Value: values}, nil
+		}
+		decoded := make([][]byte, 0, len(values))
+		for _, value := range values {
+			b, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("invalid base64 value:%s", value))
+			}
+			decoded = append(decoded, b)
+		}
+		return &types.AttributeValueMemberBS{Value: decoded}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unrecognized attribute type provided:%s", attributeType))
+	}
+	return nil, errors.New(fmt.Sprintf("invalid %s value:%s", attributeType, cell))
+}
+
+// ValidateType checks that the attribute type is one of the types supported by ParseCell.
+// It returns an error if the type is not recognized.
+func ValidateType(attributeType string) error {
+	switch attributeType {
+	case TypeString, TypeNumber, TypeBinary, TypeBool, TypeNull, TypeSS, TypeNS, TypeBS, TypeList, TypeMap, TypeJSON:
+		return nil
+	}
+	return errors.New(fmt.Sprintf("unrecognized attribute type provided:%s", attributeType))
+}
+
+// AttributeNames returns the sorted union of the attribute names of the items.
+func AttributeNames(items []Item) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, item := range items {
+		for name := range item {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Key extracts the attributes of the key schema from an item.
+func Key(item Item, keyNames []string) Item {
+	key := make(Item, len(keyNames))
+	for _, name := range keyNames {
+		if value, exists := item[name]; exists {
+			key[name] = value
+		}
+	}
+	return key
+}
+
+// canonical converts an attribute value into its DynamoDB typed JSON representation with sorted sets,
+// DynamoDB doesn't keep the order of the elements of a set.
+func canonical(value types.AttributeValue) interface{} {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberSS, *types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+		typed := ToDynamoDB(value)
+		for setType, elements := range typed {
+			sorted := append([]string(nil), elements.([]string)...)
+			sort.Strings(sorted)
+			typed[setType] = sorted
+		}
+		return typed
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(v.Value))
+		for _, element := range v.Value {
+			list = append(list, canonical(element))
+		}
+		return map[string]interface{}{"L": list}
+	case *types.AttributeValueMemberM:
+		return map[string]interface{}{"M": canonicalItem(v.Value)}
+	}
+	return ToDynamoDB(value)
+}
+
+// canonicalItem converts an item into its canonical DynamoDB typed JSON representation.
+func canonicalItem(item Item) map[string]interface{} {
+	typed := make(map[string]interface{}, len(item))
+	for name, value := range item {
+		typed[name] = canonical(value)
+	}
+	return typed
+}
+
+// Checksum returns a hash of the item that doesn't depend on the order of its attributes or of the elements of its sets.
+// The checksums of the items of a table can be summed up to compare tables regardless of the scan order.
+func Checksum(item Item) uint64 {
+	// The keys of the JSON objects are sorted by the encoder
+	encoded, _ := json.Marshal(canonicalItem(item))
+	sum := sha256.Sum256(encoded)
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -103,7 +103,7 @@ func (l Logger) Errorz(msg string, fields ...zap.Field) {
 // Debug wraps Sugar Debugf
 func (l Logger) Debugf(msg string, args ...interface{}) {
 	sanitized := l.sanitize(msg)
-	l.writer().Sugar().Debugf(sanitized, args)
+	l.writer().Sugar().Debugf(sanitized, args...)
 }
 
 // Info wraps Sugar Infof
@@ -115,13 +115,13 @@ func (l Logger) Infof(msg string, args ...interface{}) {
 // Warn wraps Sugar Warnf
 func (l Logger) Warnf(msg string, args ...interface{}) {
 	sanitized := l.sanitize(msg)
-	l.writer().Sugar().Warnf(sanitized, args)
+	l.writer().Sugar().Warnf(sanitized, args...)
 }
 
 // Error wraps Sugar Errorf
 func (l Logger) Errorf(msg string, args ...interface{}) {
 	sanitized := l.sanitize(msg)
-	l.writer().Sugar().Errorf(sanitized, args)
+	l.writer().Sugar().Errorf(sanitized, args...)
 }
 
 // Debug wraps Sugar Debug
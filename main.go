@@ -5,31 +5,112 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/compare"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/manifest"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
 	"github.com/ForrestIsARealGoodman/dynamodb-manager/search"
 	"github.com/ForrestIsARealGoodman/dynamodb-manager/update"
 )
 
+// Exit codes of the program:
+//
+//	0   success
+//	1   generic failure
+//	2   usage error, the command line arguments or the config file are invalid
+//	3   no matches, the search conditions didn't match any table
+//	4   partial failure, some of the tables couldn't be processed
+//	5   policy violation
+//	6   dry run with pending changes
+//	10+ specific failures reported by the client, update and compare packages
+const (
+	ExitSuccess         = 0
+	ExitFailure         = 1
+	ExitUsage           = 2
+	ExitNoMatches       = 3
+	ExitPartialFailure  = 4
+	ExitPolicyViolation = 5
+	ExitPendingChanges  = 6
+)
+
+// Exit codes returned for the errors of the client, update and compare packages
+const (
+	ExitTableNotFound     = 10
+	ExitThrottled         = 11
+	ExitResourceInUse     = 12
+	ExitModeSwitchTooSoon = 13
+	ExitAccessDenied      = 14
+	ExitInvalidModeSwitch = 15
+	ExitDifferences       = 16
+	ExitLimitExceeded     = 17
+)
+
 // Actions the program can take
 const (
-	Search string = "search"
-	Update string = "update"
+	Search    string = "search"
+	Update    string = "update"
+	Describe  string = "describe"
+	Tag       string = "tag"
+	AuditTags string = "audit-tags"
+	Export    string = "export"
+	Import    string = "import"
+	Get       string = "get"
+	Query     string = "query"
+	Scan      string = "scan"
+	SQL       string = "sql"
+	Copy      string = "copy"
+
+	BackupCreate  string = "backup-create"
+	BackupList    string = "backup-list"
+	BackupRestore string = "backup-restore"
+	BackupDelete  string = "backup-delete"
+	BackupPrune   string = "backup-prune"
+
+	PitrStatus  string = "pitr-status"
+	PitrEnable  string = "pitr-enable"
+	PitrDisable string = "pitr-disable"
+	PitrRestore string = "pitr-restore"
+
+	SchemaExport string = "schema-export"
+	Create       string = "create"
+	Delete       string = "delete"
+
+	IndexStatus string = "index-status"
+	IndexCreate string = "index-create"
+	IndexDelete string = "index-delete"
+
+	Compare     string = "compare"
+	CompareEnvs string = "compare-envs"
+
+	Recommend string = "recommend"
+	Cost      string = "cost"
+	BreakEven string = "breakeven"
+	Manifest  string = "manifest"
 )
 
 var ExecuteSearchTask = search.ExecuteSearch
 var ExecuteUpdateTask = update.ExecuteUpdate
+var ExecuteUpdateSettingsTask = update.ExecuteUpdateSettings
+var ExecuteApplyManifestTask = manifest.ExecuteApply
 
 var searchTerm string
 var tagValue string
 var updateTable string
+var manifestFile string
 var rcuValueStr string
 var wcuValueStr string
 var provisioned bool
 var onDemand bool
+var settings update.Settings
+var dryRun bool
+
+// action is the workflow selected by the command line, it stays empty if only the help was requested
+var action string
 
 var usageStr string = `./dynamodb-manager --search table_name [--profile profile_name] [--level (Debug, Info, Warn, Error)]
 ./dynamodb-manager --tag tag_value [--profile profile_name] [--level (Debug, Info, Warn, Error)]
@@ -37,42 +118,103 @@ var usageStr string = `./dynamodb-manager --search table_name [--profile profile
 ./dynamodb-manager --update table_name --rcu rcu_value --wcu wcu_value [--profile profile_name] [--level (Debug, Info, Warn, Error)]
 ./dynamodb-manager --update table_name --provisioned [--profile profile_name] [--level (Debug, Info, Warn, Error)]
 ./dynamodb-manager --update table_name --ondemand [--profile profile_name] [--level (Debug, Info, Warn, Error)]
-./dynamodb-manager --update table_name --provisioned --rcu rcu_value --wcu wcu_value [--profile profile_name] [--level (Debug, Info, Warn, Error)]`
+./dynamodb-manager --update table_name --provisioned --rcu rcu_value --wcu wcu_value [--profile profile_name] [--level (Debug, Info, Warn, Error)]
+./dynamodb-manager --update table_name [--ttl attribute|off] [--stream view_type|off] [--table-class class] [--deletion-protection on|off] [--sse owned|kms [--kms-key key]] [--profile profile_name] [--level (Debug, Info, Warn, Error)]
+./dynamodb-manager --update table_name ... --dry-run [--price-file file] [--profile profile_name] [--level (Debug, Info, Warn, Error)]
+./dynamodb-manager --manifest file [--dry-run] [--yes] [--profile profile_name] [--level (Debug, Info, Warn, Error)]`
+
+var exitCodesStr string = `Exit codes:
+  0   success
+  1   generic failure
+  2   usage error
+  3   no matches
+  4   partial failure
+  5   policy violation
+  6   dry run with pending changes
+  10  table not found
+  11  throttled
+  12  resource in use
+  13  billing mode switched too recently
+  14  access denied
+  15  invalid billing mode switch
+  16  compared tables differ
+  17  account or table limit exceeded
+
+When several outcomes apply, the first of 4, 5, 6 and 3 is returned, e.g. a dry run
+with pending changes and a partial failure exits with 4.`
 
 var rootCmd = &cobra.Command{
 	Use:   usageStr,
 	Short: "Manage DynamoDB tables with fuzzy search and update capabilities",
-	Long:  "Manage DynamoDB tables with fuzzy search and update capabilities",
+	Long:  "Manage DynamoDB tables with fuzzy search and update capabilities\n\n" + exitCodesStr,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfigFile(); err != nil {
+			return err
+		}
+		return output.Validate(viper.GetString("output"))
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		searchTerm = viper.GetString("search")
 		tagValue = viper.GetString("tag")
 		updateTable = viper.GetString("update")
+		manifestFile = viper.GetString("manifest")
 		rcuValueStr = viper.GetString("rcu")
 		wcuValueStr = viper.GetString("wcu")
 		provisioned = viper.GetBool("provisioned")
 		onDemand = viper.GetBool("ondemand")
+		settings = update.Settings{
+			TimeToLive:         viper.GetString("ttl"),
+			Stream:             viper.GetString("stream"),
+			TableClass:         viper.GetString("table-class"),
+			DeletionProtection: viper.GetString("deletion-protection"),
+			Encryption:         viper.GetString("sse"),
+			KMSKey:             viper.GetString("kms-key"),
+		}
+		dryRun = viper.GetBool("dry-run")
 
-		return checkCommand()
+		if err := checkCommand(); err != nil {
+			return err
+		}
+		if updateTable != "" {
+			action = Update
+		} else if manifestFile != "" {
+			action = Manifest
+		} else {
+			action = Search
+		}
+		return nil
 	},
 }
 
 // checkCommand checks the validity of the command line arguments.
 // It returns an error if the arguments are not valid.
 func checkCommand() error {
-	if updateTable == "" && searchTerm == "" && tagValue == "" {
-		return errors.New("any of search or tag or update param must be provided!")
+	if updateTable == "" && manifestFile == "" && searchTerm == "" && tagValue == "" {
+		return errors.New("any of search or tag or update or manifest param must be provided!")
+	}
+
+	if manifestFile != "" && (updateTable != "" || searchTerm != "" || tagValue != "" || hasCapacityChange() || !settings.IsEmpty()) {
+		return errors.New("Invalid command line arguments: manifest can't be used together with update, search, tag or any update param!")
 	}
 
 	if (searchTerm != "" || tagValue != "") && (rcuValueStr != "" || wcuValueStr != "" || provisioned || onDemand) {
 		return errors.New("Invalid command line arguments: search or tag cannot be used together with rcu, wcu, provisioned, ondemand!")
 	}
 
+	if (searchTerm != "" || tagValue != "") && !settings.IsEmpty() {
+		return errors.New("Invalid command line arguments: search or tag cannot be used together with ttl, stream, table-class, deletion-protection, sse, kms-key!")
+	}
+
 	if updateTable != "" && (searchTerm != "" || tagValue != "") {
 		return errors.New("Invalid command line arguments: update can't be used together with search or tag!")
 	}
 
-	if updateTable != "" && rcuValueStr == "" && wcuValueStr == "" && !provisioned && !onDemand {
-		return errors.New("Invalid command line arguments: no rcu or wcu or provisioned or onDemand is provided!")
+	if updateTable != "" && !hasCapacityChange() && settings.IsEmpty() {
+		return errors.New("Invalid command line arguments: no rcu or wcu or provisioned or onDemand or table setting is provided!")
+	}
+
+	if dryRun && updateTable == "" && manifestFile == "" {
+		return errors.New("Invalid command line arguments: dry-run can only be used together with update or manifest!")
 	}
 
 	if updateTable != "" && onDemand && (rcuValueStr != "" || wcuValueStr != "") {
@@ -92,19 +234,110 @@ func checkCommand() error {
 			return errors.New(fmt.Sprintf("Invalid command line arguments: wcuValue:%s - error:%v", wcuValueStr, err))
 		}
 	}
+
+	if err := settings.Validate(); err != nil {
+		return errors.New(fmt.Sprintf("Invalid command line arguments: %v", err))
+	}
+
+	if _, err := managerOptions(); err != nil {
+		return errors.New(fmt.Sprintf("Invalid command line arguments: %v", err))
+	}
 	return nil
 }
 
+// hasCapacityChange reports whether the capacity or the billing mode of the table is to be updated.
+func hasCapacityChange() bool {
+	return rcuValueStr != "" || wcuValueStr != "" || provisioned || onDemand
+}
+
+// loadConfigFile reads the config file passed through --config, if any, so its values back the command line flags.
+// It returns an error if the file can't be read.
+func loadConfigFile() error {
+	configFile := viper.GetString("config")
+	if configFile == "" {
+		return nil
+	}
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return errors.New(fmt.Sprintf("Failed to read config file:%s - error:%v", configFile, err))
+	}
+	return nil
+}
+
+// managerOptions builds the options of the DynamoDB manager from the credential, region and retry flags.
+// It returns an error if any of the values is invalid.
+func managerOptions() (client.ManagerOptions, error) {
+	opts := client.ManagerOptions{
+		Profile:          viper.GetString("profile"),
+		Region:           viper.GetString("region"),
+		Endpoint:         viper.GetString("endpoint"),
+		RoleArn:          viper.GetString("role-arn"),
+		ExternalID:       viper.GetString("external-id"),
+		MFASerial:        viper.GetString("mfa-serial"),
+		SessionName:      viper.GetString("session-name"),
+		CacheCredentials: viper.GetBool("credential-cache"),
+	}
+
+	if opts.RoleArn == "" && (opts.ExternalID != "" || opts.MFASerial != "" || opts.SessionName != "") {
+		return opts, errors.New("external-id, mfa-serial and session-name can only be used together with role-arn!")
+	}
+
+	retryCfg, err := retryConfig()
+	if err != nil {
+		return opts, err
+	}
+	opts.Retry = retryCfg
+
+	roleDuration := viper.GetString("role-duration")
+	if roleDuration != "" {
+		if opts.RoleArn == "" {
+			return opts, errors.New("role-duration can only be used together with role-arn!")
+		}
+		if opts.RoleDuration, err = time.ParseDuration(roleDuration); err != nil {
+			return opts, errors.New(fmt.Sprintf("roleDuration:%s - error:%v", roleDuration, err))
+		}
+	}
+	return opts, nil
+}
+
+// retryConfig builds the client retry configuration from the retry and rate limit flags.
+// It returns an error if any of the values is invalid.
+func retryConfig() (client.RetryConfig, error) {
+	retryCfg := client.DefaultRetryConfig()
+	retryCfg.MaxAttempts = viper.GetInt("max-attempts")
+	retryCfg.Mode = viper.GetString("retry-mode")
+	retryCfg.RateBurst = viper.GetInt("rate-burst")
+
+	maxBackoff, err := time.ParseDuration(viper.GetString("max-backoff"))
+	if err != nil {
+		return retryCfg, errors.New(fmt.Sprintf("maxBackoff:%s - error:%v", viper.GetString("max-backoff"), err))
+	}
+	retryCfg.MaxBackoff = maxBackoff
+
+	retryCfg.RateLimits, err = client.ParseRateLimits(viper.GetString("rate-limit"))
+	if err != nil {
+		return retryCfg, err
+	}
+	return retryCfg, retryCfg.Validate()
+}
+
 // dumpParams logs the passed arguments to the logger in debug mode.
 func dumpParams(dbmgr *client.DynamoDBManager) {
 	dbmgr.Logger.Debugf("Debug info - passed args listed here:")
 	dbmgr.Logger.Debugf("Search Term: %s\n", searchTerm)
 	dbmgr.Logger.Debugf("Tag Value: %s\n", tagValue)
 	dbmgr.Logger.Debugf("Update Table: %s\n", updateTable)
+	dbmgr.Logger.Debugf("Manifest File: %s\n", manifestFile)
 	dbmgr.Logger.Debugf("RCU Value: %s\n", rcuValueStr)
 	dbmgr.Logger.Debugf("WCU Value: %s\n", wcuValueStr)
 	dbmgr.Logger.Debugf("Provisioned: %t\n", provisioned)
 	dbmgr.Logger.Debugf("On-Demand: %t\n", onDemand)
+	dbmgr.Logger.Debugf("Settings: %+v\n", settings)
+	dbmgr.Logger.Debugf("Dry Run: %t\n", dryRun)
+	dbmgr.Logger.Debugf("Profile: %s - Region: %s - Endpoint: %s\n", viper.GetString("profile"), viper.GetString("region"), viper.GetString("endpoint"))
+	dbmgr.Logger.Debugf("Role Arn: %s - Session Name: %s - MFA Serial: %s\n", viper.GetString("role-arn"), viper.GetString("session-name"), viper.GetString("mfa-serial"))
+	dbmgr.Logger.Debugf("Retry Mode: %s - Max Attempts: %d - Max Backoff: %s\n", viper.GetString("retry-mode"), viper.GetInt("max-attempts"), viper.GetString("max-backoff"))
+	dbmgr.Logger.Debugf("Rate Limit: %s - Burst: %d\n", viper.GetString("rate-limit"), viper.GetInt("rate-burst"))
 }
 
 // initCommand initializes the command-line flags, parses them, and binds them to viper.
@@ -114,13 +347,57 @@ func initCommand() error {
 	rootCmd.PersistentFlags().StringP("search", "", "", "Search term for DynamoDB table names")
 	rootCmd.PersistentFlags().StringP("tag", "", "", "Value of the tag for DynamoDB table search")
 	rootCmd.PersistentFlags().StringP("update", "", "", "Name of the DynamoDB table to update")
+	rootCmd.PersistentFlags().StringP("manifest", "", "", "Manifest file of billing mode and capacity updates to apply, e.g. saved by breakeven")
 	rootCmd.PersistentFlags().StringP("rcu", "", "", "Read Capacity Units")
 	rootCmd.PersistentFlags().StringP("wcu", "", "", "Write Capacity Units")
 	rootCmd.PersistentFlags().Bool("provisioned", false, "Provisioned capacity mode")
 	rootCmd.PersistentFlags().Bool("ondemand", false, "On-Demand capacity mode")
+	rootCmd.PersistentFlags().StringP("ttl", "", "", "Attribute holding the expiry time of the items, or off to disable the time to live")
+	rootCmd.PersistentFlags().StringP("stream", "", "", "Stream view type (NEW_IMAGE, OLD_IMAGE, NEW_AND_OLD_IMAGES, KEYS_ONLY), or off to disable the stream")
+	rootCmd.PersistentFlags().StringP("table-class", "", "", "Table class (STANDARD, STANDARD_INFREQUENT_ACCESS)")
+	rootCmd.PersistentFlags().StringP("deletion-protection", "", "", "Deletion protection (on, off)")
+	rootCmd.PersistentFlags().StringP("sse", "", "", "Server-side encryption (owned, kms)")
+	rootCmd.PersistentFlags().StringP("kms-key", "", "", "KMS key ID, ARN or alias of the kms encryption (default the AWS managed key)")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Show the pending changes without applying them")
+	rootCmd.PersistentFlags().StringP("price-file", "", "", "JSON price table of DynamoDB by region and table class (default the embedded list prices)")
+	rootCmd.PersistentFlags().Bool("yes", false, "Apply the changes without asking for confirmation")
+	rootCmd.PersistentFlags().StringP("config", "", "", "Config file providing values for any of the flags")
+	rootCmd.PersistentFlags().StringP("output", "", output.Text, "Output format of the results (text, json)")
+	rootCmd.PersistentFlags().StringP("profile", "", "", "AWS shared config profile")
+	rootCmd.PersistentFlags().StringP("region", "", "", "AWS region, overrides the region of the profile")
+	rootCmd.PersistentFlags().StringP("endpoint", "", "", "DynamoDB endpoint URL, e.g. of DynamoDB Local")
+	rootCmd.PersistentFlags().StringP("role-arn", "", "", "ARN of the role to assume")
+	rootCmd.PersistentFlags().StringP("external-id", "", "", "External ID used to assume the role")
+	rootCmd.PersistentFlags().StringP("mfa-serial", "", "", "Serial number or ARN of the MFA device, the token code is prompted for")
+	rootCmd.PersistentFlags().StringP("session-name", "", "", "Session name of the assumed role (default \"dynamodb-manager\")")
+	rootCmd.PersistentFlags().StringP("role-duration", "", "", "Duration of the assumed role session, up to the maximum session duration of the role (default \"1h\")")
+	rootCmd.PersistentFlags().Bool("credential-cache", true, "Cache the temporary credentials between invocations")
+	rootCmd.PersistentFlags().Int("max-attempts", client.DefaultMaxAttempts, "Maximum attempts per AWS request, including the first one")
+	rootCmd.PersistentFlags().StringP("max-backoff", "", client.DefaultMaxBackoff.String(), "Maximum backoff delay between retries")
+	rootCmd.PersistentFlags().StringP("retry-mode", "", client.RetryModeStandard, "Retry mode (standard, adaptive)")
+	rootCmd.PersistentFlags().StringP("rate-limit", "", "", "Client-side requests per second, per operation (e.g. DescribeTable=5,ListTagsOfResource=2) or for all operations")
+	rootCmd.PersistentFlags().Int("rate-burst", client.DefaultRateBurst, "Requests allowed in a burst by each rate limit")
 
 	viper.BindPFlags(rootCmd.PersistentFlags())
 
+	initDescribeCommand()
+	initTagCommand()
+	initAuditCommand()
+	initExportCommand()
+	initImportCommand()
+	initReadCommands()
+	initSQLCommand()
+	initCopyCommand()
+	initBackupCommand()
+	initPitrCommand()
+	initSchemaCommand()
+	initCreateCommand()
+	initDeleteCommand()
+	initIndexCommand()
+	initCompareCommand()
+	initRecommendCommand()
+	initCostCommand()
+
 	cobra.EnableCommandSorting = false
 	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
 		return errors.New(fmt.Sprintf("Failed to parse command line args:%v", err))
@@ -135,57 +412,162 @@ func initCommand() error {
 // run configures and executes the program's workflow based on the specified action.
 //
 // It takes a DynamoDB manager, 'dbmgr', and an action string as parameters.
-// The action string determines the specific workflow to be executed, either 'Search' or 'Update'.
+// The action string determines the specific workflow to be executed: 'Search', 'Update' or one of the commands, e.g. 'Describe'.
 //
 // If the action is 'Search', it calls ExecuteSearchTask with the search term and tag retrieved from command-line flags.
 // If the action is 'Update', it calls ExecuteUpdateTask with the update table name, read and write capacity units,
-// on-demand and provisioned flags retrieved from command-line flags.
+// on-demand, provisioned and dry-run flags retrieved from command-line flags, then ExecuteUpdateSettingsTask with the table settings,
+// the estimated monthly cost of the table before and after the update being logged for a dry run.
+// If the action is 'Manifest', it calls ExecuteApplyManifestTask with the manifest file, dry-run and confirmation flags.
 //
-// Returns an error if the action is unrecognized or if there's an error during execution.
+// Returns an error if the action is unrecognized or if there's an error during execution,
+// client.ErrNoMatches is returned if the search didn't match any table.
 func run(dbmgr *client.DynamoDBManager, action string) error {
 	switch action {
 	case Search:
-		ExecuteSearchTask(dbmgr, viper.GetString("search"), viper.GetString("tag"))
+		matchingTables, err := ExecuteSearchTask(dbmgr, viper.GetString("search"), viper.GetString("tag"))
+		if err == nil && len(matchingTables) == 0 {
+			return client.ErrNoMatches
+		}
+		return err
 	case Update:
-		ExecuteUpdateTask(dbmgr, viper.GetString("update"), viper.GetString("rcu"), viper.GetString("wcu"), viper.GetBool("ondemand"), viper.GetBool("provisioned"))
+		var err error
+		if hasCapacityChange() {
+			err = ExecuteUpdateTask(dbmgr, viper.GetString("update"), viper.GetString("rcu"), viper.GetString("wcu"), viper.GetBool("ondemand"), viper.GetBool("provisioned"), viper.GetBool("dry-run"))
+		}
+		// The settings are only updated once the capacity is, or would be for a dry run
+		if !settings.IsEmpty() && (err == nil || errors.Is(err, client.ErrPendingChanges)) {
+			err = errors.Join(err, ExecuteUpdateSettingsTask(dbmgr, viper.GetString("update"), settings, viper.GetBool("dry-run")))
+		}
+		// The estimation of the cost only informs the dry run, it doesn't affect its outcome
+		if errors.Is(err, client.ErrPendingChanges) && (hasCapacityChange() || settings.TableClass != "") {
+			if errCost := estimateUpdateCost(dbmgr); errCost != nil {
+				dbmgr.Logger.Warnf("The monthly cost of table:%s can't be estimated - %v", viper.GetString("update"), errCost)
+			}
+		}
+		return err
+	case Describe:
+		return ExecuteDescribeTask(dbmgr, describeTable, viper.GetString("output"))
+	case Tag:
+		return runTag(dbmgr)
+	case AuditTags:
+		return runAuditTags(dbmgr)
+	case Export:
+		return runExport(dbmgr)
+	case Import:
+		return runImport(dbmgr)
+	case Get, Query, Scan:
+		return runRead(dbmgr, action)
+	case SQL:
+		return runSQL(dbmgr)
+	case Copy:
+		return runCopy(dbmgr)
+	case BackupCreate, BackupList, BackupRestore, BackupDelete, BackupPrune:
+		return runBackup(dbmgr, action)
+	case PitrStatus, PitrEnable, PitrDisable, PitrRestore:
+		return runPitr(dbmgr, action)
+	case IndexStatus, IndexCreate, IndexDelete:
+		return runIndex(dbmgr, action)
+	case Compare:
+		return runCompare(dbmgr)
+	case CompareEnvs:
+		return runCompareEnvs(dbmgr)
+	case Recommend:
+		return runRecommend(dbmgr)
+	case Cost:
+		return runCost(dbmgr)
+	case BreakEven:
+		return runBreakEven(dbmgr)
+	case Manifest:
+		return ExecuteApplyManifestTask(dbmgr, manifestFile, viper.GetBool("dry-run"), viper.GetBool("yes"), viper.GetString("output"))
+	case SchemaExport:
+		return runSchemaExport(dbmgr)
+	case Create:
+		return runCreate(dbmgr)
+	case Delete:
+		return runDelete(dbmgr)
 	default:
 		return errors.New(fmt.Sprintf("unrecognized action provided:%s", action))
 	}
-	return nil
 }
 
-// main invokes the program's workflow and handles errors by returning an exit status of 1.
+// exitCode maps an error returned by run to the exit status of the program.
+// Outcome errors take precedence over the specific client errors they may wrap, a partial failure over a policy violation
+// and both over pending changes, as exitCodesStr documents. Any other error exits with ExitFailure.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, client.ErrPartialFailure):
+		return ExitPartialFailure
+	case errors.Is(err, client.ErrPolicyViolation):
+		return ExitPolicyViolation
+	case errors.Is(err, client.ErrPendingChanges):
+		return ExitPendingChanges
+	case errors.Is(err, client.ErrNoMatches):
+		return ExitNoMatches
+	case errors.Is(err, client.ErrTableNotFound):
+		return ExitTableNotFound
+	case errors.Is(err, client.ErrThrottled):
+		return ExitThrottled
+	case errors.Is(err, client.ErrResourceInUse):
+		return ExitResourceInUse
+	case errors.Is(err, client.ErrModeSwitchTooSoon):
+		return ExitModeSwitchTooSoon
+	case errors.Is(err, client.ErrLimitExceeded):
+		return ExitLimitExceeded
+	case errors.Is(err, client.ErrAccessDenied):
+		return ExitAccessDenied
+	case errors.Is(err, update.ErrInvalidModeSwitch):
+		return ExitInvalidModeSwitch
+	case errors.Is(err, compare.ErrDifferences):
+		return ExitDifferences
+	}
+	return ExitFailure
+}
+
+// main invokes the program's workflow and exits with the status matching its outcome, see exitCode.
 func main() {
 	err_cmd := initCommand()
 	if err_cmd != nil {
-		fmt.Printf("%v", err_cmd)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "%v\n", err_cmd)
+		os.Exit(ExitUsage)
 	}
 
-	dbmgr, err := client.CreateNewDynamoDBManager(viper.GetString("profile"))
+	if action == "" {
+		// Only the help was requested
+		os.Exit(ExitSuccess)
+	}
+
+	opts, err := managerOptions()
 	if err != nil {
-		fmt.Printf("Failed to create DynamoDB client due to: %v", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Invalid manager options: %v\n", err)
+		os.Exit(ExitUsage)
+	}
+
+	dbmgr, err := client.CreateNewDynamoDBManager(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create DynamoDB client due to: %v\n", err)
+		os.Exit(ExitFailure)
 	}
 
 	err = client.SetupLogger(dbmgr, viper.GetString("level"))
 	if err != nil {
-		fmt.Printf("SetupLogger failed due to:%v", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "SetupLogger failed due to:%v\n", err)
+		os.Exit(ExitUsage)
 	}
 
 	dumpParams(dbmgr)
 
-	if viper.GetString("update") != "" {
-		err := run(dbmgr, "update")
-		if err != nil {
-			dbmgr.Logger.Errorf("Failed to update the dynamodb table:%s , due to: %v", viper.GetString("update"), err)
-		}
-	} else {
-		err := run(dbmgr, "search")
-		if err != nil {
-			dbmgr.Logger.Errorf("Failed to search dynamodb table due to: %v", err)
-		}
+	err = run(dbmgr, action)
+	switch {
+	case err == nil:
+	case errors.Is(err, client.ErrPendingChanges), errors.Is(err, client.ErrNoMatches), errors.Is(err, compare.ErrDifferences):
+		dbmgr.Logger.Warnf("Finished %s: %v", action, err)
+	case action == Update:
+		dbmgr.Logger.Errorf("Failed to update the dynamodb table:%s , due to: %v", viper.GetString("update"), err)
+	default:
+		dbmgr.Logger.Errorf("Failed to %s dynamodb table due to: %v", action, err)
 	}
-	os.Exit(1)
+	os.Exit(exitCode(err))
 }
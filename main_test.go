@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+func TestExitCode(t *testing.T) {
+	pending := fmt.Errorf("deletion pending for 2 table(s) - %w", client.ErrPendingChanges)
+	partial := fmt.Errorf("deletion failed for 1 of 3 table(s) - %w", client.ErrPartialFailure)
+	protected := fmt.Errorf("1 table(s) protected - %w", client.ErrPolicyViolation)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitSuccess},
+		{"generic failure", errors.New("boom"), ExitFailure},
+		{"pending changes", pending, ExitPendingChanges},
+		{"partial failure over pending changes", errors.Join(pending, partial), ExitPartialFailure},
+		{"policy violation over pending changes", errors.Join(protected, pending), ExitPolicyViolation},
+		{"partial failure over policy violation", errors.Join(protected, partial), ExitPartialFailure},
+		{"pending changes over no matches", errors.Join(client.ErrNoMatches, pending), ExitPendingChanges},
+		{"outcome over client error", fmt.Errorf("%w - %w", client.ErrThrottled, client.ErrPartialFailure), ExitPartialFailure},
+		{"client error", fmt.Errorf("describe failed - %w", client.ErrTableNotFound), ExitTableNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/update"
+)
+
+// Billing modes of a table
+const (
+	ModeProvisioned string = "PROVISIONED"
+	ModeOnDemand    string = "PAY_PER_REQUEST"
+)
+
+// Statuses of an update of the manifest
+const (
+	StatusPending   string = "pending"
+	StatusUnchanged string = "unchanged"
+	StatusUpdated   string = "updated"
+	StatusFailed           = outcome.StatusFailed
+)
+
+var ExecuteUpdateTask = update.ExecuteUpdate
+
+// Entry is the billing mode and capacity a table is to be updated to.
+type Entry struct {
+	Table       string `yaml:"table" json:"table"`
+	BillingMode string `yaml:"billingMode" json:"billingMode"`
+	Rcu         int64  `yaml:"rcu,omitempty" json:"rcu,omitempty"` // Provisioned mode only
+	Wcu         int64  `yaml:"wcu,omitempty" json:"wcu,omitempty"` // Provisioned mode only
+}
+
+// String describes the billing mode and capacity of the entry.
+func (e Entry) String() string {
+	if e.BillingMode == ModeProvisioned {
+		return fmt.Sprintf("%s %d/%d", e.BillingMode, e.Rcu, e.Wcu)
+	}
+	return e.BillingMode
+}
+
+// Manifest is a batch of billing mode and capacity updates, e.g. generated by the break-even report.
+type Manifest struct {
+	Region  string  `yaml:"region,omitempty" json:"region,omitempty"` // Region of the tables, checked before applying if set
+	Updates []Entry `yaml:"updates" json:"updates"`
+}
+
+// Validate checks that every table is updated once, to a known billing mode with the capacity it expects.
+// It returns an error joining the problems of the entries, if any.
+func (m *Manifest) Validate() error {
+	if len(m.Updates) == 0 {
+		return errors.New("the manifest has no update")
+	}
+	var errs []error
+	seen := map[string]bool{}
+	for i, entry := range m.Updates {
+		switch {
+		case entry.Table == "":
+			errs = append(errs, errors.New(fmt.Sprintf("update #%d: the table is missing", i+1)))
+		case seen[entry.Table]:
+			errs = append(errs, errors.New(fmt.Sprintf("update #%d: table:%s is updated more than once", i+1, entry.Table)))
+		case entry.BillingMode == ModeProvisioned && (entry.Rcu < 1 || entry.Wcu < 1):
+			errs = append(errs, errors.New(fmt.Sprintf("update #%d: table:%s - rcu and wcu of at least 1 are expected", i+1, entry.Table)))
+		case entry.BillingMode == ModeOnDemand && (entry.Rcu != 0 || entry.Wcu != 0):
+			errs = append(errs, errors.New(fmt.Sprintf("update #%d: table:%s - %s doesn't support rcu or wcu", i+1, entry.Table, ModeOnDemand)))
+		case entry.BillingMode != ModeProvisioned && entry.BillingMode != ModeOnDemand:
+			errs = append(errs, errors.New(fmt.Sprintf("update #%d: table:%s - invalid billing mode:%s, %s or %s is expected",
+				i+1, entry.Table, entry.BillingMode, ModeProvisioned, ModeOnDemand)))
+		}
+		seen[entry.Table] = true
+	}
+	return errors.Join(errs...)
+}
+
+// Load reads a manifest from a YAML or JSON file, unknown fields being rejected so that a misspelled setting isn't silently ignored.
+// It returns the manifest and an error if the file can't be read or parsed, or the manifest is invalid.
+func Load(file string) (*Manifest, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read manifest file:%s - error:%v", file, err))
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	var manifest Manifest
+	if err = decoder.Decode(&manifest); err != nil && err != io.EOF {
+		return nil, errors.New(fmt.Sprintf("Failed to parse manifest file:%s - error:%v", file, err))
+	}
+	if err = manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest file:%s:\n%w", file, err)
+	}
+	return &manifest, nil
+}
+
+// Save writes the manifest to a YAML file, preceded by the comment lines of the header.
+// It returns an error if the file can't be written.
+func Save(file string, manifest *Manifest, header []string) error {
+	var content bytes.Buffer
+	for _, line := range header {
+		fmt.Fprintf(&content, "# %s\n", line)
+	}
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return errors.New(fmt.Sprintf("Failed to encode manifest - error:%v", err))
+	}
+	if err := os.WriteFile(file, content.Bytes(), 0644); err != nil {
+		return errors.New(fmt.Sprintf("Failed to write manifest file:%s - error:%v", file, err))
+	}
+	return nil
+}
+
+// Result is the outcome of an update of the manifest.
+type Result struct {
+	Table  string `json:"table"`
+	Update string `json:"update"`
+	outcome.Outcome
+}
+
+// WriteResults writes the outcome of the updates in the given output format.
+func WriteResults(w io.Writer, results []Result, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, results)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tUPDATE\tSTATUS\tERROR\n")
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Table, result.Update, result.Status, result.Error)
+	}
+	return tw.Flush()
+}
+
+// apply updates the table of the entry to its billing mode and capacity as the update does, or only checks it for a dry run.
+// The result is pending for a dry run with changes, and unchanged if the table already has the billing mode and capacity.
+func apply(dbmgr *client.DynamoDBManager, entry Entry, dryRun bool) Result {
+	result := Result{Table: entry.Table, Update: entry.String(), Outcome: outcome.Outcome{Status: StatusUpdated}}
+	var err error
+	if entry.BillingMode == ModeOnDemand {
+		err = ExecuteUpdateTask(dbmgr, entry.Table, "", "", true, false, dryRun)
+	} else {
+		err = ExecuteUpdateTask(dbmgr, entry.Table, fmt.Sprintf("%d", entry.Rcu), fmt.Sprintf("%d", entry.Wcu), false, true, dryRun)
+	}
+	switch {
+	case errors.Is(err, client.ErrPendingChanges):
+		result.Status = StatusPending
+	case err != nil:
+		result.Fail(err)
+	case dryRun:
+		result.Status = StatusUnchanged
+	}
+	return result
+}
+
+// ExecuteApply applies the billing mode and capacity updates of a manifest file, once the user confirms unless 'yes' is set.
+// The tables are checked first, as for a dry run: only the tables with changes pending are shown and updated, the others are unchanged.
+// It takes a DynamoDBManager, the manifest file, the dry-run and confirmation flags and the output format of the report as input.
+// It returns an error if the manifest is invalid or meant for another region, an error wrapping client.ErrPendingChanges for a dry run
+// with tables to update, client.ErrPartialFailure if some updates failed, or the error of the failure if all of them failed.
+func ExecuteApply(dbmgr *client.DynamoDBManager, file string, dryRun bool, yes bool, format string) error {
+	manifest, err := Load(file)
+	if err != nil {
+		return err
+	}
+	if manifest.Region != "" && dbmgr.Config.Region != "" && manifest.Region != dbmgr.Config.Region {
+		return errors.New(fmt.Sprintf("manifest file:%s is meant for region:%s, not region:%s", file, manifest.Region, dbmgr.Config.Region))
+	}
+
+	results := make([]Result, 0, len(manifest.Updates))
+	var plan []Result
+	for _, entry := range manifest.Updates {
+		result := apply(dbmgr, entry, true)
+		if result.Status == StatusPending {
+			plan = append(plan, result)
+		}
+		results = append(results, result)
+	}
+
+	if !dryRun && len(plan) > 0 {
+		show := func(w io.Writer) error { return WriteResults(w, plan, output.Text) }
+		if err = prompt.ApprovePlan(yes, fmt.Sprintf("Apply the updates of %d table(s)?", len(plan)), show); err != nil {
+			return err
+		}
+		for i, entry := range manifest.Updates {
+			if results[i].Status == StatusPending {
+				results[i] = apply(dbmgr, entry, false)
+			}
+		}
+	}
+
+	if err = WriteResults(output.Stdout, results, format); err != nil {
+		return err
+	}
+
+	var errs []error
+	if pending := outcome.CountStatus(results, StatusPending); pending > 0 {
+		errs = append(errs, fmt.Errorf("update pending for %d table(s) - %w", pending, client.ErrPendingChanges))
+	}
+	return errors.Join(append(errs, outcome.Summarize("update", "table(s)", results))...)
+}
@@ -0,0 +1,136 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+func TestManifestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates []Entry
+		want    string // Part of the error message, empty if the manifest is valid
+	}{
+		{"valid", []Entry{{Table: "orders", BillingMode: ModeProvisioned, Rcu: 10, Wcu: 5}, {Table: "events", BillingMode: ModeOnDemand}}, ""},
+		{"no update", nil, "has no update"},
+		{"missing table", []Entry{{BillingMode: ModeOnDemand}}, "the table is missing"},
+		{"duplicate table", []Entry{{Table: "orders", BillingMode: ModeOnDemand}, {Table: "orders", BillingMode: ModeOnDemand}}, "more than once"},
+		{"missing capacity", []Entry{{Table: "orders", BillingMode: ModeProvisioned, Rcu: 10}}, "rcu and wcu of at least 1"},
+		{"capacity of on-demand", []Entry{{Table: "orders", BillingMode: ModeOnDemand, Wcu: 5}}, "doesn't support rcu or wcu"},
+		{"invalid billing mode", []Entry{{Table: "orders", BillingMode: "ON_DEMAND"}}, "invalid billing mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Manifest{Updates: tt.updates}).Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate() error = %v", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("Validate() returned no error, want %q", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "manifest.yaml")
+	saved := &Manifest{Region: "eu-west-1", Updates: []Entry{
+		{Table: "orders", BillingMode: ModeProvisioned, Rcu: 10, Wcu: 5},
+		{Table: "events", BillingMode: ModeOnDemand},
+	}}
+	if err := Save(file, saved, []string{"Generated for the test"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if fmt.Sprint(loaded) != fmt.Sprint(saved) {
+		t.Errorf("Load() = %v, want %v", loaded, saved)
+	}
+
+	if err = os.WriteFile(file, []byte("updates:\n  - table: orders\n    billingMode: PAY_PER_REQUEST\n    rcuu: 10\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(file); err == nil {
+		t.Error("Load() of a misspelled field returned no error")
+	}
+}
+
+func TestExecuteApply(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "manifest.yaml")
+	manifest := &Manifest{Updates: []Entry{
+		{Table: "orders", BillingMode: ModeProvisioned, Rcu: 10, Wcu: 5},
+		{Table: "events", BillingMode: ModeOnDemand},
+		{Table: "users", BillingMode: ModeOnDemand},
+	}}
+	if err := Save(file, manifest, nil); err != nil {
+		t.Fatal(err)
+	}
+	dbmgr := &client.DynamoDBManager{}
+	if err := client.SetupLogger(dbmgr, "Error"); err != nil {
+		t.Fatal(err)
+	}
+	stdout, task := output.Stdout, ExecuteUpdateTask
+	defer func() {
+		output.Stdout, ExecuteUpdateTask = stdout, task
+	}()
+
+	errFailure := errors.New("update failure")
+	tests := []struct {
+		name    string
+		dryRun  bool
+		updated []string // Tables updated, prefixed with '!' if the update fails with errFailure
+		want    []error
+	}{
+		{"dry run", true, nil, []error{client.ErrPendingChanges}},
+		{"all updated", false, []string{"orders", "events"}, nil},
+		{"some failed", false, []string{"orders", "!events"}, []error{client.ErrPartialFailure}},
+		{"all updates failed", false, []string{"!orders", "!events"}, []error{client.ErrPartialFailure}}, // The unchanged table counts
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated []string
+			ExecuteUpdateTask = func(dbmgr *client.DynamoDBManager, tableName string, rcu string, wcu string, onDemand bool, provisioned bool, dryRun bool) error {
+				switch {
+				case tableName == "users":
+					return nil // Already on-demand
+				case dryRun:
+					return client.ErrPendingChanges
+				}
+				updated = append(updated, tableName)
+				for _, table := range tt.updated {
+					if table == "!"+tableName {
+						return errFailure
+					}
+				}
+				return nil
+			}
+			output.Stdout = &bytes.Buffer{}
+
+			err := ExecuteApply(dbmgr, file, tt.dryRun, true, output.JSON)
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("ExecuteApply() = %v, want %v", err, want)
+				}
+			}
+			if len(tt.want) == 0 && err != nil {
+				t.Errorf("ExecuteApply() = %v", err)
+			}
+			if len(updated) != len(tt.updated) {
+				t.Errorf("ExecuteApply() updated %v, want %v", updated, tt.updated)
+			}
+		})
+	}
+}
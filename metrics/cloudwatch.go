@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+// namespace of the DynamoDB metrics in CloudWatch
+const namespace = "AWS/DynamoDB"
+
+var NewCloudWatchClient = cloudwatch.NewFromConfig
+
+// CloudWatchSource fetches the metrics of the tables from CloudWatch.
+type CloudWatchSource struct {
+	client *cloudwatch.Client
+}
+
+// NewCloudWatchSource creates a CloudWatch source with the credentials and region of the DynamoDB manager.
+// The DynamoDB endpoint, e.g. of DynamoDB Local, isn't used for CloudWatch.
+func NewCloudWatchSource(dbmgr *client.DynamoDBManager) *CloudWatchSource {
+	return &CloudWatchSource{
+		client: NewCloudWatchClient(dbmgr.Config, func(o *cloudwatch.Options) {
+			o.BaseEndpoint = nil
+		}),
+	}
+}
+
+// Fetch returns the datapoints of each of the metrics of the table within the window, fetched by a single GetMetricData query.
+// It returns an error if CloudWatch fails or reports a metric as incomplete.
+func (s *CloudWatchSource) Fetch(table string, metrics []string, window Window) (map[string][]Datapoint, error) {
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(window.Start),
+		EndTime:   aws.Time(window.End),
+		ScanBy:    types.ScanByTimestampAscending,
+	}
+	ids := map[string]string{}
+	for i, metric := range metrics {
+		ids[fmt.Sprintf("m%d", i)] = metric
+		input.MetricDataQueries = append(input.MetricDataQueries, types.MetricDataQuery{
+			Id: aws.String(fmt.Sprintf("m%d", i)),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String(namespace),
+					MetricName: aws.String(metric),
+					Dimensions: []types.Dimension{{Name: aws.String("TableName"), Value: aws.String(table)}},
+				},
+				Period: aws.Int32(int32(window.Period.Seconds())),
+				Stat:   aws.String("Sum"),
+			},
+		})
+	}
+
+	datapoints := map[string][]Datapoint{}
+	paginator := cloudwatch.NewGetMetricDataPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get the metrics of table:%s - %w", table, err)
+		}
+		for _, result := range page.MetricDataResults {
+			metric := ids[aws.ToString(result.Id)]
+			if result.StatusCode == types.StatusCodeInternalError || result.StatusCode == types.StatusCodeForbidden {
+				return nil, errors.New(fmt.Sprintf("Failed to get metric:%s of table:%s - status:%s", metric, table, result.StatusCode))
+			}
+			for i, timestamp := range result.Timestamps {
+				datapoints[metric] = append(datapoints[metric], Datapoint{Time: timestamp, Value: result.Values[i]})
+			}
+		}
+	}
+	for _, series := range datapoints {
+		sort.Slice(series, func(i, j int) bool { return series[i].Time.Before(series[j].Time) })
+	}
+	return datapoints, nil
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// StaticSource holds the datapoints of the tables by table name and metric name, e.g. loaded from a file or set up by hand.
+type StaticSource map[string]map[string][]Datapoint
+
+// Fetch returns the datapoints of each of the metrics of the table within the window.
+// It returns an error if the source has no metrics for the table.
+func (s StaticSource) Fetch(table string, metrics []string, window Window) (map[string][]Datapoint, error) {
+	tableMetrics, exists := s[table]
+	if !exists {
+		return nil, errors.New(fmt.Sprintf("no metrics of table:%s", table))
+	}
+	datapoints := map[string][]Datapoint{}
+	for _, metric := range metrics {
+		for _, datapoint := range tableMetrics[metric] {
+			if !datapoint.Time.Before(window.Start) && datapoint.Time.Before(window.End) {
+				datapoints[metric] = append(datapoints[metric], datapoint)
+			}
+		}
+		series := datapoints[metric]
+		sort.Slice(series, func(i, j int) bool { return series[i].Time.Before(series[j].Time) })
+	}
+	return datapoints, nil
+}
+
+// LoadFile reads the metrics of the tables from a JSON file, e.g. exported from CloudWatch, of the form
+// {"table": {"ConsumedReadCapacityUnits": [{"time": "2024-03-01T10:00:00Z", "value": 1200}, ...], ...}, ...},
+// each value being the sum of the metric over the period starting at its time.
+// It returns the source and an error if the file can't be read or decoded.
+func LoadFile(file string) (StaticSource, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read metrics file:%s - error:%v", file, err))
+	}
+	var source StaticSource
+	if err = json.Unmarshal(content, &source); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to decode metrics file:%s - error:%v", file, err))
+	}
+	return source, nil
+}
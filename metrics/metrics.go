@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Metrics of a DynamoDB table published to CloudWatch
+const (
+	ConsumedReadCapacity  string = "ConsumedReadCapacityUnits"
+	ConsumedWriteCapacity string = "ConsumedWriteCapacityUnits"
+	ReadThrottleEvents    string = "ReadThrottleEvents"
+	WriteThrottleEvents   string = "WriteThrottleEvents"
+)
+
+// Names lists the metrics a recommendation is based on
+var Names = []string{ConsumedReadCapacity, ConsumedWriteCapacity, ReadThrottleEvents, WriteThrottleEvents}
+
+// Datapoint is the sum of a metric over the period starting at its time.
+type Datapoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Window is the time range the metrics are fetched over, in periods of the given length.
+type Window struct {
+	Start  time.Time
+	End    time.Time
+	Period time.Duration
+}
+
+// NewWindow returns the window of the last given number of days up to now, in periods of the given length.
+func NewWindow(days int, period time.Duration) Window {
+	end := time.Now().UTC().Truncate(period)
+	return Window{Start: end.AddDate(0, 0, -days), End: end, Period: period}
+}
+
+// Validate checks that the window isn't empty and the period is a multiple of a minute, as CloudWatch expects.
+// It returns an error if the window is invalid.
+func (w Window) Validate() error {
+	if !w.End.After(w.Start) {
+		return errors.New("the metrics window is empty")
+	}
+	if w.Period < time.Minute || w.Period%time.Minute != 0 {
+		return errors.New(fmt.Sprintf("invalid metrics period:%s, a multiple of a minute is expected", w.Period))
+	}
+	return nil
+}
+
+// Source provides the metrics of the tables, e.g. CloudWatch or a file.
+type Source interface {
+	// Fetch returns the datapoints of each of the metrics of the table within the window, sorted by time.
+	// The periods without datapoint had no activity.
+	Fetch(table string, metrics []string, window Window) (map[string][]Datapoint, error)
+}
+
+// Stats sums up the datapoints of a consumed capacity metric as capacity units per second.
+type Stats struct {
+	Mean       float64 `json:"mean"`
+	Percentile float64 `json:"percentile"`
+	Max        float64 `json:"max"`
+	Periods    int     `json:"periods"` // Number of periods of the window with a datapoint
+}
+
+// Summarize computes the mean, the given percentile and the maximum of the datapoints, converted to units per second.
+// The periods of the window without datapoint count as idle for the mean and the percentile.
+func Summarize(datapoints []Datapoint, window Window, percentile float64) Stats {
+	periods := int(window.End.Sub(window.Start) / window.Period)
+	stats := Stats{Periods: len(datapoints)}
+	if periods < len(datapoints) {
+		periods = len(datapoints)
+	}
+	if periods == 0 {
+		return stats
+	}
+
+	seconds := window.Period.Seconds()
+	rates := make([]float64, periods)
+	sum := 0.0
+	for i, datapoint := range datapoints {
+		rates[i] = datapoint.Value / seconds
+		sum += rates[i]
+	}
+	sort.Float64s(rates)
+
+	stats.Mean = sum / float64(periods)
+	stats.Max = rates[periods-1]
+	// Nearest rank percentile
+	rank := int(math.Ceil(percentile/100*float64(periods))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	stats.Percentile = rates[rank]
+	return stats
+}
+
+// Total returns the sum of the datapoints, e.g. the number of throttle events over the window.
+func Total(datapoints []Datapoint) float64 {
+	total := 0.0
+	for _, datapoint := range datapoints {
+		total += datapoint.Value
+	}
+	return total
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	window := Window{Start: start, End: start.Add(4 * time.Minute), Period: time.Minute}
+	// Sums over a minute: 60, 120 and 240 units are 1, 2 and 4 units per second, the fourth period is idle
+	datapoints := []Datapoint{{Time: start, Value: 60}, {Time: start.Add(time.Minute), Value: 120}, {Time: start.Add(2 * time.Minute), Value: 240}}
+
+	tests := []struct {
+		name       string
+		datapoints []Datapoint
+		percentile float64
+		want       Stats
+	}{
+		{"no datapoint", nil, 90, Stats{}},
+		{"median", datapoints, 50, Stats{Mean: 1.75, Percentile: 1, Max: 4, Periods: 3}},
+		{"p75", datapoints, 75, Stats{Mean: 1.75, Percentile: 2, Max: 4, Periods: 3}},
+		{"p100", datapoints, 100, Stats{Mean: 1.75, Percentile: 4, Max: 4, Periods: 3}},
+		{"p0", datapoints, 0, Stats{Mean: 1.75, Percentile: 0, Max: 4, Periods: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.datapoints, window, tt.percentile); got != tt.want {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowValidate(t *testing.T) {
+	start := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	if err := (Window{Start: start, End: start.Add(time.Hour), Period: 5 * time.Minute}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (Window{Start: start, End: start, Period: time.Minute}).Validate(); err == nil {
+		t.Error("Validate() of an empty window returned no error")
+	}
+	if err := (Window{Start: start, End: start.Add(time.Hour), Period: 90 * time.Second}).Validate(); err == nil {
+		t.Error("Validate() of a period that isn't a multiple of a minute returned no error")
+	}
+}
@@ -0,0 +1,75 @@
+package outcome
+
+import (
+	"fmt"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+// StatusFailed is the status of an operation that failed
+const StatusFailed string = "failed"
+
+// Outcome is the status of an operation on a table or a backup, and the error of its failure.
+// It is embedded in the results the commands report.
+type Outcome struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	err    error
+}
+
+// Fail marks the operation as failed with the given error.
+func (o *Outcome) Fail(err error) {
+	o.Status = StatusFailed
+	o.Error = err.Error()
+	o.err = err
+}
+
+// Err returns the error of the failure, nil if the operation didn't fail.
+func (o Outcome) Err() error {
+	return o.err
+}
+
+func (o Outcome) outcome() Outcome {
+	return o
+}
+
+// Result is the result of an operation, embedding its Outcome.
+type Result interface {
+	outcome() Outcome
+}
+
+// CountStatus returns the number of results with the given status.
+func CountStatus[T Result](results []T, status string) int {
+	count := 0
+	for _, result := range results {
+		if result.outcome().Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Summarize returns the error summing up an operation on several items, named by 'items' e.g. "table(s)", see Summary.
+func Summarize[T Result](operation string, items string, results []T) error {
+	failed := 0
+	var lastErr error
+	for _, result := range results {
+		if o := result.outcome(); o.Status == StatusFailed {
+			failed, lastErr = failed+1, o.err
+		}
+	}
+	return Summary(operation, items, failed, len(results), lastErr)
+}
+
+// Summary returns the error summing up an operation on 'total' items of which 'failed' failed:
+// nil if none failed, client.ErrPartialFailure if some failed, or the error of the last failure if all of them failed.
+func Summary(operation string, items string, failed int, total int, lastErr error) error {
+	switch {
+	case failed == 0:
+		return nil
+	case failed == total:
+		return fmt.Errorf("%s failed for all %d %s - %w", operation, failed, items, lastErr)
+	default:
+		return fmt.Errorf("%s failed for %d of %d %s - %w", operation, failed, total, items, client.ErrPartialFailure)
+	}
+}
@@ -0,0 +1,61 @@
+package outcome
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+type testResult struct {
+	Name string
+	Outcome
+}
+
+func TestSummarize(t *testing.T) {
+	errLast := errors.New("last failure")
+	failed := func(name string, err error) testResult {
+		result := testResult{Name: name}
+		result.Fail(err)
+		return result
+	}
+	done := func(name string) testResult {
+		return testResult{Name: name, Outcome: Outcome{Status: "done"}}
+	}
+
+	tests := []struct {
+		name    string
+		results []testResult
+		want    error
+	}{
+		{"none", nil, nil},
+		{"none failed", []testResult{done("a"), done("b")}, nil},
+		{"some failed", []testResult{done("a"), failed("b", errors.New("first failure"))}, client.ErrPartialFailure},
+		{"all failed", []testResult{failed("a", errors.New("first failure")), failed("b", errLast)}, errLast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Summarize("update", "table(s)", tt.results)
+			if (tt.want == nil) != (err == nil) || !errors.Is(err, tt.want) {
+				t.Errorf("Summarize() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountStatus(t *testing.T) {
+	result := testResult{Name: "c"}
+	result.Fail(errors.New("failure"))
+	results := []testResult{{Name: "a", Outcome: Outcome{Status: "done"}}, {Name: "b", Outcome: Outcome{Status: "done"}}, result}
+
+	if got := CountStatus(results, "done"); got != 2 {
+		t.Errorf("CountStatus(done) = %d, want 2", got)
+	}
+	if got := CountStatus(results, StatusFailed); got != 1 {
+		t.Errorf("CountStatus(failed) = %d, want 1", got)
+	}
+	if result.Error != "failure" || result.Err() == nil {
+		t.Errorf("Fail() left error:%q err:%v", result.Error, result.Err())
+	}
+}
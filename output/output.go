@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Output formats supported by the commands
+const (
+	Text string = "text"
+	JSON string = "json"
+)
+
+// ANSI colors of the human readable output
+const (
+	Red    string = "\033[31m"
+	Green  string = "\033[32m"
+	Yellow string = "\033[33m"
+	reset  string = "\033[0m"
+)
+
+// Stdout is where the command results are written, logs are written separately by the logger
+var Stdout io.Writer = os.Stdout
+
+// Colored tells whether the human readable output is colored: only on a terminal, unless NO_COLOR is set
+var Colored = term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == ""
+
+// Validate checks that the format is one of the supported output formats.
+// It returns an error if the format is not recognized.
+func Validate(format string) error {
+	switch format {
+	case Text, JSON:
+		return nil
+	}
+	return errors.New(fmt.Sprintf("unrecognized output format provided:%s", format))
+}
+
+// WriteJSON writes the value as indented JSON followed by a newline.
+// It returns an error if the value can't be encoded.
+func WriteJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// NewTabWriter creates a tab writer aligning the columns of the human readable output.
+// The caller must Flush it once all the rows are written.
+func NewTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+}
+
+// Colorize wraps the text in the given color if the output is colored, the text is returned as it is otherwise.
+func Colorize(color string, text string) string {
+	if !Colored || color == "" {
+		return text
+	}
+	return color + text + reset
+}
+
+// FormatTime formats an optional timestamp for the human readable output, "-" if it is not set.
+func FormatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// FormatBytes formats a size in bytes with a binary unit for the human readable output.
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,193 @@
+package partiql
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+const (
+	prompt   = "sql> "
+	helpText = `Enter one PartiQL statement per line, e.g. SELECT * FROM "my-table" WHERE id = 'a'
+Tab completes the table names, up and down browse the history.
+help shows this text, exit, quit or Ctrl-D leave.
+`
+)
+
+// completer completes the table names of the statement being typed.
+type completer struct {
+	tables []string
+}
+
+// isSeparator reports whether the character ends a word of the statement.
+func isSeparator(c byte) bool {
+	return strings.IndexByte(" \t,()=", c) >= 0
+}
+
+// needsQuotes reports whether a table name must be quoted in a statement, PartiQL identifiers are letters, digits and '_'.
+func needsQuotes(name string) bool {
+	for _, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return true
+		}
+	}
+	return false
+}
+
+// complete is the AutoCompleteCallback of the terminal, it completes the word before the cursor on Tab.
+// A unique match is completed with the quotes it needs, several matches up to their common prefix.
+func (c *completer) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	start := pos
+	for start > 0 && !isSeparator(line[start-1]) {
+		start--
+	}
+	word := line[start:pos]
+	quoted := strings.HasPrefix(word, `"`)
+	prefix := strings.TrimPrefix(word, `"`)
+
+	var matches []string
+	for _, table := range c.tables {
+		if strings.HasPrefix(table, prefix) {
+			matches = append(matches, table)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, completion) {
+			completion = completion[:len(completion)-1]
+		}
+	}
+	switch {
+	case len(matches) == 1 && (quoted || needsQuotes(completion)):
+		completion = `"` + completion + `"`
+	case quoted:
+		completion = `"` + completion
+	}
+	return line[:start] + completion + line[pos:], start + len(completion), true
+}
+
+// runLine runs a statement of the shell, a select without results is not a failure there.
+func runLine(dbmgr *client.DynamoDBManager, line string, consistentRead bool, format string) error {
+	statement, err := NewStatement(line, "")
+	if err == nil {
+		err = run(dbmgr, output.Stdout, statement, consistentRead, 0, format)
+	}
+	if errors.Is(err, client.ErrNoMatches) {
+		return nil
+	}
+	return err
+}
+
+// ExecuteInteractive runs a PartiQL shell reading one statement per line and writing the results in the given output format.
+// It takes a DynamoDBManager, the consistent-read flag and the output format as input.
+// On a terminal the lines are edited with history and table name completion, otherwise they are read from stdin as a script.
+// Failed statements are reported and the shell goes on.
+// It returns client.ErrPartialFailure if some statements failed.
+func ExecuteInteractive(dbmgr *client.DynamoDBManager, consistentRead bool, format string) error {
+	fd := int(os.Stdin.Fd())
+	var readLine func() (string, error)
+
+	if term.IsTerminal(fd) {
+		c := &completer{}
+		tables, err := GetTableListClient(dbmgr)
+		if err != nil {
+			dbmgr.Logger.Warnf("Table names can't be completed, listing the tables failed due to:%v", err)
+		}
+		sort.Strings(tables)
+		c.tables = tables
+
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, prompt)
+		terminal.AutoCompleteCallback = c.complete
+		fmt.Fprint(os.Stdout, helpText)
+
+		readLine = func() (string, error) {
+			// The terminal is raw only while a line is edited, so the results and logs are written as usual
+			oldState, err := term.MakeRaw(fd)
+			if err != nil {
+				return "", err
+			}
+			defer term.Restore(fd, oldState)
+			if width, height, err := term.GetSize(fd); err == nil {
+				terminal.SetSize(width, height)
+			}
+			line, err := terminal.ReadLine()
+			if err == io.EOF {
+				fmt.Fprintln(os.Stdout)
+			}
+			return line, err
+		}
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		readLine = func() (string, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			}
+			return scanner.Text(), nil
+		}
+	}
+
+	executed, failed := 0, 0
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		switch strings.ToLower(strings.TrimSuffix(line, ";")) {
+		case "":
+			continue
+		case "exit", "quit", `\q`:
+			return shellResult(executed, failed)
+		case "help", `\h`, `\?`:
+			fmt.Fprint(output.Stdout, helpText)
+			continue
+		}
+		if strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		err = runLine(dbmgr, line, consistentRead, format)
+		executed++
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+
+	return shellResult(executed, failed)
+}
+
+// shellResult returns client.ErrPartialFailure if some statements of the shell failed.
+func shellResult(executed int, failed int) error {
+	if failed > 0 {
+		return fmt.Errorf("%d of %d statement(s) failed - %w", failed, executed, client.ErrPartialFailure)
+	}
+	return nil
+}
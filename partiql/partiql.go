@@ -0,0 +1,321 @@
+package partiql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/query"
+)
+
+// BatchSize is the maximum number of statements of a BatchExecuteStatement call
+const BatchSize = 25
+
+// Statuses of a statement of a batch
+const (
+	StatusSucceeded string = "succeeded"
+	StatusFailed    string = "failed"
+	StatusPending   string = "pending"
+)
+
+var (
+	ExecuteStatementClient      = client.ExecuteStatement
+	BatchExecuteStatementClient = client.BatchExecuteStatement
+	GetTableListClient          = client.GetTableList
+)
+
+// Statement is a PartiQL statement with the plain JSON values bound to its '?' placeholders.
+type Statement struct {
+	Statement  string        `json:"statement"`
+	Parameters []interface{} `json:"parameters,omitempty"`
+}
+
+// attributeValues converts the plain JSON parameters of the statement into attribute values.
+// It returns an error if a parameter can't be converted.
+func (s *Statement) attributeValues() ([]types.AttributeValue, error) {
+	if len(s.Parameters) == 0 {
+		return nil, nil
+	}
+	values := make([]types.AttributeValue, 0, len(s.Parameters))
+	for idx, parameter := range s.Parameters {
+		value, err := items.FromPlain(parameter)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("parameter:%d - %v", idx+1, err))
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// decodeJSON decodes plain JSON keeping the numbers as json.Number, to preserve their precision.
+func decodeJSON(content []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// NewStatement creates a statement with its parameters given as a plain JSON array, e.g. ["a", 3].
+// It returns the statement and an error if the statement is empty or the parameters are malformed.
+func NewStatement(statement string, parameters string) (Statement, error) {
+	s := Statement{Statement: strings.TrimSuffix(strings.TrimSpace(statement), ";")}
+	if s.Statement == "" {
+		return s, errors.New("statement must be provided!")
+	}
+	if parameters != "" {
+		if err := decodeJSON([]byte(parameters), &s.Parameters); err != nil {
+			return s, errors.New(fmt.Sprintf("invalid parameters:%s, a plain JSON array is expected - error:%v", parameters, err))
+		}
+	}
+	_, err := s.attributeValues()
+	return s, err
+}
+
+// IsSelect reports whether the statement reads items.
+func (s *Statement) IsSelect() bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s.Statement)), "SELECT")
+}
+
+// Execute runs a statement and reads all the pages of its results, up to the limit if not 0.
+// It returns the items read and an error.
+func Execute(dbmgr *client.DynamoDBManager, statement Statement, consistentRead bool, limit int) ([]items.Item, error) {
+	parameters, err := statement.attributeValues()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ExecuteStatementInput{
+		Statement:      aws.String(statement.Statement),
+		Parameters:     parameters,
+		ConsistentRead: aws.Bool(consistentRead),
+	}
+	var results []items.Item
+	for {
+		page, err := ExecuteStatementClient(dbmgr, input)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, page.Items...)
+		dbmgr.Logger.Debugf("Statement read %d item(s) of the page, %d in total", len(page.Items), len(results))
+		if page.NextToken == nil || (limit > 0 && len(results) >= limit) {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// run executes a statement and writes the items it returns in the given output format.
+// It returns client.ErrNoMatches if a select doesn't return any item.
+func run(dbmgr *client.DynamoDBManager, w io.Writer, statement Statement, consistentRead bool, limit int, format string) error {
+	results, err := Execute(dbmgr, statement, consistentRead, limit)
+	if err != nil {
+		return err
+	}
+	if !statement.IsSelect() && len(results) == 0 {
+		dbmgr.Logger.Infof("Statement executed: %s", statement.Statement)
+		return nil
+	}
+	if err = query.WriteItems(w, results, nil, format); err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return client.ErrNoMatches
+	}
+	return nil
+}
+
+// ExecuteSQL runs a PartiQL statement and writes the items it returns in the given output format.
+// It takes a DynamoDBManager, the statement, its parameters as a plain JSON array, the consistent-read flag,
+// the maximum number of items to return, all if 0, and the output format as input.
+// It returns client.ErrNoMatches if a select doesn't return any item, or an error if the statement fails.
+func ExecuteSQL(dbmgr *client.DynamoDBManager, statement string, parameters string, consistentRead bool, limit int, format string) error {
+	s, err := NewStatement(statement, parameters)
+	if err != nil {
+		return err
+	}
+	return run(dbmgr, output.Stdout, s, consistentRead, limit, format)
+}
+
+// ReadStatements reads one statement per line, either as plain PartiQL or as a JSON object with its parameters,
+// e.g. {"statement":"UPDATE t SET n = ? WHERE id = ?","parameters":[3,"a"]}.
+// Empty lines and "--" comments are skipped.
+// It returns the statements and an error if a line is malformed.
+func ReadStatements(r io.Reader) ([]Statement, error) {
+	var statements []Statement
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		var s Statement
+		if strings.HasPrefix(line, "{") {
+			if err := decodeJSON([]byte(line), &s); err != nil {
+				return nil, errors.New(fmt.Sprintf("invalid statement on line:%d - error:%v", lineNumber, err))
+			}
+		} else {
+			s.Statement = line
+		}
+		s.Statement = strings.TrimSuffix(strings.TrimSpace(s.Statement), ";")
+		if s.Statement == "" {
+			return nil, errors.New(fmt.Sprintf("empty statement on line:%d", lineNumber))
+		}
+		if _, err := s.attributeValues(); err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid statement on line:%d - %v", lineNumber, err))
+		}
+		statements = append(statements, s)
+	}
+	return statements, scanner.Err()
+}
+
+// BatchResult is the outcome of a statement of a batch.
+type BatchResult struct {
+	Statement string                 `json:"statement"`
+	Status    string                 `json:"status"`
+	Item      map[string]interface{} `json:"item,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// executeBatch runs up to BatchSize statements with a single call.
+// It returns the outcome of every statement.
+func executeBatch(dbmgr *client.DynamoDBManager, statements []Statement, consistentRead bool) []BatchResult {
+	results := make([]BatchResult, len(statements))
+	requests := make([]types.BatchStatementRequest, 0, len(statements))
+	for idx, s := range statements {
+		parameters, _ := s.attributeValues()
+		requests = append(requests, types.BatchStatementRequest{
+			Statement:      aws.String(s.Statement),
+			Parameters:     parameters,
+			ConsistentRead: aws.Bool(consistentRead),
+		})
+		results[idx] = BatchResult{Statement: s.Statement, Status: StatusSucceeded}
+	}
+
+	batchOutput, err := BatchExecuteStatementClient(dbmgr, &dynamodb.BatchExecuteStatementInput{Statements: requests})
+	for idx := range results {
+		switch {
+		case err != nil:
+			results[idx].Status, results[idx].Error = StatusFailed, err.Error()
+		case idx >= len(batchOutput.Responses):
+			results[idx].Status, results[idx].Error = StatusFailed, "no response returned"
+		case batchOutput.Responses[idx].Error != nil:
+			response := batchOutput.Responses[idx].Error
+			results[idx].Status = StatusFailed
+			results[idx].Error = fmt.Sprintf("%s: %s", response.Code, aws.ToString(response.Message))
+		case len(batchOutput.Responses[idx].Item) > 0:
+			results[idx].Item = items.ToPlainItem(batchOutput.Responses[idx].Item)
+		}
+	}
+	return results
+}
+
+// Batches splits the statements into batches of up to BatchSize statements, all reads or all writes,
+// as BatchExecuteStatement requires. The statements keep their order, a batch ends where the kind of statement changes.
+func Batches(statements []Statement) [][]Statement {
+	var batches [][]Statement
+	start := 0
+	for idx := 1; idx <= len(statements); idx++ {
+		if idx == len(statements) || idx-start == BatchSize || statements[idx].IsSelect() != statements[start].IsSelect() {
+			batches = append(batches, statements[start:idx])
+			start = idx
+		}
+	}
+	return batches
+}
+
+// WriteBatchReport writes the outcome of every statement of a batch in the given output format.
+func WriteBatchReport(w io.Writer, results []BatchResult, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, results)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "#\tSTATUS\tRESULT\n")
+	for idx, result := range results {
+		details := result.Error
+		if result.Item != nil {
+			encoded, _ := json.Marshal(result.Item)
+			details = string(encoded)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", idx+1, result.Status, details)
+	}
+	return tw.Flush()
+}
+
+// ExecuteBatchSQL runs the statements of a file with BatchExecuteStatement calls of up to 25 statements.
+// It takes a DynamoDBManager, the statement file, "-" for stdin, the consistent-read flag, the dry-run flag and the output format as input.
+// The statements are grouped into batches of reads and batches of writes, see Batches, and ReadStatements for the file format.
+// With dryRun set, the statements are only validated and reported as pending.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, client.ErrPartialFailure if some statements failed,
+// or an error if all of them failed.
+func ExecuteBatchSQL(dbmgr *client.DynamoDBManager, file string, consistentRead bool, dryRun bool, format string) error {
+	var input io.Reader = os.Stdin
+	if file != "-" {
+		opened, err := os.Open(file)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Failed to open the statement file:%s - error:%v", file, err))
+		}
+		defer opened.Close()
+		input = opened
+	}
+	statements, err := ReadStatements(input)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		return errors.New(fmt.Sprintf("no statement found in file:%s", file))
+	}
+
+	batches := Batches(statements)
+	if dryRun {
+		results := make([]BatchResult, 0, len(statements))
+		for _, s := range statements {
+			results = append(results, BatchResult{Statement: s.Statement, Status: StatusPending})
+		}
+		dbmgr.Logger.Infof("Dry run - %d statement(s) would be run in %d batch(es)", len(statements), len(batches))
+		if err = WriteBatchReport(output.Stdout, results, format); err != nil {
+			return err
+		}
+		return fmt.Errorf("%d statement(s) pending - %w", len(statements), client.ErrPendingChanges)
+	}
+
+	var results []BatchResult
+	for _, batch := range batches {
+		results = append(results, executeBatch(dbmgr, batch, consistentRead)...)
+	}
+
+	if err = WriteBatchReport(output.Stdout, results, format); err != nil {
+		return err
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Status == StatusFailed {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(results):
+		return errors.New(fmt.Sprintf("all %d statement(s) failed, first error: %s", failed, results[0].Error))
+	default:
+		return fmt.Errorf("%d of %d statement(s) failed - %w", failed, len(results), client.ErrPartialFailure)
+	}
+}
@@ -0,0 +1,236 @@
+package pitr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+)
+
+// Statuses of a point in time recovery operation
+const (
+	StatusEnabled   string = "enabled"
+	StatusDisabled  string = "disabled"
+	StatusUnchanged string = "unchanged"
+	StatusRestoring string = "restoring"
+	StatusRestored  string = "restored"
+	StatusPending   string = "pending"
+	StatusFailed           = outcome.StatusFailed
+)
+
+// DefaultRestoreTimeout is how long a restore waits for the restored table to become active
+const DefaultRestoreTimeout = time.Hour
+
+var (
+	DescribeContinuousBackupsClient = client.DescribeContinuousBackups
+	UpdateContinuousBackupsClient   = client.UpdateContinuousBackups
+	RestoreTableToPointInTimeClient = client.RestoreTableToPointInTime
+	WaitForTableClient              = client.WaitForTable
+)
+
+// Recovery is the point in time recovery state of a table, or the outcome of an operation on it.
+type Recovery struct {
+	Table string `json:"table"`
+	outcome.Outcome
+	Earliest    *time.Time `json:"earliestRestorableTime,omitempty"`
+	Latest      *time.Time `json:"latestRestorableTime,omitempty"`
+	Target      string     `json:"target,omitempty"`
+	RestoreTime *time.Time `json:"restoreTime,omitempty"`
+}
+
+// enabled reports whether the point in time recovery of the table is enabled.
+func (r *Recovery) enabled() bool {
+	return r.Status == string(types.PointInTimeRecoveryStatusEnabled)
+}
+
+// GetRecovery retrieves the point in time recovery state of a table: its status and restorable time window.
+// It returns the recovery state and an error if the description fails.
+func GetRecovery(dbmgr *client.DynamoDBManager, tableName string) (Recovery, error) {
+	recovery := Recovery{Table: tableName, Outcome: outcome.Outcome{Status: string(types.PointInTimeRecoveryStatusDisabled)}}
+	backups, err := DescribeContinuousBackupsClient(dbmgr, tableName)
+	if err != nil {
+		return recovery, err
+	}
+	if backups != nil && backups.PointInTimeRecoveryDescription != nil {
+		description := backups.PointInTimeRecoveryDescription
+		recovery.Status = string(description.PointInTimeRecoveryStatus)
+		recovery.Earliest = description.EarliestRestorableDateTime
+		recovery.Latest = description.LatestRestorableDateTime
+	}
+	return recovery, nil
+}
+
+// WriteRecoveries writes the point in time recovery states or operation outcomes in the given output format.
+func WriteRecoveries(w io.Writer, recoveries []Recovery, format string) error {
+	if format == output.JSON {
+		if recoveries == nil {
+			recoveries = []Recovery{}
+		}
+		return output.WriteJSON(w, recoveries)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tSTATUS\tEARLIEST RESTORABLE\tLATEST RESTORABLE\tDETAILS\n")
+	for _, recovery := range recoveries {
+		details := recovery.Target
+		if recovery.RestoreTime != nil {
+			details = fmt.Sprintf("to %s as of %s", recovery.Target, output.FormatTime(recovery.RestoreTime))
+		}
+		if recovery.Error != "" {
+			details = recovery.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", recovery.Table, recovery.Status,
+			output.FormatTime(recovery.Earliest), output.FormatTime(recovery.Latest), details)
+	}
+	return tw.Flush()
+}
+
+// ExecuteStatus writes the point in time recovery state of every given table in the given output format.
+// It takes a DynamoDBManager, the table names and the output format as input.
+// It returns client.ErrPartialFailure if some tables couldn't be described, or the error of the failure if none could.
+func ExecuteStatus(dbmgr *client.DynamoDBManager, tables []string, format string) error {
+	recoveries := make([]Recovery, 0, len(tables))
+	for _, tableName := range tables {
+		recovery, err := GetRecovery(dbmgr, tableName)
+		if err != nil {
+			recovery.Status = ""
+			recovery.Fail(err)
+		}
+		recoveries = append(recoveries, recovery)
+	}
+
+	if err := WriteRecoveries(output.Stdout, recoveries, format); err != nil {
+		return err
+	}
+	return outcome.Summarize("status", "table(s)", recoveries)
+}
+
+// ExecuteSetRecovery enables or disables the point in time recovery of every given table, the tables already in that state are left unchanged.
+// It takes a DynamoDBManager, the table names, the state to set, the dry-run and confirmation flags and the output format of the report as input.
+// Disabling drops the restorable history of the tables, so it is done once the user confirms, unless 'yes' is set.
+// It returns an error wrapping client.ErrPendingChanges for a dry run with tables to update,
+// client.ErrPartialFailure if some updates failed, or the error of the failure if all of them failed.
+func ExecuteSetRecovery(dbmgr *client.DynamoDBManager, tables []string, enable bool, dryRun bool, yes bool, format string) error {
+	operation, status := "disable", StatusDisabled
+	if enable {
+		operation, status = "enable", StatusEnabled
+	}
+
+	recoveries := make([]Recovery, 0, len(tables))
+	for _, tableName := range tables {
+		recovery, err := GetRecovery(dbmgr, tableName)
+		switch {
+		case err != nil:
+			recovery.Fail(err)
+		case recovery.enabled() == enable:
+			recovery.Status = StatusUnchanged
+		default:
+			recovery.Status = StatusPending
+		}
+		recoveries = append(recoveries, recovery)
+	}
+
+	pending := outcome.CountStatus(recoveries, StatusPending)
+	switch {
+	case dryRun && pending > 0:
+		if err := WriteRecoveries(output.Stdout, recoveries, format); err != nil {
+			return err
+		}
+		return errors.Join(fmt.Errorf("%s pending for %d table(s) - %w", operation, pending, client.ErrPendingChanges),
+			outcome.Summarize(operation, "table(s)", recoveries))
+	case !enable && pending > 0:
+		plan := func(w io.Writer) error { return WriteRecoveries(w, recoveries, output.Text) }
+		question := fmt.Sprintf("Disable point in time recovery of %d table(s)? Their restorable history is lost.", pending)
+		if err := prompt.ApprovePlan(yes, question, plan); err != nil {
+			return err
+		}
+	}
+
+	for i := range recoveries {
+		if recoveries[i].Status != StatusPending {
+			continue
+		}
+		backups, err := UpdateContinuousBackupsClient(dbmgr, recoveries[i].Table, enable)
+		if err != nil {
+			recoveries[i].Fail(err)
+			continue
+		}
+		recoveries[i].Status = status
+		if backups != nil && backups.PointInTimeRecoveryDescription != nil {
+			recoveries[i].Earliest = backups.PointInTimeRecoveryDescription.EarliestRestorableDateTime
+			recoveries[i].Latest = backups.PointInTimeRecoveryDescription.LatestRestorableDateTime
+		}
+		dbmgr.Logger.Infof("Point in time recovery of table:%s %s", recoveries[i].Table, status)
+	}
+
+	if err := WriteRecoveries(output.Stdout, recoveries, format); err != nil {
+		return err
+	}
+	return outcome.Summarize(operation, "table(s)", recoveries)
+}
+
+// ValidateRestoreTime checks that the restore time lies within the restorable time window of the table.
+// It returns an error if the point in time recovery isn't enabled or the time is out of the window.
+func ValidateRestoreTime(recovery Recovery, restoreTime time.Time) error {
+	if !recovery.enabled() {
+		return errors.New(fmt.Sprintf("point in time recovery of table:%s is %s", recovery.Table, recovery.Status))
+	}
+	if recovery.Earliest != nil && restoreTime.Before(*recovery.Earliest) {
+		return errors.New(fmt.Sprintf("restore time:%s is before the earliest restorable time:%s of table:%s",
+			restoreTime.Format(time.RFC3339), recovery.Earliest.Format(time.RFC3339), recovery.Table))
+	}
+	if recovery.Latest != nil && restoreTime.After(*recovery.Latest) {
+		return errors.New(fmt.Sprintf("restore time:%s is after the latest restorable time:%s of table:%s",
+			restoreTime.Format(time.RFC3339), recovery.Latest.Format(time.RFC3339), recovery.Table))
+	}
+	return nil
+}
+
+// ExecuteRestore restores a table as it was at the given time to a new table, and waits until the restored table is active.
+// It takes a DynamoDBManager, the source and target table names, the restore time, the wait timeout,
+// the dry-run flag and the output format of the report as input.
+// It returns an error wrapping client.ErrPendingChanges for a dry run, or an error if the time isn't restorable or the restore fails.
+func ExecuteRestore(dbmgr *client.DynamoDBManager, sourceTable string, targetTable string, restoreTime time.Time, timeout time.Duration,
+	dryRun bool, format string) error {
+	recovery, err := GetRecovery(dbmgr, sourceTable)
+	if err != nil {
+		return err
+	}
+	if err = ValidateRestoreTime(recovery, restoreTime); err != nil {
+		return err
+	}
+
+	result := recovery
+	result.Status, result.Target, result.RestoreTime = StatusPending, targetTable, &restoreTime
+	if !dryRun {
+		if _, err = RestoreTableToPointInTimeClient(dbmgr, sourceTable, targetTable, restoreTime); err != nil {
+			result.Fail(err)
+		} else {
+			result.Status = StatusRestoring
+			dbmgr.Logger.Infof("Restoring table:%s at %s to table:%s", sourceTable, restoreTime.Format(time.RFC3339), targetTable)
+			if err = WaitForTableClient(dbmgr, targetTable, timeout); err != nil {
+				result.Fail(err)
+			} else {
+				result.Status = StatusRestored
+			}
+		}
+	}
+
+	if err = WriteRecoveries(output.Stdout, []Recovery{result}, format); err != nil {
+		return err
+	}
+	switch result.Status {
+	case StatusPending:
+		return fmt.Errorf("restore of table:%s to table:%s pending - %w", sourceTable, targetTable, client.ErrPendingChanges)
+	case StatusFailed:
+		return result.Err()
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package pricing
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/manifest"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/metrics"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/recommend"
+)
+
+// DefaultMinSavings is the monthly savings below which a switch of billing mode isn't worth flagging
+const DefaultMinSavings = 1.0
+
+// BreakEven compares the monthly cost of a table in its current billing mode with the cost in the other billing mode,
+// for the observed traffic. The provisioned capacity of an on-demand table is the one the recommend command would set.
+type BreakEven struct {
+	Table            string  `json:"table"`
+	Current          Usage   `json:"current"`
+	Alternative      Usage   `json:"alternative"`
+	CurrentCost      float64 `json:"currentMonthly"`
+	AlternativeCost  float64 `json:"alternativeMonthly"`
+	Savings          float64 `json:"savings"`                    // Monthly savings of the switch, negative if it would cost more
+	BreakEvenTraffic float64 `json:"breakEvenTraffic,omitempty"` // Multiple of the observed traffic both billing modes cost the same at
+	Switch           bool    `json:"switch"`
+	Skipped          string  `json:"skipped,omitempty"` // Reason the switch isn't flagged whatever the savings
+	Error            string  `json:"error,omitempty"`
+	err              error
+}
+
+// Fail marks the analysis as failed with the given error.
+func (b *BreakEven) Fail(err error) {
+	b.Error = err.Error()
+	b.err = err
+}
+
+// BreakEvenReport is the break-even analysis of the tables.
+type BreakEvenReport struct {
+	Region   string      `json:"region"`
+	Currency string      `json:"currency"`
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Tables   []BreakEven `json:"tables"`
+	Savings  float64     `json:"savings"` // Monthly savings of the flagged switches
+}
+
+// AnalyzeBreakEven estimates the monthly cost of a table in both billing modes from its consumed capacity over the window.
+// The switch is flagged if it saves at least the minimum savings, unless the table has global secondary indexes:
+// their capacity is neither estimated nor set by the manifest, so the switch is skipped.
+// The analysis is marked as failed if the table can't be described, its metrics fetched or the prices found.
+func AnalyzeBreakEven(dbmgr *client.DynamoDBManager, prices *PriceTable, source metrics.Source, tableName string,
+	opts recommend.Options, minSavings float64) BreakEven {
+	analysis := BreakEven{Table: tableName}
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		analysis.Fail(err)
+		return analysis
+	}
+	datapoints, err := source.Fetch(tableName, metrics.Names, opts.Window)
+	if err != nil {
+		analysis.Fail(err)
+		return analysis
+	}
+	read := metrics.Summarize(datapoints[metrics.ConsumedReadCapacity], opts.Window, opts.Percentile)
+	write := metrics.Summarize(datapoints[metrics.ConsumedWriteCapacity], opts.Window, opts.Percentile)
+
+	analysis.Current = usageOf(table)
+	analysis.Current.ReadRate, analysis.Current.WriteRate = read.Mean, write.Mean
+	analysis.Alternative = analysis.Current
+	if analysis.Current.BillingMode == ModeProvisioned {
+		analysis.Alternative.BillingMode, analysis.Alternative.Rcu, analysis.Alternative.Wcu = ModeOnDemand, 0, 0
+	} else {
+		analysis.Alternative.BillingMode = ModeProvisioned
+		analysis.Alternative.Rcu = recommend.Capacity(read, metrics.Total(datapoints[metrics.ReadThrottleEvents]), opts)
+		analysis.Alternative.Wcu = recommend.Capacity(write, metrics.Total(datapoints[metrics.WriteThrottleEvents]), opts)
+	}
+
+	region := dbmgr.Config.Region
+	current, err := prices.Estimate(region, analysis.Current)
+	if err != nil {
+		analysis.Fail(err)
+		return analysis
+	}
+	alternative, err := prices.Estimate(region, analysis.Alternative)
+	if err != nil {
+		analysis.Fail(err)
+		return analysis
+	}
+	analysis.CurrentCost, analysis.AlternativeCost = current.Total, alternative.Total
+	analysis.Savings = current.Total - alternative.Total
+	analysis.Switch = analysis.Savings > 0 && analysis.Savings >= minSavings
+	if len(table.GlobalSecondaryIndexes) > 0 {
+		analysis.Switch = false
+		analysis.Skipped = fmt.Sprintf("%d global secondary index(es) left out of the estimate", len(table.GlobalSecondaryIndexes))
+	}
+
+	// The storage costs the same in both billing modes, the capacity makes the difference
+	provisioned, onDemand := current.Capacity, alternative.Capacity
+	if analysis.Current.BillingMode == ModeOnDemand {
+		provisioned, onDemand = alternative.Capacity, current.Capacity
+	}
+	if onDemand > 0 {
+		analysis.BreakEvenTraffic = provisioned / onDemand
+	}
+	return analysis
+}
+
+// WriteBreakEven writes a line per table with the cost in both billing modes and whether to switch,
+// followed by the total savings of the flagged switches, or the whole report as JSON.
+func WriteBreakEven(w io.Writer, report BreakEvenReport, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, report)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tCURRENT\tMONTHLY (%s)\tALTERNATIVE\tMONTHLY (%s)\tSAVINGS\tBREAK-EVEN TRAFFIC\tACTION\n", report.Currency, report.Currency)
+	for _, analysis := range report.Tables {
+		if analysis.Error != "" {
+			fmt.Fprintf(tw, "%s\t\t\t\t\t\t\terror: %s\n", analysis.Table, analysis.Error)
+			continue
+		}
+		breakEven, action := "-", "keep"
+		if analysis.BreakEvenTraffic > 0 {
+			breakEven = fmt.Sprintf("x%.2f", analysis.BreakEvenTraffic)
+		}
+		if analysis.Switch {
+			action = "switch"
+		}
+		if analysis.Skipped != "" {
+			action = "skipped: " + analysis.Skipped
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%s\t%.2f\t%.2f\t%s\t%s\n", analysis.Table, describeMode(analysis.Current), analysis.CurrentCost,
+			describeMode(analysis.Alternative), analysis.AlternativeCost, analysis.Savings, breakEven, action)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nMonthly savings of the flagged switches: %.2f %s\n", report.Savings, report.Currency)
+	return nil
+}
+
+// saveManifest writes the manifest of the flagged switches to the file, so it can be applied with --manifest.
+// It returns an error if the file can't be written.
+func saveManifest(dbmgr *client.DynamoDBManager, file string, report BreakEvenReport) error {
+	m := &manifest.Manifest{Region: report.Region}
+	for _, analysis := range report.Tables {
+		if analysis.Switch {
+			m.Updates = append(m.Updates, manifest.Entry{
+				Table:       analysis.Table,
+				BillingMode: analysis.Alternative.BillingMode,
+				Rcu:         analysis.Alternative.Rcu,
+				Wcu:         analysis.Alternative.Wcu,
+			})
+		}
+	}
+	if len(m.Updates) == 0 {
+		dbmgr.Logger.Infof("No switch of billing mode saves money, no manifest written to file:%s", file)
+		return nil
+	}
+
+	header := []string{
+		fmt.Sprintf("Billing mode switches saving %.2f %s per month, from the traffic of %s to %s", report.Savings, report.Currency,
+			report.From.Format(time.RFC3339), report.To.Format(time.RFC3339)),
+		"Apply with: dynamodb-manager --manifest " + file + " [--dry-run]",
+	}
+	if err := manifest.Save(file, m, header); err != nil {
+		return err
+	}
+	dbmgr.Logger.Infof("Manifest of %d switch(es) written to file:%s", len(m.Updates), file)
+	return nil
+}
+
+// ExecuteBreakEven compares the monthly cost of every given table in its current billing mode with the other billing mode,
+// for the traffic observed through the metrics source, and flags the switches saving at least the minimum savings.
+// With a manifest file, the flagged switches are written to it, so they can be applied with --manifest.
+// It takes a DynamoDBManager, the price table, the metrics source, the table names, the recommendation options for the provisioned
+// capacity, the minimum savings, the manifest file and the output format as input.
+// It returns client.ErrPartialFailure if some tables couldn't be analyzed, or the error of the failure if none could.
+func ExecuteBreakEven(dbmgr *client.DynamoDBManager, prices *PriceTable, source metrics.Source, tables []string,
+	opts recommend.Options, minSavings float64, manifestFile string, format string) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	report := BreakEvenReport{
+		Region:   dbmgr.Config.Region,
+		Currency: prices.Currency,
+		From:     opts.Window.Start,
+		To:       opts.Window.End,
+		Tables:   make([]BreakEven, 0, len(tables)),
+	}
+	failed := 0
+	var lastErr error
+	for _, tableName := range tables {
+		analysis := AnalyzeBreakEven(dbmgr, prices, source, tableName, opts, minSavings)
+		if analysis.err != nil {
+			failed, lastErr = failed+1, analysis.err
+		}
+		if analysis.Switch {
+			report.Savings += analysis.Savings
+		}
+		report.Tables = append(report.Tables, analysis)
+	}
+
+	if err := WriteBreakEven(output.Stdout, report, format); err != nil {
+		return err
+	}
+	if manifestFile != "" {
+		if err := saveManifest(dbmgr, manifestFile, report); err != nil {
+			return err
+		}
+	}
+	return outcome.Summary("break-even analysis", "table(s)", failed, len(tables), lastErr)
+}
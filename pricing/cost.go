@@ -0,0 +1,269 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/metrics"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/update"
+)
+
+// DefaultDays is the number of days the request volume of a table is observed over
+const DefaultDays = 14
+
+// TrafficPeriod is the length of the metric periods the request volume is observed in, only its mean is used
+const TrafficPeriod = time.Hour
+
+var DescribeTableClient = client.DescribeTable
+
+// Traffic is the request volume the on-demand cost is estimated for:
+// the supplied rates, or the mean consumed capacity observed through the metrics source over the window.
+type Traffic struct {
+	ReadRate  float64
+	WriteRate float64
+	Supplied  bool
+	Source    metrics.Source
+	Window    metrics.Window
+}
+
+// rates returns the mean read and write request units per second of the table.
+// It returns an error if the consumed capacity can't be fetched.
+func (t Traffic) rates(tableName string) (float64, float64, error) {
+	if t.Supplied {
+		return t.ReadRate, t.WriteRate, nil
+	}
+	datapoints, err := t.Source.Fetch(tableName, []string{metrics.ConsumedReadCapacity, metrics.ConsumedWriteCapacity}, t.Window)
+	if err != nil {
+		return 0, 0, err
+	}
+	read := metrics.Summarize(datapoints[metrics.ConsumedReadCapacity], t.Window, 100)
+	write := metrics.Summarize(datapoints[metrics.ConsumedWriteCapacity], t.Window, 100)
+	return read.Mean, write.Mean, nil
+}
+
+// usageOf returns the billing mode, capacity, table class and storage of the table described.
+func usageOf(table *types.TableDescription) Usage {
+	usage := Usage{
+		TableClass:   string(types.TableClassStandard),
+		BillingMode:  ModeProvisioned,
+		StorageBytes: aws.ToInt64(table.TableSizeBytes),
+	}
+	if table.TableClassSummary != nil && table.TableClassSummary.TableClass != "" {
+		usage.TableClass = string(table.TableClassSummary.TableClass)
+	}
+	if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode != "" {
+		usage.BillingMode = string(table.BillingModeSummary.BillingMode)
+	}
+	if usage.BillingMode == ModeProvisioned && table.ProvisionedThroughput != nil {
+		usage.Rcu = aws.ToInt64(table.ProvisionedThroughput.ReadCapacityUnits)
+		usage.Wcu = aws.ToInt64(table.ProvisionedThroughput.WriteCapacityUnits)
+	}
+	return usage
+}
+
+// describeMode describes the billing mode, capacity and table class of a usage.
+func describeMode(usage Usage) string {
+	if usage.BillingMode == ModeProvisioned {
+		return fmt.Sprintf("%s %d/%d %s", usage.BillingMode, usage.Rcu, usage.Wcu, usage.TableClass)
+	}
+	return fmt.Sprintf("%s %s", usage.BillingMode, usage.TableClass)
+}
+
+// TableCost is the estimated monthly cost of a table in its current billing mode, and in the other billing mode.
+type TableCost struct {
+	Table       string    `json:"table"`
+	Region      string    `json:"region"`
+	Currency    string    `json:"currency"`
+	Usage       Usage     `json:"usage"`
+	Provisioned *Estimate `json:"provisioned,omitempty"` // At the current capacity, provisioned tables only
+	OnDemand    *Estimate `json:"onDemand,omitempty"`    // For the request volume
+	Monthly     float64   `json:"monthly"`               // In the current billing mode
+	Error       string    `json:"error,omitempty"`
+	err         error
+}
+
+// Fail marks the estimation as failed with the given error.
+func (c *TableCost) Fail(err error) {
+	c.Error = err.Error()
+	c.err = err
+}
+
+// EstimateTable estimates the monthly cost of a table from its description and request volume.
+// The estimation is marked as failed if the table can't be described, the request volume fetched or the prices found.
+func EstimateTable(dbmgr *client.DynamoDBManager, prices *PriceTable, tableName string, traffic Traffic) TableCost {
+	cost := TableCost{Table: tableName, Region: dbmgr.Config.Region, Currency: prices.Currency}
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		cost.Fail(err)
+		return cost
+	}
+	cost.Usage = usageOf(table)
+	if cost.Usage.ReadRate, cost.Usage.WriteRate, err = traffic.rates(tableName); err != nil {
+		cost.Fail(err)
+		return cost
+	}
+
+	onDemand := cost.Usage
+	onDemand.BillingMode, onDemand.Rcu, onDemand.Wcu = ModeOnDemand, 0, 0
+	estimate, err := prices.Estimate(cost.Region, onDemand)
+	if err != nil {
+		cost.Fail(err)
+		return cost
+	}
+	cost.OnDemand, cost.Monthly = &estimate, estimate.Total
+
+	if cost.Usage.BillingMode == ModeProvisioned {
+		provisioned, err := prices.Estimate(cost.Region, cost.Usage)
+		if err != nil {
+			cost.Fail(err)
+			return cost
+		}
+		cost.Provisioned, cost.Monthly = &provisioned, provisioned.Total
+	}
+	return cost
+}
+
+// formatCost formats the capacity cost of an estimate, - if there is none.
+func formatCost(estimate *Estimate) string {
+	if estimate == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", estimate.Capacity)
+}
+
+// WriteCosts writes the estimated monthly costs of the tables in the given output format,
+// the provisioned and on-demand columns holding the cost of the capacity or requests, without the storage.
+func WriteCosts(w io.Writer, costs []TableCost, currency string, format string) error {
+	if format == output.JSON {
+		if costs == nil {
+			costs = []TableCost{}
+		}
+		return output.WriteJSON(w, costs)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tCLASS\tMODE\tRCU/WCU\tSIZE\tREAD/WRITE UNITS/S\tPROVISIONED\tON-DEMAND\tSTORAGE\tMONTHLY (%s)\tDETAILS\n", currency)
+	for _, cost := range costs {
+		if cost.Error != "" {
+			fmt.Fprintf(tw, "%s\t\t\t\t\t\t\t\t\t\t%s\n", cost.Table, cost.Error)
+			continue
+		}
+		capacity := "-"
+		if cost.Usage.BillingMode == ModeProvisioned {
+			capacity = fmt.Sprintf("%d/%d", cost.Usage.Rcu, cost.Usage.Wcu)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%.2f/%.2f\t%s\t%s\t%.2f\t%.2f\t\n", cost.Table, cost.Usage.TableClass, cost.Usage.BillingMode,
+			capacity, output.FormatBytes(cost.Usage.StorageBytes), cost.Usage.ReadRate, cost.Usage.WriteRate,
+			formatCost(cost.Provisioned), formatCost(cost.OnDemand), cost.OnDemand.Storage, cost.Monthly)
+	}
+	return tw.Flush()
+}
+
+// ExecuteCost writes the estimated monthly cost of every given table in its current billing mode, along with the cost of its
+// provisioned capacity and the cost of its request volume in on-demand mode.
+// It takes a DynamoDBManager, the price table, the table names, the request volume and the output format as input.
+// It returns client.ErrPartialFailure if the cost of some tables couldn't be estimated, or the error of the failure if none could.
+func ExecuteCost(dbmgr *client.DynamoDBManager, prices *PriceTable, tables []string, traffic Traffic, format string) error {
+	costs := make([]TableCost, 0, len(tables))
+	failed := 0
+	var lastErr error
+	for _, tableName := range tables {
+		cost := EstimateTable(dbmgr, prices, tableName, traffic)
+		if cost.err != nil {
+			failed++
+			lastErr = cost.err
+		}
+		costs = append(costs, cost)
+	}
+
+	if err := WriteCosts(output.Stdout, costs, prices.Currency, format); err != nil {
+		return err
+	}
+	return outcome.Summary("cost estimation", "table(s)", failed, len(costs), lastErr)
+}
+
+// Change is a change of the billing mode, capacity or table class of a table, as given to the update.
+type Change struct {
+	Rcu         string
+	Wcu         string
+	OnDemand    bool
+	Provisioned bool
+	TableClass  string
+}
+
+// apply returns the usage of the table once the change is applied, as the dry run of update.ExecuteUpdate reports it:
+// the capacity not given defaults to client.DefaultRcu and client.DefaultWcu, and a provisioned table is left as it is without capacity.
+// It returns an error if the capacity isn't a number, or wrapping update.ErrInvalidModeSwitch if the capacity of an on-demand table is given
+// without switching it to provisioned.
+func (c Change) apply(usage Usage) (Usage, error) {
+	after := usage
+	if c.TableClass != "" {
+		after.TableClass = c.TableClass
+	}
+	switch {
+	case c.OnDemand:
+		after.BillingMode, after.Rcu, after.Wcu = ModeOnDemand, 0, 0
+	case (c.Rcu != "" || c.Wcu != "") && !c.Provisioned && usage.BillingMode != ModeProvisioned:
+		return after, fmt.Errorf("billing mode:%s - %w", usage.BillingMode, update.ErrInvalidModeSwitch)
+	case c.Rcu != "" || c.Wcu != "" || (c.Provisioned && usage.BillingMode != ModeProvisioned):
+		after.BillingMode, after.Rcu, after.Wcu = ModeProvisioned, client.DefaultRcu, client.DefaultWcu
+		var err error
+		if c.Rcu != "" {
+			if after.Rcu, err = strconv.ParseInt(c.Rcu, 10, 64); err != nil {
+				return after, errors.New(fmt.Sprintf("invalid rcu:%s - error:%v", c.Rcu, err))
+			}
+		}
+		if c.Wcu != "" {
+			if after.Wcu, err = strconv.ParseInt(c.Wcu, 10, 64); err != nil {
+				return after, errors.New(fmt.Sprintf("invalid wcu:%s - error:%v", c.Wcu, err))
+			}
+		}
+	}
+	return after, nil
+}
+
+// ExecuteEstimateChange logs the estimated monthly cost of a table before and after the change of its billing mode,
+// capacity or table class, e.g. for the dry run of an update.
+// It takes a DynamoDBManager, the price table, the table name, the change and the request volume as input,
+// the request volume being fetched only if the table is or would be on-demand.
+// It returns an error if the table can't be described, the request volume fetched or the prices found.
+func ExecuteEstimateChange(dbmgr *client.DynamoDBManager, prices *PriceTable, tableName string, change Change, traffic Traffic) error {
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		return err
+	}
+	before := usageOf(table)
+	after, err := change.apply(before)
+	if err != nil {
+		return err
+	}
+	if before.BillingMode == ModeOnDemand || after.BillingMode == ModeOnDemand {
+		if before.ReadRate, before.WriteRate, err = traffic.rates(tableName); err != nil {
+			return fmt.Errorf("request volume of table:%s can't be observed - %w", tableName, err)
+		}
+		after.ReadRate, after.WriteRate = before.ReadRate, before.WriteRate
+	}
+
+	region := dbmgr.Config.Region
+	estimateBefore, err := prices.Estimate(region, before)
+	if err != nil {
+		return err
+	}
+	estimateAfter, err := prices.Estimate(region, after)
+	if err != nil {
+		return err
+	}
+	dbmgr.Logger.Infof("Estimated monthly cost of table:%s - %s: %.2f %s -> %s: %.2f %s (%+.2f %s)", tableName,
+		describeMode(before), estimateBefore.Total, prices.Currency, describeMode(after), estimateAfter.Total, prices.Currency,
+		estimateAfter.Total-estimateBefore.Total, prices.Currency)
+	return nil
+}
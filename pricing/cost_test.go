@@ -0,0 +1,55 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/update"
+)
+
+func TestChangeApply(t *testing.T) {
+	provisioned := Usage{TableClass: "STANDARD", BillingMode: ModeProvisioned, Rcu: 50, Wcu: 20}
+	onDemand := Usage{TableClass: "STANDARD", BillingMode: ModeOnDemand}
+	defaults := Usage{TableClass: "STANDARD", BillingMode: ModeProvisioned, Rcu: client.DefaultRcu, Wcu: client.DefaultWcu}
+
+	tests := []struct {
+		name   string
+		change Change
+		usage  Usage
+		want   Usage
+	}{
+		{"to on-demand", Change{OnDemand: true}, provisioned, onDemand},
+		{"already on-demand", Change{OnDemand: true}, onDemand, onDemand},
+		{"to provisioned with the default capacity", Change{Provisioned: true}, onDemand, defaults},
+		{"already provisioned", Change{Provisioned: true}, provisioned, provisioned},
+		{"to provisioned with the capacity", Change{Provisioned: true, Rcu: "100", Wcu: "40"}, onDemand,
+			Usage{TableClass: "STANDARD", BillingMode: ModeProvisioned, Rcu: 100, Wcu: 40}},
+		{"read capacity only", Change{Rcu: "100"}, provisioned,
+			Usage{TableClass: "STANDARD", BillingMode: ModeProvisioned, Rcu: 100, Wcu: client.DefaultWcu}},
+		{"table class only", Change{TableClass: "STANDARD_INFREQUENT_ACCESS"}, provisioned,
+			Usage{TableClass: "STANDARD_INFREQUENT_ACCESS", BillingMode: ModeProvisioned, Rcu: 50, Wcu: 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.change.apply(tt.usage)
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeApplyErrors(t *testing.T) {
+	onDemand := Usage{BillingMode: ModeOnDemand}
+	if _, err := (Change{Rcu: "100"}).apply(onDemand); !errors.Is(err, update.ErrInvalidModeSwitch) {
+		t.Errorf("apply() of the capacity of an on-demand table error = %v, want update.ErrInvalidModeSwitch", err)
+	}
+	if _, err := (Change{Provisioned: true, Wcu: "ten"}).apply(onDemand); err == nil {
+		t.Error("apply() of an invalid capacity returned no error")
+	}
+}
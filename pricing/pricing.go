@@ -0,0 +1,138 @@
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// HoursPerMonth is the number of hours a month is billed for
+const HoursPerMonth = 730
+
+// bytesPerGB is the size of a GB of storage as billed
+const bytesPerGB = 1 << 30
+
+// Billing modes of a table
+const (
+	ModeProvisioned string = "PROVISIONED"
+	ModeOnDemand    string = "PAY_PER_REQUEST"
+)
+
+// embeddedPrices holds the list prices of the main regions at the time of writing, a price file can be supplied for the others
+//
+//go:embed prices.json
+var embeddedPrices []byte
+
+// Prices are the prices of a table class in a region, in the currency of the price table.
+type Prices struct {
+	ReadCapacityUnitHour        float64 `json:"readCapacityUnitHour"`
+	WriteCapacityUnitHour       float64 `json:"writeCapacityUnitHour"`
+	ReadRequestUnitsPerMillion  float64 `json:"readRequestUnitsPerMillion"`
+	WriteRequestUnitsPerMillion float64 `json:"writeRequestUnitsPerMillion"`
+	StorageGBMonth              float64 `json:"storageGBMonth"`
+}
+
+// PriceTable holds the prices of every table class by region.
+type PriceTable struct {
+	Currency string                       `json:"currency"`
+	Updated  string                       `json:"updated,omitempty"`
+	Regions  map[string]map[string]Prices `json:"regions"`
+}
+
+// Parse decodes a price table, the source naming it in the errors.
+// It returns the price table and an error if it can't be decoded or a table class or price is invalid.
+func Parse(content []byte, source string) (*PriceTable, error) {
+	var table PriceTable
+	if err := json.Unmarshal(content, &table); err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to decode price table:%s - error:%v", source, err))
+	}
+	if table.Currency == "" || len(table.Regions) == 0 {
+		return nil, errors.New(fmt.Sprintf("invalid price table:%s, the currency and the prices of a region at least are expected", source))
+	}
+	for region, classes := range table.Regions {
+		for class, prices := range classes {
+			if class != string(types.TableClassStandard) && class != string(types.TableClassStandardInfrequentAccess) {
+				return nil, errors.New(fmt.Sprintf("invalid price table:%s, unknown table class:%s of region:%s", source, class, region))
+			}
+			if prices.ReadCapacityUnitHour < 0 || prices.WriteCapacityUnitHour < 0 || prices.ReadRequestUnitsPerMillion < 0 ||
+				prices.WriteRequestUnitsPerMillion < 0 || prices.StorageGBMonth < 0 {
+				return nil, errors.New(fmt.Sprintf("invalid price table:%s, negative price of table class:%s in region:%s", source, class, region))
+			}
+		}
+	}
+	return &table, nil
+}
+
+// Load reads the price table from the given file, or the embedded one if no file is given.
+// It returns the price table and an error if the file can't be read or the price table is invalid.
+func Load(file string) (*PriceTable, error) {
+	if file == "" {
+		return Parse(embeddedPrices, "embedded")
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read price file:%s - error:%v", file, err))
+	}
+	return Parse(content, file)
+}
+
+// Lookup returns the prices of the table class in the region, the STANDARD class if none is given.
+// It returns an error if the price table has no prices for them.
+func (t *PriceTable) Lookup(region string, tableClass string) (Prices, error) {
+	if tableClass == "" {
+		tableClass = string(types.TableClassStandard)
+	}
+	classes, exists := t.Regions[region]
+	if !exists {
+		return Prices{}, errors.New(fmt.Sprintf("no prices of region:%s, a price file can be supplied with --price-file", region))
+	}
+	prices, exists := classes[tableClass]
+	if !exists {
+		return Prices{}, errors.New(fmt.Sprintf("no prices of table class:%s in region:%s", tableClass, region))
+	}
+	return prices, nil
+}
+
+// Usage is what a table is billed for over a month.
+type Usage struct {
+	TableClass   string  `json:"tableClass"`
+	BillingMode  string  `json:"billingMode"`
+	Rcu          int64   `json:"rcu,omitempty"` // Provisioned mode only
+	Wcu          int64   `json:"wcu,omitempty"` // Provisioned mode only
+	ReadRate     float64 `json:"readRate"`      // Mean read request units per second, billed in on-demand mode
+	WriteRate    float64 `json:"writeRate"`     // Mean write request units per second, billed in on-demand mode
+	StorageBytes int64   `json:"storageBytes"`
+}
+
+// Estimate is the monthly cost of a table, in the currency of the price table.
+type Estimate struct {
+	Capacity float64 `json:"capacity"` // Provisioned capacity or request units, depending on the billing mode
+	Storage  float64 `json:"storage"`
+	Total    float64 `json:"total"`
+}
+
+// Estimate computes the monthly cost of the usage of a table in the region.
+// It returns the estimate and an error if the price table has no prices for the region and table class.
+func (t *PriceTable) Estimate(region string, usage Usage) (Estimate, error) {
+	prices, err := t.Lookup(region, usage.TableClass)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	var estimate Estimate
+	if usage.BillingMode == ModeOnDemand {
+		seconds := float64(HoursPerMonth * 3600)
+		estimate.Capacity = usage.ReadRate*seconds/1e6*prices.ReadRequestUnitsPerMillion +
+			usage.WriteRate*seconds/1e6*prices.WriteRequestUnitsPerMillion
+	} else {
+		estimate.Capacity = float64(usage.Rcu)*HoursPerMonth*prices.ReadCapacityUnitHour +
+			float64(usage.Wcu)*HoursPerMonth*prices.WriteCapacityUnitHour
+	}
+	estimate.Storage = float64(usage.StorageBytes) / bytesPerGB * prices.StorageGBMonth
+	estimate.Total = estimate.Capacity + estimate.Storage
+	return estimate, nil
+}
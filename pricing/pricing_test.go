@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	if _, err := Load(""); err != nil {
+		t.Errorf("Load() of the embedded prices error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string // Part of the error message
+	}{
+		{"malformed", `{"currency":`, "Failed to decode"},
+		{"no region", `{"currency":"USD","regions":{}}`, "the currency and the prices of a region"},
+		{"unknown class", `{"currency":"USD","regions":{"eu-west-1":{"COLD":{}}}}`, "unknown table class"},
+		{"negative price", `{"currency":"USD","regions":{"eu-west-1":{"STANDARD":{"storageGBMonth":-1}}}}`, "negative price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.content), "test"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	prices := &PriceTable{Currency: "USD", Regions: map[string]map[string]Prices{
+		"eu-west-1": {"STANDARD": {
+			ReadCapacityUnitHour:        0.0001,
+			WriteCapacityUnitHour:       0.0005,
+			ReadRequestUnitsPerMillion:  0.25,
+			WriteRequestUnitsPerMillion: 1.25,
+			StorageGBMonth:              0.25,
+		}},
+	}}
+
+	tests := []struct {
+		name  string
+		usage Usage
+		want  Estimate
+	}{
+		// 10 × 730 × 0.0001 + 2 × 730 × 0.0005, 4 GB × 0.25
+		{"provisioned", Usage{BillingMode: ModeProvisioned, Rcu: 10, Wcu: 2, StorageBytes: 4 << 30}, Estimate{Capacity: 1.46, Storage: 1, Total: 2.46}},
+		// 2,628,000 seconds a month: 2.628M reads × 0.25 + 0.2628M writes × 1.25
+		{"on-demand", Usage{BillingMode: ModeOnDemand, ReadRate: 1, WriteRate: 0.1}, Estimate{Capacity: 0.9855, Total: 0.9855}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prices.Estimate("eu-west-1", tt.usage)
+			if err != nil {
+				t.Fatalf("Estimate() error = %v", err)
+			}
+			if math.Abs(got.Capacity-tt.want.Capacity) > 1e-9 || math.Abs(got.Storage-tt.want.Storage) > 1e-9 || math.Abs(got.Total-tt.want.Total) > 1e-9 {
+				t.Errorf("Estimate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := prices.Estimate("us-east-1", Usage{BillingMode: ModeOnDemand}); err == nil {
+		t.Error("Estimate() in a region without prices returned no error")
+	}
+	if _, err := prices.Estimate("eu-west-1", Usage{TableClass: "STANDARD_INFREQUENT_ACCESS", BillingMode: ModeOnDemand}); err == nil {
+		t.Error("Estimate() of a table class without prices returned no error")
+	}
+}
@@ -0,0 +1,80 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrAborted is returned when the user doesn't confirm an operation.
+var ErrAborted = errors.New("aborted by the user")
+
+// Stdin is where the answers are read from and Stderr where the questions are written to,
+// the results of the commands written to stdout stay free of prompts
+var (
+	Stdin  io.Reader = os.Stdin
+	Stderr io.Writer = os.Stderr
+)
+
+var reader *bufio.Reader
+
+// readLine writes the question and reads the answer, without the trailing newline.
+func readLine(question string) (string, error) {
+	if reader == nil {
+		reader = bufio.NewReader(Stdin)
+	}
+	fmt.Fprint(Stderr, question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// Confirm asks a yes/no question, it returns true only if the user answers "y" or "yes".
+func Confirm(question string) bool {
+	answer, err := readLine(question + " [y/N]: ")
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// ConfirmByTyping asks the user to type the expected text, e.g. a table name, to confirm a destructive operation.
+// It returns true only if the typed text matches exactly.
+func ConfirmByTyping(question string, expected string) bool {
+	answer, err := readLine(fmt.Sprintf("%s\nType %q to confirm: ", question, expected))
+	if err != nil {
+		return false
+	}
+	return answer == expected
+}
+
+// Approve confirms an operation unless 'yes' is set, it returns ErrAborted if the user declines.
+func Approve(yes bool, question string) error {
+	if yes || Confirm(question) {
+		return nil
+	}
+	return ErrAborted
+}
+
+// ShowPlan writes the plan of an operation to Stderr along with its confirmation,
+// so the results written to stdout stay in the requested output format.
+func ShowPlan(plan func(w io.Writer) error) error {
+	return plan(Stderr)
+}
+
+// ApprovePlan shows the plan of an operation and confirms the operation, unless 'yes' is set.
+// It returns the error of writing the plan, or ErrAborted if the user declines.
+func ApprovePlan(yes bool, question string, plan func(w io.Writer) error) error {
+	if !yes {
+		if err := ShowPlan(plan); err != nil {
+			return err
+		}
+	}
+	return Approve(yes, question)
+}
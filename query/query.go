@@ -0,0 +1,318 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/items"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+var (
+	GetItemClient   = client.GetItem
+	QueryPageClient = client.QueryPage
+	ScanPageClient  = client.ScanPage
+)
+
+// Options describes a read of the items of a table.
+type Options struct {
+	Table          string
+	Key            string   // Primary key of the item to get, as a plain JSON object
+	KeyCondition   string   // Key condition expression of a query
+	Filter         string   // Filter expression of a query or scan
+	Names          []string // Expression attribute names as "#placeholder=name"
+	Values         string   // Expression attribute values as a plain JSON object, e.g. {":id":"a"}
+	Index          string   // Index to query or scan instead of the table
+	ConsistentRead bool
+	Projection     []string // Attributes or document paths to return, e.g. "a.b[0]", all if empty
+	Limit          int      // Items to return at most, all if 0
+	Descending     bool     // Query the sort key in descending order
+}
+
+// expressions are the parsed expression attribute names and values with the projection expression.
+type expressions struct {
+	names      map[string]string
+	values     items.Item
+	projection *string
+}
+
+// parseExpressions parses the expression attribute names and values, and builds the projection expression.
+// The elements of the projected document paths, e.g. "a.b[0]", get their own "#pN" placeholders, so reserved words can be projected,
+// skipping the placeholders given in the names.
+// It returns the expressions and an error if a name or the values are malformed.
+func parseExpressions(opts *Options) (expressions, error) {
+	var exprs expressions
+	for _, entry := range opts.Names {
+		idx := strings.Index(entry, "=")
+		if idx <= 1 || !strings.HasPrefix(entry, "#") || idx == len(entry)-1 {
+			return exprs, errors.New(fmt.Sprintf("invalid expression attribute name:%s, #placeholder=name is expected", entry))
+		}
+		if exprs.names == nil {
+			exprs.names = map[string]string{}
+		}
+		exprs.names[entry[:idx]] = entry[idx+1:]
+	}
+
+	if opts.Values != "" {
+		values, err := items.Unmarshal([]byte(opts.Values), items.JSONL)
+		if err != nil {
+			return exprs, errors.New(fmt.Sprintf("invalid expression attribute values:%s - error:%v", opts.Values, err))
+		}
+		for placeholder := range values {
+			if !strings.HasPrefix(placeholder, ":") {
+				return exprs, errors.New(fmt.Sprintf("invalid expression attribute value placeholder:%s, it must start with ':'", placeholder))
+			}
+		}
+		exprs.values = values
+	}
+
+	if len(opts.Projection) > 0 {
+		if exprs.names == nil {
+			exprs.names = map[string]string{}
+		}
+		placeholders := map[string]string{}
+		next := 0
+		paths := make([]string, 0, len(opts.Projection))
+		for _, path := range opts.Projection {
+			elements := strings.Split(path, ".")
+			for idx, element := range elements {
+				// The list indexes of the element, e.g. "[0]", stay as they are
+				name, indexes := element, ""
+				if i := strings.Index(element, "["); i >= 0 {
+					name, indexes = element[:i], element[i:]
+				}
+				if name == "" {
+					return exprs, errors.New(fmt.Sprintf("invalid projection:%s, attribute or document path is expected", path))
+				}
+				placeholder, exists := placeholders[name]
+				if !exists {
+					for placeholder = fmt.Sprintf("#p%d", next); exprs.names[placeholder] != ""; placeholder = fmt.Sprintf("#p%d", next) {
+						next++
+					}
+					next++
+					placeholders[name] = placeholder
+					exprs.names[placeholder] = name
+				}
+				elements[idx] = placeholder + indexes
+			}
+			paths = append(paths, strings.Join(elements, "."))
+		}
+		exprs.projection = aws.String(strings.Join(paths, ", "))
+	}
+	return exprs, nil
+}
+
+// projectedAttributes returns the top-level attributes of the projected document paths, in the order of the projection.
+func projectedAttributes(projection []string) []string {
+	var attributes []string
+	seen := map[string]bool{}
+	for _, path := range projection {
+		attribute := strings.SplitN(path, ".", 2)[0]
+		if i := strings.Index(attribute, "["); i >= 0 {
+			attribute = attribute[:i]
+		}
+		if !seen[attribute] {
+			seen[attribute] = true
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// optionalString returns nil for an empty string, so unset expressions are left out of the requests.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// Validate checks the read options.
+// It returns an error if the limit is negative or the expressions are malformed.
+func (o *Options) Validate() error {
+	if o.Table == "" {
+		return errors.New("table must be provided!")
+	}
+	if o.Limit < 0 {
+		return errors.New(fmt.Sprintf("limit must not be negative:%d", o.Limit))
+	}
+	_, err := parseExpressions(o)
+	return err
+}
+
+// WriteItems writes the items in the given output format: a JSON array of plain items, or a table with a column per attribute.
+// The columns follow the projection if any, otherwise the attribute names are sorted.
+func WriteItems(w io.Writer, results []items.Item, projection []string, format string) error {
+	if format == output.JSON {
+		plain := make([]map[string]interface{}, 0, len(results))
+		for _, item := range results {
+			plain = append(plain, items.ToPlainItem(item))
+		}
+		return output.WriteJSON(w, plain)
+	}
+
+	columns := projection
+	if len(columns) == 0 {
+		columns = items.AttributeNames(results)
+	}
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "%s\n", strings.Join(columns, "\t"))
+	for _, item := range results {
+		cells := make([]string, 0, len(columns))
+		for _, name := range columns {
+			cells = append(cells, items.FormatCell(item[name]))
+		}
+		fmt.Fprintf(tw, "%s\n", strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeResults writes the items read, it returns client.ErrNoMatches if there are none.
+func writeResults(results []items.Item, opts *Options, format string) error {
+	if err := WriteItems(output.Stdout, results, projectedAttributes(opts.Projection), format); err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return client.ErrNoMatches
+	}
+	return nil
+}
+
+// ExecuteGet reads an item by its primary key and writes it in the given output format.
+// It takes a DynamoDBManager, the read options, with the key as a plain JSON object, and the output format as input.
+// It returns client.ErrNoMatches if the item doesn't exist, or an error if the read fails.
+func ExecuteGet(dbmgr *client.DynamoDBManager, opts Options, format string) error {
+	exprs, err := parseExpressions(&opts)
+	if err != nil {
+		return err
+	}
+	key, err := items.Unmarshal([]byte(opts.Key), items.JSONL)
+	if err != nil || len(key) == 0 {
+		return errors.New(fmt.Sprintf("invalid key:%s, a plain JSON object is expected, e.g. {\"id\":\"a\"}", opts.Key))
+	}
+
+	result, err := GetItemClient(dbmgr, &dynamodb.GetItemInput{
+		TableName:                aws.String(opts.Table),
+		Key:                      key,
+		ConsistentRead:           aws.Bool(opts.ConsistentRead),
+		ProjectionExpression:     exprs.projection,
+		ExpressionAttributeNames: exprs.names,
+	})
+	if err != nil {
+		return err
+	}
+
+	var results []items.Item
+	if len(result.Item) > 0 {
+		results = append(results, result.Item)
+	}
+	return writeResults(results, &opts, format)
+}
+
+// ExecuteQuery queries a table or an index with a key condition and writes the items in the given output format.
+// It takes a DynamoDBManager, the read options and the output format as input.
+// The pages are read until the results are exhausted or the limit is reached.
+// It returns client.ErrNoMatches if no item matches, or an error if the query fails.
+func ExecuteQuery(dbmgr *client.DynamoDBManager, opts Options, format string) error {
+	if opts.KeyCondition == "" {
+		return errors.New("key condition must be provided!")
+	}
+	exprs, err := parseExpressions(&opts)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(opts.Table),
+		IndexName:                 optionalString(opts.Index),
+		KeyConditionExpression:    aws.String(opts.KeyCondition),
+		FilterExpression:          optionalString(opts.Filter),
+		ProjectionExpression:      exprs.projection,
+		ExpressionAttributeNames:  exprs.names,
+		ExpressionAttributeValues: exprs.values,
+		ConsistentRead:            aws.Bool(opts.ConsistentRead),
+		ScanIndexForward:          aws.Bool(!opts.Descending),
+	}
+
+	var results []items.Item
+	for {
+		input.Limit = pageLimit(&opts, len(results))
+		page, err := QueryPageClient(dbmgr, input)
+		if err != nil {
+			return err
+		}
+		results = append(results, page.Items...)
+		dbmgr.Logger.Debugf("Query of table:%s - read %d item(s) of the page, %d in total", opts.Table, len(page.Items), len(results))
+		if len(page.LastEvaluatedKey) == 0 || limitReached(&opts, results) {
+			break
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+	return writeResults(truncate(&opts, results), &opts, format)
+}
+
+// ExecuteScan scans a table or an index, optionally filtered, and writes the items in the given output format.
+// It takes a DynamoDBManager, the read options and the output format as input.
+// The pages are read until the results are exhausted or the limit is reached.
+// It returns client.ErrNoMatches if no item matches, or an error if the scan fails.
+func ExecuteScan(dbmgr *client.DynamoDBManager, opts Options, format string) error {
+	exprs, err := parseExpressions(&opts)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(opts.Table),
+		IndexName:                 optionalString(opts.Index),
+		FilterExpression:          optionalString(opts.Filter),
+		ProjectionExpression:      exprs.projection,
+		ExpressionAttributeNames:  exprs.names,
+		ExpressionAttributeValues: exprs.values,
+		ConsistentRead:            aws.Bool(opts.ConsistentRead),
+	}
+
+	var results []items.Item
+	for {
+		input.Limit = pageLimit(&opts, len(results))
+		page, err := ScanPageClient(context.Background(), dbmgr, input)
+		if err != nil {
+			return err
+		}
+		results = append(results, page.Items...)
+		dbmgr.Logger.Debugf("Scan of table:%s - read %d item(s) of the page, %d in total", opts.Table, len(page.Items), len(results))
+		if len(page.LastEvaluatedKey) == 0 || limitReached(&opts, results) {
+			break
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+	return writeResults(truncate(&opts, results), &opts, format)
+}
+
+// pageLimit returns the number of items to evaluate in the next page, nil for a full page.
+// DynamoDB applies the page limit before the filter, so a filtered read always gets full pages.
+func pageLimit(opts *Options, read int) *int32 {
+	if opts.Limit == 0 || opts.Filter != "" {
+		return nil
+	}
+	return aws.Int32(int32(opts.Limit - read))
+}
+
+// limitReached reports whether enough items were read.
+func limitReached(opts *Options, results []items.Item) bool {
+	return opts.Limit > 0 && len(results) >= opts.Limit
+}
+
+// truncate drops the items read beyond the limit.
+func truncate(opts *Options, results []items.Item) []items.Item {
+	if limitReached(opts, results) {
+		return results[:opts.Limit]
+	}
+	return results
+}
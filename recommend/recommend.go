@@ -0,0 +1,294 @@
+package recommend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/metrics"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/update"
+)
+
+// Defaults of the recommendation
+const (
+	DefaultDays           = 14
+	DefaultPeriod         = 5 * time.Minute
+	DefaultPercentile     = 95.0
+	DefaultHeadroom       = 0.2
+	DefaultMinUtilization = 0.2
+)
+
+// Billing modes of a table
+const (
+	ModeProvisioned string = "PROVISIONED"
+	ModeOnDemand    string = "PAY_PER_REQUEST"
+)
+
+// Statuses of a recommendation
+const (
+	StatusUnchanged string = "unchanged"
+	StatusPending   string = "pending"
+	StatusApplied   string = "applied"
+	StatusSkipped   string = "skipped"
+	StatusFailed           = outcome.StatusFailed
+)
+
+var (
+	DescribeTableClient = client.DescribeTable
+	ExecuteUpdateTask   = update.ExecuteUpdate
+)
+
+// Options tunes how the capacity is derived from the consumed capacity.
+type Options struct {
+	Window         metrics.Window
+	Percentile     float64 // Percentile of the consumed capacity per second the capacity is based on
+	Headroom       float64 // Share of capacity added on top of the percentile, e.g. 0.2 for 20%
+	MinUtilization float64 // Mean utilization of the capacity absorbing the peaks below which on-demand is recommended
+}
+
+// Validate checks the window, the percentile, the headroom and the utilization threshold.
+// It returns an error if any of them is out of range.
+func (o *Options) Validate() error {
+	if err := o.Window.Validate(); err != nil {
+		return err
+	}
+	if o.Percentile <= 0 || o.Percentile > 100 {
+		return errors.New(fmt.Sprintf("invalid percentile:%g, a value above 0 and up to 100 is expected", o.Percentile))
+	}
+	if o.Headroom < 0 {
+		return errors.New(fmt.Sprintf("invalid headroom:%g, a positive value is expected", o.Headroom))
+	}
+	if o.MinUtilization < 0 || o.MinUtilization > 1 {
+		return errors.New(fmt.Sprintf("invalid utilization:%g, a value between 0 and 1 is expected", o.MinUtilization))
+	}
+	return nil
+}
+
+// Recommendation is the capacity recommended for a table from its consumed capacity, and the outcome of applying it.
+type Recommendation struct {
+	Table          string        `json:"table"`
+	CurrentMode    string        `json:"currentMode,omitempty"`
+	CurrentRcu     string        `json:"currentRcu,omitempty"`
+	CurrentWcu     string        `json:"currentWcu,omitempty"`
+	Read           metrics.Stats `json:"read"`
+	Write          metrics.Stats `json:"write"`
+	ReadThrottles  float64       `json:"readThrottles"`
+	WriteThrottles float64       `json:"writeThrottles"`
+	Mode           string        `json:"mode,omitempty"`
+	Rcu            int64         `json:"rcu,omitempty"`
+	Wcu            int64         `json:"wcu,omitempty"`
+	Reason         string        `json:"reason,omitempty"`
+	Skipped        string        `json:"skipped,omitempty"` // Reason the recommendation isn't applied
+	outcome.Outcome
+	indexes int // Number of global secondary indexes of the table
+}
+
+// Capacity returns the capacity units covering the consumed capacity per second with the headroom, at least 1.
+// The consumed capacity doesn't show the requests that were throttled, so the peak is used instead of the percentile then.
+func Capacity(stats metrics.Stats, throttles float64, opts Options) int64 {
+	base := stats.Percentile
+	if throttles > 0 {
+		base = stats.Max
+	}
+	units := int64(math.Ceil(base * (1 + opts.Headroom)))
+	if units < 1 {
+		units = 1
+	}
+	return units
+}
+
+// utilization returns the share of the capacity absorbing the peaks the traffic would use on average,
+// low for spiky traffic and 0 without traffic.
+func utilization(stats metrics.Stats, opts Options) float64 {
+	if stats.Max == 0 {
+		return 0
+	}
+	return stats.Mean / (stats.Max * (1 + opts.Headroom))
+}
+
+// basis describes what the capacity of the reads or writes is based on.
+func basis(name string, throttles float64, opts Options) string {
+	if throttles > 0 {
+		return "peak of the throttled " + name
+	}
+	return fmt.Sprintf("p%g of the %s", opts.Percentile, name)
+}
+
+// decide sets the recommended mode and capacity from the statistics of the consumed capacity and the throttles.
+// On-demand is recommended for a table without traffic, or whose capacity absorbing the peaks would mostly stay idle.
+// Switching an on-demand table with global secondary indexes to provisioned is skipped, as the update sets no capacity for its indexes.
+func (r *Recommendation) decide(opts Options) {
+	r.Rcu = Capacity(r.Read, r.ReadThrottles, opts)
+	r.Wcu = Capacity(r.Write, r.WriteThrottles, opts)
+	readUtilization := utilization(r.Read, opts)
+	writeUtilization := utilization(r.Write, opts)
+
+	switch {
+	case r.Read.Periods == 0 && r.Write.Periods == 0:
+		r.Mode, r.Rcu, r.Wcu = ModeOnDemand, 0, 0
+		r.Reason = "no consumed capacity over the window"
+	case readUtilization < opts.MinUtilization && writeUtilization < opts.MinUtilization:
+		r.Mode, r.Rcu, r.Wcu = ModeOnDemand, 0, 0
+		r.Reason = fmt.Sprintf("spiky traffic, capacity absorbing the peaks would be used at %.0f%% for reads and %.0f%% for writes",
+			readUtilization*100, writeUtilization*100)
+	default:
+		r.Mode = ModeProvisioned
+		r.Reason = fmt.Sprintf("%s and %s with %.0f%% headroom", basis("reads", r.ReadThrottles, opts), basis("writes", r.WriteThrottles, opts),
+			opts.Headroom*100)
+	}
+
+	switch {
+	case r.Mode == r.CurrentMode && (r.Mode == ModeOnDemand ||
+		(r.CurrentRcu == fmt.Sprintf("%d", r.Rcu) && r.CurrentWcu == fmt.Sprintf("%d", r.Wcu))):
+		r.Status = StatusUnchanged
+	case r.Mode == ModeProvisioned && r.CurrentMode != ModeProvisioned && r.indexes > 0:
+		r.Status = StatusSkipped
+		r.Skipped = fmt.Sprintf("%d global secondary index(es) would be left without capacity", r.indexes)
+	default:
+		r.Status = StatusPending
+	}
+}
+
+// Recommend fetches the consumed capacity and the throttles of a table over the window and recommends its billing mode and capacity.
+// The recommendation is marked as failed if the table or its metrics can't be retrieved.
+func Recommend(dbmgr *client.DynamoDBManager, source metrics.Source, tableName string, opts Options) Recommendation {
+	recommendation := Recommendation{Table: tableName}
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		recommendation.Fail(err)
+		return recommendation
+	}
+	// Tables created before the billing mode was reported are provisioned
+	recommendation.CurrentMode = ModeProvisioned
+	if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode != "" {
+		recommendation.CurrentMode = string(table.BillingModeSummary.BillingMode)
+	}
+	if recommendation.CurrentMode == ModeProvisioned && table.ProvisionedThroughput != nil {
+		recommendation.CurrentRcu = fmt.Sprintf("%d", aws.ToInt64(table.ProvisionedThroughput.ReadCapacityUnits))
+		recommendation.CurrentWcu = fmt.Sprintf("%d", aws.ToInt64(table.ProvisionedThroughput.WriteCapacityUnits))
+	}
+	recommendation.indexes = len(table.GlobalSecondaryIndexes)
+
+	datapoints, err := source.Fetch(tableName, metrics.Names, opts.Window)
+	if err != nil {
+		recommendation.Fail(err)
+		return recommendation
+	}
+	recommendation.Read = metrics.Summarize(datapoints[metrics.ConsumedReadCapacity], opts.Window, opts.Percentile)
+	recommendation.Write = metrics.Summarize(datapoints[metrics.ConsumedWriteCapacity], opts.Window, opts.Percentile)
+	recommendation.ReadThrottles = metrics.Total(datapoints[metrics.ReadThrottleEvents])
+	recommendation.WriteThrottles = metrics.Total(datapoints[metrics.WriteThrottleEvents])
+	recommendation.decide(opts)
+	dbmgr.Logger.Debugf("Table:%s - read:%+v - write:%+v - throttles read:%g write:%g - recommended:%s RCU:%d WCU:%d",
+		tableName, recommendation.Read, recommendation.Write, recommendation.ReadThrottles, recommendation.WriteThrottles,
+		recommendation.Mode, recommendation.Rcu, recommendation.Wcu)
+	return recommendation
+}
+
+// apply updates the table to the recommended billing mode and capacity.
+// It returns the error of the update.
+func (r *Recommendation) apply(dbmgr *client.DynamoDBManager) error {
+	if r.Mode == ModeOnDemand {
+		return ExecuteUpdateTask(dbmgr, r.Table, "", "", true, false, false)
+	}
+	return ExecuteUpdateTask(dbmgr, r.Table, fmt.Sprintf("%d", r.Rcu), fmt.Sprintf("%d", r.Wcu), false,
+		r.CurrentMode != ModeProvisioned, false)
+}
+
+// modeSummary describes a billing mode with its capacity.
+func modeSummary(mode string, rcu string, wcu string) string {
+	if mode == ModeProvisioned {
+		return fmt.Sprintf("%s %s/%s", mode, rcu, wcu)
+	}
+	return mode
+}
+
+// WriteRecommendations writes the recommendations in the given output format,
+// the consumed capacity being shown as the percentile and the peak per second.
+func WriteRecommendations(w io.Writer, recommendations []Recommendation, format string) error {
+	if format == output.JSON {
+		if recommendations == nil {
+			recommendations = []Recommendation{}
+		}
+		return output.WriteJSON(w, recommendations)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tCURRENT (RCU/WCU)\tRECOMMENDED (RCU/WCU)\tREAD PCT/PEAK\tWRITE PCT/PEAK\tTHROTTLES R/W\tSTATUS\tDETAILS\n")
+	for _, r := range recommendations {
+		details := r.Reason
+		if r.Skipped != "" {
+			details = r.Skipped
+		}
+		if r.Error != "" {
+			details = r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f/%.1f\t%.1f/%.1f\t%g/%g\t%s\t%s\n", r.Table,
+			modeSummary(r.CurrentMode, r.CurrentRcu, r.CurrentWcu), modeSummary(r.Mode, fmt.Sprintf("%d", r.Rcu), fmt.Sprintf("%d", r.Wcu)),
+			r.Read.Percentile, r.Read.Max, r.Write.Percentile, r.Write.Max, r.ReadThrottles, r.WriteThrottles, r.Status, details)
+	}
+	return tw.Flush()
+}
+
+// ExecuteRecommend recommends the billing mode and capacity of every given table from its consumed capacity and throttles,
+// and applies the recommendations through update.ExecuteUpdate once the user confirms, unless 'yes' is set.
+// It takes a DynamoDBManager, the metrics source, the table names, the options, the apply, dry-run and confirmation flags
+// and the output format of the report as input.
+// It returns an error wrapping client.ErrPendingChanges for a dry run with changes to apply,
+// client.ErrPartialFailure if some tables failed, or the error of the failure if all of them failed.
+func ExecuteRecommend(dbmgr *client.DynamoDBManager, source metrics.Source, tables []string, opts Options,
+	apply bool, dryRun bool, yes bool, format string) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	recommendations := make([]Recommendation, 0, len(tables))
+	for _, tableName := range tables {
+		recommendations = append(recommendations, Recommend(dbmgr, source, tableName, opts))
+	}
+
+	pending := outcome.CountStatus(recommendations, StatusPending)
+	switch {
+	case !apply || pending == 0:
+		if err := WriteRecommendations(output.Stdout, recommendations, format); err != nil {
+			return err
+		}
+		return outcome.Summarize("recommendation", "table(s)", recommendations)
+	case dryRun:
+		if err := WriteRecommendations(output.Stdout, recommendations, format); err != nil {
+			return err
+		}
+		return errors.Join(fmt.Errorf("capacity change pending for %d table(s) - %w", pending, client.ErrPendingChanges),
+			outcome.Summarize("recommendation", "table(s)", recommendations))
+	default:
+		plan := func(w io.Writer) error { return WriteRecommendations(w, recommendations, output.Text) }
+		if err := prompt.ApprovePlan(yes, fmt.Sprintf("Apply the recommended capacity to %d table(s)?", pending), plan); err != nil {
+			return err
+		}
+	}
+
+	for i := range recommendations {
+		if recommendations[i].Status != StatusPending {
+			continue
+		}
+		if err := recommendations[i].apply(dbmgr); err != nil {
+			recommendations[i].Fail(err)
+			continue
+		}
+		recommendations[i].Status = StatusApplied
+		dbmgr.Logger.Infof("Table:%s updated to %s", recommendations[i].Table,
+			modeSummary(recommendations[i].Mode, fmt.Sprintf("%d", recommendations[i].Rcu), fmt.Sprintf("%d", recommendations[i].Wcu)))
+	}
+
+	if err := WriteRecommendations(output.Stdout, recommendations, format); err != nil {
+		return err
+	}
+	return outcome.Summarize("recommendation", "table(s)", recommendations)
+}
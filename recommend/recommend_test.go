@@ -0,0 +1,144 @@
+package recommend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/metrics"
+)
+
+var testWindow = metrics.Window{
+	Start:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	End:    time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC),
+	Period: time.Minute,
+}
+
+var testOptions = Options{
+	Window:         testWindow,
+	Percentile:     DefaultPercentile,
+	Headroom:       DefaultHeadroom,
+	MinUtilization: DefaultMinUtilization,
+}
+
+// series returns a datapoint for each period of the test window, with the given sums over the period.
+func series(values ...float64) []metrics.Datapoint {
+	datapoints := make([]metrics.Datapoint, 0, len(values))
+	for i, value := range values {
+		datapoints = append(datapoints, metrics.Datapoint{Time: testWindow.Start.Add(time.Duration(i) * testWindow.Period), Value: value})
+	}
+	return datapoints
+}
+
+// repeat returns the value 'n' times.
+func repeat(value float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func TestCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		stats     metrics.Stats
+		throttles float64
+		want      int64
+	}{
+		{"percentile with headroom", metrics.Stats{Percentile: 10, Max: 50}, 0, 12},
+		{"rounded up", metrics.Stats{Percentile: 10.1, Max: 50}, 0, 13},
+		{"peak when throttled", metrics.Stats{Percentile: 10, Max: 50}, 3, 60},
+		{"at least one unit", metrics.Stats{}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Capacity(tt.stats, tt.throttles, testOptions); got != tt.want {
+				t.Errorf("Capacity(%+v, %g) = %d, want %d", tt.stats, tt.throttles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	source := metrics.StaticSource{
+		// 10 reads and 5 writes per second all along
+		"steady": {
+			metrics.ConsumedReadCapacity:  series(repeat(600, 10)...),
+			metrics.ConsumedWriteCapacity: series(repeat(300, 10)...),
+		},
+		// 100 reads per second for a single minute
+		"spiky": {
+			metrics.ConsumedReadCapacity: series(6000),
+		},
+		"idle": {},
+		// Peak of 20 reads per second, throttled
+		"throttled": {
+			metrics.ConsumedReadCapacity:  series(append(repeat(600, 9), 1200)...),
+			metrics.ConsumedWriteCapacity: series(repeat(300, 10)...),
+			metrics.ReadThrottleEvents:    series(0, 0, 0, 0, 0, 0, 0, 0, 0, 4),
+		},
+	}
+	provisioned := func(rcu int64, wcu int64) *types.TableDescription {
+		return &types.TableDescription{
+			BillingModeSummary:    &types.BillingModeSummary{BillingMode: types.BillingModeProvisioned},
+			ProvisionedThroughput: &types.ProvisionedThroughputDescription{ReadCapacityUnits: aws.Int64(rcu), WriteCapacityUnits: aws.Int64(wcu)},
+		}
+	}
+	onDemand := &types.TableDescription{
+		BillingModeSummary: &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest},
+	}
+	onDemandWithIndex := &types.TableDescription{
+		BillingModeSummary:     &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{IndexName: aws.String("by-date")}},
+	}
+
+	tests := []struct {
+		name    string
+		metrics string
+		table   *types.TableDescription
+		mode    string
+		rcu     int64
+		wcu     int64
+		status  string
+	}{
+		{"provisioned matching the traffic", "steady", provisioned(12, 6), ModeProvisioned, 12, 6, StatusUnchanged},
+		{"provisioned below the traffic", "steady", provisioned(5, 5), ModeProvisioned, 12, 6, StatusPending},
+		{"on-demand with steady traffic", "steady", onDemand, ModeProvisioned, 12, 6, StatusPending},
+		{"on-demand with an index", "steady", onDemandWithIndex, ModeProvisioned, 12, 6, StatusSkipped},
+		{"on-demand with spiky traffic", "spiky", onDemand, ModeOnDemand, 0, 0, StatusUnchanged},
+		{"provisioned with spiky traffic", "spiky", provisioned(120, 1), ModeOnDemand, 0, 0, StatusPending},
+		{"provisioned without traffic", "idle", provisioned(12, 6), ModeOnDemand, 0, 0, StatusPending},
+		{"on-demand with an index without traffic", "idle", onDemandWithIndex, ModeOnDemand, 0, 0, StatusUnchanged},
+		{"throttled reads", "throttled", provisioned(12, 6), ModeProvisioned, 24, 6, StatusPending},
+		{"missing metrics", "unknown", onDemand, "", 0, 0, StatusFailed},
+	}
+
+	dbmgr := &client.DynamoDBManager{}
+	if err := client.SetupLogger(dbmgr, "Error"); err != nil {
+		t.Fatal(err)
+	}
+	defer func(describe func(*client.DynamoDBManager, string) (*types.TableDescription, error)) {
+		DescribeTableClient = describe
+	}(DescribeTableClient)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DescribeTableClient = func(*client.DynamoDBManager, string) (*types.TableDescription, error) {
+				return tt.table, nil
+			}
+			got := Recommend(dbmgr, source, tt.metrics, testOptions)
+			if got.Mode != tt.mode || got.Rcu != tt.rcu || got.Wcu != tt.wcu || got.Status != tt.status {
+				t.Errorf("Recommend() = %s %d/%d %s, want %s %d/%d %s (reason:%s error:%s)", got.Mode, got.Rcu, got.Wcu, got.Status,
+					tt.mode, tt.rcu, tt.wcu, tt.status, got.Reason, got.Error)
+			}
+			if (got.Status == StatusSkipped) != (got.Skipped != "") {
+				t.Errorf("Recommend() status:%s skipped:%q", got.Status, got.Skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+// DefaultCreateTimeout is how long the creation waits for a table to become active
+const DefaultCreateTimeout = 30 * time.Minute
+
+// Statuses of a table creation
+const (
+	StatusCreated string = "created"
+	StatusPending string = "pending"
+	StatusFailed  string = "failed"
+)
+
+var (
+	CreateTableClient             = client.CreateTable
+	WaitForTableClient            = client.WaitForTable
+	UpdateTimeToLiveClient        = client.UpdateTimeToLive
+	UpdateContinuousBackupsClient = client.UpdateContinuousBackups
+)
+
+// CreateResult is the outcome of the creation of a table.
+type CreateResult struct {
+	Table  string `json:"table"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	err    error
+}
+
+// Fail marks the creation as failed with the given error.
+func (r *CreateResult) Fail(err error) {
+	r.Status = StatusFailed
+	r.Error = err.Error()
+	r.err = err
+}
+
+// WriteCreateResults writes the outcome of the table creations in the given output format.
+func WriteCreateResults(w io.Writer, results []CreateResult, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, results)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tSTATUS\tERROR\n")
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Table, result.Status, result.Error)
+	}
+	return tw.Flush()
+}
+
+// CreateFromDefinition creates the table of a definition, waits until it is active,
+// then enables its time to live and point in time recovery.
+// It returns an error if any step fails, the table may exist then.
+func CreateFromDefinition(dbmgr *client.DynamoDBManager, definition *Definition, timeout time.Duration) error {
+	tableName := definition.Name()
+	if _, err := CreateTableClient(dbmgr, definition.Input); err != nil {
+		return err
+	}
+	dbmgr.Logger.Infof("Creating table:%s", tableName)
+	if err := WaitForTableClient(dbmgr, tableName, timeout); err != nil {
+		return err
+	}
+
+	if ttl := definition.TimeToLive; ttl != nil {
+		if err := UpdateTimeToLiveClient(dbmgr, tableName, aws.ToString(ttl.AttributeName), true); err != nil {
+			return fmt.Errorf("table:%s created, enabling its time to live failed - %w", tableName, err)
+		}
+	}
+	if definition.PointInTimeRecovery {
+		if _, err := UpdateContinuousBackupsClient(dbmgr, tableName, true); err != nil {
+			return fmt.Errorf("table:%s created, enabling its point in time recovery failed - %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// ExecuteCreate creates the tables declared by a spec file, once the specs are validated locally.
+// It takes a DynamoDBManager, the spec file, the optional name overriding the name of a single spec, the timeout to wait
+// for each table to become active, the dry-run flag and the output format of the report as input.
+// It returns an error joining the problems of the specs if any is invalid, an error wrapping client.ErrPendingChanges for a dry run,
+// client.ErrPartialFailure if some creations failed, or the error of the failure if all of them failed.
+func ExecuteCreate(dbmgr *client.DynamoDBManager, file string, name string, timeout time.Duration, dryRun bool, format string) error {
+	specs, err := LoadSpecs(file)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		if len(specs) > 1 {
+			return errors.New(fmt.Sprintf("spec file:%s declares %d tables, the name can only override a single one", file, len(specs)))
+		}
+		specs[0].Name = name
+	}
+
+	var errs []error
+	for _, spec := range specs {
+		if err = spec.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec of table:%s:\n%w", spec.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	results := make([]CreateResult, 0, len(specs))
+	for _, spec := range specs {
+		result := CreateResult{Table: spec.Name, Status: StatusPending}
+		if !dryRun {
+			if err = CreateFromDefinition(dbmgr, spec.Definition(), timeout); err != nil {
+				result.Fail(err)
+			} else {
+				result.Status = StatusCreated
+				dbmgr.Logger.Infof("Created table:%s", spec.Name)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err = WriteCreateResults(output.Stdout, results, format); err != nil {
+		return err
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Status == StatusFailed {
+			failed, err = failed+1, result.err
+		}
+	}
+	switch {
+	case dryRun:
+		return fmt.Errorf("creation pending for %d table(s) - %w", len(results), client.ErrPendingChanges)
+	case failed == 0:
+		return nil
+	case failed == len(results):
+		return fmt.Errorf("creation failed for all %d table(s) - %w", failed, err)
+	default:
+		return fmt.Errorf("creation failed for %d of %d table(s) - %w", failed, len(results), client.ErrPartialFailure)
+	}
+}
@@ -0,0 +1,671 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+// Formats of the schema export
+const (
+	FormatSpec           string = "spec"
+	FormatCreateTable    string = "create-table"
+	FormatCloudFormation string = "cloudformation"
+	FormatTerraform      string = "terraform"
+)
+
+var (
+	DescribeTableClient             = client.DescribeTable
+	DescribeTimeToLiveClient        = client.DescribeTimeToLive
+	DescribeContinuousBackupsClient = client.DescribeContinuousBackups
+	GetTableTagsClient              = client.GetTableTags
+)
+
+// Definition is everything needed to recreate a table: the CreateTable input, with the encryption and tags,
+// and the time to live and point in time recovery settings applied once the table is created.
+type Definition struct {
+	Input               *dynamodb.CreateTableInput
+	TimeToLive          *types.TimeToLiveSpecification
+	PointInTimeRecovery bool
+}
+
+// Name returns the name of the defined table.
+func (d *Definition) Name() string {
+	return aws.ToString(d.Input.TableName)
+}
+
+// ValidateFormat checks that the format is one of the supported schema export formats.
+// It returns an error if the format is not recognized.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatSpec, FormatCreateTable, FormatCloudFormation, FormatTerraform:
+		return nil
+	}
+	return errors.New(fmt.Sprintf("unrecognized schema format provided:%s", format))
+}
+
+// sseSpecification returns the encryption to create the described table with, nil for the default DynamoDB owned key.
+func sseSpecification(sse *types.SSEDescription) *types.SSESpecification {
+	if sse == nil || (sse.Status != types.SSEStatusEnabled && sse.Status != types.SSEStatusEnabling) {
+		return nil
+	}
+	spec := &types.SSESpecification{Enabled: aws.Bool(true), SSEType: sse.SSEType}
+	if sse.KMSMasterKeyArn != nil {
+		spec.KMSMasterKeyId = sse.KMSMasterKeyArn
+	}
+	return spec
+}
+
+// GetDefinition gathers the definition of a table from its description, time to live, continuous backups and tags.
+// The time to live, point in time recovery and tags are best effort: a failure to get them is logged and they are left out.
+// The tags reserved for AWS are left out too, as they can't be set on a table.
+// It returns the definition and an error if the table can't be described.
+func GetDefinition(dbmgr *client.DynamoDBManager, tableName string) (*Definition, error) {
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		return nil, err
+	}
+	definition := &Definition{Input: CreateTableInput(table, tableName)}
+	definition.Input.SSESpecification = sseSpecification(table.SSEDescription)
+
+	ttl, err := DescribeTimeToLiveClient(dbmgr, tableName)
+	if err != nil {
+		dbmgr.Logger.Warnf("Failed to get the time to live of table:%s - error:%v", tableName, err)
+	} else if ttl != nil && (ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabled || ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+		definition.TimeToLive = &types.TimeToLiveSpecification{AttributeName: ttl.AttributeName, Enabled: aws.Bool(true)}
+	}
+
+	backups, err := DescribeContinuousBackupsClient(dbmgr, tableName)
+	if err != nil {
+		dbmgr.Logger.Warnf("Failed to get the continuous backups of table:%s - error:%v", tableName, err)
+	} else if backups != nil && backups.PointInTimeRecoveryDescription != nil {
+		definition.PointInTimeRecovery = backups.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus == types.PointInTimeRecoveryStatusEnabled
+	}
+
+	tags, err := GetTableTagsClient(dbmgr, aws.ToString(table.TableArn))
+	if err != nil {
+		dbmgr.Logger.Warnf("Failed to get the tags of table:%s - error:%v", tableName, err)
+	}
+	for _, tag := range tags {
+		// The tags reserved for AWS, e.g. of a CloudFormation stack, can't be part of the definition of a new table
+		if client.IsReservedTag(aws.ToString(tag.Key)) {
+			dbmgr.Logger.Infof("Skipped the reserved tag:%s of table:%s", aws.ToString(tag.Key), tableName)
+			continue
+		}
+		definition.Input.Tags = append(definition.Input.Tags, tag)
+	}
+	sort.Slice(definition.Input.Tags, func(i, j int) bool {
+		return aws.ToString(definition.Input.Tags[i].Key) < aws.ToString(definition.Input.Tags[j].Key)
+	})
+	return definition, nil
+}
+
+// compact drops the null values, empty strings and empty lists and objects of a decoded JSON value.
+func compact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case map[string]interface{}:
+		for key, field := range v {
+			if field = compact(field); field == nil {
+				delete(v, key)
+			} else {
+				v[key] = field
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = compact(v[i])
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	}
+	return value
+}
+
+// CreateTableJSON encodes the CreateTable input of a definition as the JSON accepted by the API, e.g. by aws dynamodb create-table --cli-input-json.
+// The fields not set are left out.
+func CreateTableJSON(definition *Definition) (interface{}, error) {
+	content, err := json.Marshal(definition.Input)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return compact(value), nil
+}
+
+// WriteCreateTable writes the CreateTable inputs of the definitions as JSON, an object for a single table or an array of objects.
+// The time to live and point in time recovery can't be set by CreateTable, they are reported as warnings.
+func WriteCreateTable(dbmgr *client.DynamoDBManager, w io.Writer, definitions []*Definition) error {
+	inputs := make([]interface{}, 0, len(definitions))
+	for _, definition := range definitions {
+		if definition.TimeToLive != nil {
+			dbmgr.Logger.Warnf("Time to live on attribute:%s of table:%s isn't part of the CreateTable input, enable it with UpdateTimeToLive",
+				aws.ToString(definition.TimeToLive.AttributeName), definition.Name())
+		}
+		if definition.PointInTimeRecovery {
+			dbmgr.Logger.Warnf("Point in time recovery of table:%s isn't part of the CreateTable input, enable it with UpdateContinuousBackups", definition.Name())
+		}
+		input, err := CreateTableJSON(definition)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Failed to encode the CreateTable input of table:%s - error:%v", definition.Name(), err))
+		}
+		inputs = append(inputs, input)
+	}
+	if len(inputs) == 1 {
+		return output.WriteJSON(w, inputs[0])
+	}
+	return output.WriteJSON(w, inputs)
+}
+
+func isAlphanumeric(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// unique returns the identifier, with a numbered suffix if it is already taken, and marks it as taken.
+func unique(id string, taken map[string]bool) string {
+	result := id
+	for i := 2; taken[result]; i++ {
+		result = fmt.Sprintf("%s%d", id, i)
+	}
+	taken[result] = true
+	return result
+}
+
+// logicalID converts a table name into a CloudFormation logical ID, e.g. orders-v2 into OrdersV2.
+// Logical IDs are alphanumeric, the ones starting with a digit are prefixed with Table.
+func logicalID(tableName string) string {
+	var id strings.Builder
+	upper := true
+	for _, c := range tableName {
+		switch {
+		case !isAlphanumeric(c):
+			upper = true
+		case upper:
+			id.WriteString(strings.ToUpper(string(c)))
+			upper = false
+		default:
+			id.WriteRune(c)
+		}
+	}
+	if id.Len() == 0 || id.String()[0] >= '0' && id.String()[0] <= '9' {
+		return "Table" + id.String()
+	}
+	return id.String()
+}
+
+// resourceName converts a table name into a Terraform resource name, e.g. orders.v2 into orders_v2.
+// Resource names are made of letters, digits, '_' and '-', the ones not starting with a letter are prefixed with table_.
+func resourceName(tableName string) string {
+	name := strings.Map(func(c rune) rune {
+		if isAlphanumeric(c) || c == '_' || c == '-' {
+			return c
+		}
+		return '_'
+	}, tableName)
+	if c := name[0]; !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+		return "table_" + name
+	}
+	return name
+}
+
+// CloudFormation template of the exported tables
+type cfnTemplate struct {
+	AWSTemplateFormatVersion string                 `yaml:"AWSTemplateFormatVersion" json:"AWSTemplateFormatVersion"`
+	Description              string                 `yaml:"Description" json:"Description"`
+	Resources                map[string]cfnResource `yaml:"Resources" json:"Resources"`
+}
+
+// cfnResource is an AWS::DynamoDB::Table resource, retained on deletion so it can be imported into a stack.
+type cfnResource struct {
+	Type                string        `yaml:"Type" json:"Type"`
+	DeletionPolicy      string        `yaml:"DeletionPolicy" json:"DeletionPolicy"`
+	UpdateReplacePolicy string        `yaml:"UpdateReplacePolicy" json:"UpdateReplacePolicy"`
+	Properties          cfnProperties `yaml:"Properties" json:"Properties"`
+}
+
+type cfnProperties struct {
+	TableName                        string                  `yaml:"TableName" json:"TableName"`
+	AttributeDefinitions             []cfnAttribute          `yaml:"AttributeDefinitions" json:"AttributeDefinitions"`
+	KeySchema                        []cfnKeyElement         `yaml:"KeySchema" json:"KeySchema"`
+	BillingMode                      string                  `yaml:"BillingMode" json:"BillingMode"`
+	ProvisionedThroughput            *cfnThroughput          `yaml:"ProvisionedThroughput,omitempty" json:"ProvisionedThroughput,omitempty"`
+	GlobalSecondaryIndexes           []cfnIndex              `yaml:"GlobalSecondaryIndexes,omitempty" json:"GlobalSecondaryIndexes,omitempty"`
+	LocalSecondaryIndexes            []cfnIndex              `yaml:"LocalSecondaryIndexes,omitempty" json:"LocalSecondaryIndexes,omitempty"`
+	StreamSpecification              *cfnStream              `yaml:"StreamSpecification,omitempty" json:"StreamSpecification,omitempty"`
+	SSESpecification                 *cfnSSE                 `yaml:"SSESpecification,omitempty" json:"SSESpecification,omitempty"`
+	TimeToLiveSpecification          *cfnTimeToLive          `yaml:"TimeToLiveSpecification,omitempty" json:"TimeToLiveSpecification,omitempty"`
+	PointInTimeRecoverySpecification *cfnPointInTimeRecovery `yaml:"PointInTimeRecoverySpecification,omitempty" json:"PointInTimeRecoverySpecification,omitempty"`
+	TableClass                       string                  `yaml:"TableClass,omitempty" json:"TableClass,omitempty"`
+	DeletionProtectionEnabled        bool                    `yaml:"DeletionProtectionEnabled,omitempty" json:"DeletionProtectionEnabled,omitempty"`
+	Tags                             []cfnTag                `yaml:"Tags,omitempty" json:"Tags,omitempty"`
+}
+
+type cfnAttribute struct {
+	AttributeName string `yaml:"AttributeName" json:"AttributeName"`
+	AttributeType string `yaml:"AttributeType" json:"AttributeType"`
+}
+
+type cfnKeyElement struct {
+	AttributeName string `yaml:"AttributeName" json:"AttributeName"`
+	KeyType       string `yaml:"KeyType" json:"KeyType"`
+}
+
+type cfnThroughput struct {
+	ReadCapacityUnits  int64 `yaml:"ReadCapacityUnits" json:"ReadCapacityUnits"`
+	WriteCapacityUnits int64 `yaml:"WriteCapacityUnits" json:"WriteCapacityUnits"`
+}
+
+type cfnProjection struct {
+	ProjectionType   string   `yaml:"ProjectionType" json:"ProjectionType"`
+	NonKeyAttributes []string `yaml:"NonKeyAttributes,omitempty" json:"NonKeyAttributes,omitempty"`
+}
+
+type cfnIndex struct {
+	IndexName             string          `yaml:"IndexName" json:"IndexName"`
+	KeySchema             []cfnKeyElement `yaml:"KeySchema" json:"KeySchema"`
+	Projection            cfnProjection   `yaml:"Projection" json:"Projection"`
+	ProvisionedThroughput *cfnThroughput  `yaml:"ProvisionedThroughput,omitempty" json:"ProvisionedThroughput,omitempty"`
+}
+
+type cfnStream struct {
+	StreamViewType string `yaml:"StreamViewType" json:"StreamViewType"`
+}
+
+type cfnSSE struct {
+	SSEEnabled     bool   `yaml:"SSEEnabled" json:"SSEEnabled"`
+	SSEType        string `yaml:"SSEType,omitempty" json:"SSEType,omitempty"`
+	KMSMasterKeyId string `yaml:"KMSMasterKeyId,omitempty" json:"KMSMasterKeyId,omitempty"`
+}
+
+type cfnTimeToLive struct {
+	AttributeName string `yaml:"AttributeName" json:"AttributeName"`
+	Enabled       bool   `yaml:"Enabled" json:"Enabled"`
+}
+
+type cfnPointInTimeRecovery struct {
+	PointInTimeRecoveryEnabled bool `yaml:"PointInTimeRecoveryEnabled" json:"PointInTimeRecoveryEnabled"`
+}
+
+type cfnTag struct {
+	Key   string `yaml:"Key" json:"Key"`
+	Value string `yaml:"Value" json:"Value"`
+}
+
+func cfnKeySchema(keySchema []types.KeySchemaElement) []cfnKeyElement {
+	elements := make([]cfnKeyElement, 0, len(keySchema))
+	for _, element := range keySchema {
+		elements = append(elements, cfnKeyElement{AttributeName: aws.ToString(element.AttributeName), KeyType: string(element.KeyType)})
+	}
+	return elements
+}
+
+func cfnProvisionedThroughput(throughput *types.ProvisionedThroughput) *cfnThroughput {
+	if throughput == nil {
+		return nil
+	}
+	return &cfnThroughput{ReadCapacityUnits: aws.ToInt64(throughput.ReadCapacityUnits), WriteCapacityUnits: aws.ToInt64(throughput.WriteCapacityUnits)}
+}
+
+func cfnIndexProjection(projection *types.Projection) cfnProjection {
+	if projection == nil {
+		return cfnProjection{ProjectionType: string(types.ProjectionTypeAll)}
+	}
+	return cfnProjection{ProjectionType: string(projection.ProjectionType), NonKeyAttributes: projection.NonKeyAttributes}
+}
+
+// cloudFormationResource converts a definition into an AWS::DynamoDB::Table resource.
+func cloudFormationResource(definition *Definition) cfnResource {
+	input := definition.Input
+	properties := cfnProperties{
+		TableName:                 aws.ToString(input.TableName),
+		KeySchema:                 cfnKeySchema(input.KeySchema),
+		BillingMode:               string(input.BillingMode),
+		ProvisionedThroughput:     cfnProvisionedThroughput(input.ProvisionedThroughput),
+		TableClass:                string(input.TableClass),
+		DeletionProtectionEnabled: aws.ToBool(input.DeletionProtectionEnabled),
+	}
+	for _, attribute := range input.AttributeDefinitions {
+		properties.AttributeDefinitions = append(properties.AttributeDefinitions,
+			cfnAttribute{AttributeName: aws.ToString(attribute.AttributeName), AttributeType: string(attribute.AttributeType)})
+	}
+	for _, index := range input.GlobalSecondaryIndexes {
+		properties.GlobalSecondaryIndexes = append(properties.GlobalSecondaryIndexes, cfnIndex{
+			IndexName:             aws.ToString(index.IndexName),
+			KeySchema:             cfnKeySchema(index.KeySchema),
+			Projection:            cfnIndexProjection(index.Projection),
+			ProvisionedThroughput: cfnProvisionedThroughput(index.ProvisionedThroughput),
+		})
+	}
+	for _, index := range input.LocalSecondaryIndexes {
+		properties.LocalSecondaryIndexes = append(properties.LocalSecondaryIndexes, cfnIndex{
+			IndexName:  aws.ToString(index.IndexName),
+			KeySchema:  cfnKeySchema(index.KeySchema),
+			Projection: cfnIndexProjection(index.Projection),
+		})
+	}
+	if input.StreamSpecification != nil && aws.ToBool(input.StreamSpecification.StreamEnabled) {
+		properties.StreamSpecification = &cfnStream{StreamViewType: string(input.StreamSpecification.StreamViewType)}
+	}
+	if sse := input.SSESpecification; sse != nil {
+		properties.SSESpecification = &cfnSSE{SSEEnabled: aws.ToBool(sse.Enabled), SSEType: string(sse.SSEType), KMSMasterKeyId: aws.ToString(sse.KMSMasterKeyId)}
+	}
+	if ttl := definition.TimeToLive; ttl != nil {
+		properties.TimeToLiveSpecification = &cfnTimeToLive{AttributeName: aws.ToString(ttl.AttributeName), Enabled: true}
+	}
+	if definition.PointInTimeRecovery {
+		properties.PointInTimeRecoverySpecification = &cfnPointInTimeRecovery{PointInTimeRecoveryEnabled: true}
+	}
+	for _, tag := range input.Tags {
+		properties.Tags = append(properties.Tags, cfnTag{Key: aws.ToString(tag.Key), Value: aws.ToString(tag.Value)})
+	}
+	return cfnResource{
+		Type:                "AWS::DynamoDB::Table",
+		DeletionPolicy:      "Retain",
+		UpdateReplacePolicy: "Retain",
+		Properties:          properties,
+	}
+}
+
+// WriteCloudFormation writes a CloudFormation template declaring the tables of the definitions,
+// as YAML or as JSON with the JSON output format.
+// The resources are retained on deletion, as required to import the existing tables into a stack.
+func WriteCloudFormation(w io.Writer, definitions []*Definition, format string) error {
+	template := cfnTemplate{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Description:              "DynamoDB tables exported by dynamodb-manager",
+		Resources:                make(map[string]cfnResource, len(definitions)),
+	}
+	taken := map[string]bool{}
+	for _, definition := range definitions {
+		template.Resources[unique(logicalID(definition.Name()), taken)] = cloudFormationResource(definition)
+	}
+
+	if format == output.JSON {
+		return output.WriteJSON(w, template)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(template); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// hclString quotes a string for HCL, escaping the template sequences as well.
+func hclString(value string) string {
+	quoted, _ := json.Marshal(value)
+	escaped := strings.ReplaceAll(string(quoted), "${", "$${")
+	return strings.ReplaceAll(escaped, "%{", "%%{")
+}
+
+// hclWriter writes the indented attributes and blocks of a Terraform configuration.
+type hclWriter struct {
+	w      *bytes.Buffer
+	indent int
+}
+
+func (h *hclWriter) line(format string, args ...interface{}) {
+	if format == "" {
+		h.w.WriteString("\n")
+		return
+	}
+	fmt.Fprintf(h.w, "%s%s\n", strings.Repeat("  ", h.indent), fmt.Sprintf(format, args...))
+}
+
+func (h *hclWriter) open(format string, args ...interface{}) {
+	h.line(format+" {", args...)
+	h.indent++
+}
+
+func (h *hclWriter) close() {
+	h.indent--
+	h.line("}")
+}
+
+// keyAttributes returns the hash and range key attribute names of a key schema.
+func keyAttributes(keySchema []types.KeySchemaElement) (string, string) {
+	var hashKey, rangeKey string
+	for _, element := range keySchema {
+		if element.KeyType == types.KeyTypeHash {
+			hashKey = aws.ToString(element.AttributeName)
+		} else {
+			rangeKey = aws.ToString(element.AttributeName)
+		}
+	}
+	return hashKey, rangeKey
+}
+
+// writeTerraformIndex writes the attributes of a secondary index block, a local index shares the hash key of the table.
+func writeTerraformIndex(h *hclWriter, name string, keySchema []types.KeySchemaElement, projection *types.Projection, throughput *types.ProvisionedThroughput, local bool) {
+	hashKey, rangeKey := keyAttributes(keySchema)
+	h.line("name            = %s", hclString(name))
+	if !local {
+		h.line("hash_key        = %s", hclString(hashKey))
+	}
+	if rangeKey != "" {
+		h.line("range_key       = %s", hclString(rangeKey))
+	}
+	p := cfnIndexProjection(projection)
+	h.line("projection_type = %s", hclString(p.ProjectionType))
+	if len(p.NonKeyAttributes) > 0 {
+		quoted := make([]string, 0, len(p.NonKeyAttributes))
+		for _, attribute := range p.NonKeyAttributes {
+			quoted = append(quoted, hclString(attribute))
+		}
+		h.line("non_key_attributes = [%s]", strings.Join(quoted, ", "))
+	}
+	if throughput != nil {
+		h.line("read_capacity   = %d", aws.ToInt64(throughput.ReadCapacityUnits))
+		h.line("write_capacity  = %d", aws.ToInt64(throughput.WriteCapacityUnits))
+	}
+}
+
+// writeTerraformTable writes the aws_dynamodb_table resource of a definition, with the import block bringing the existing table under management.
+func writeTerraformTable(h *hclWriter, name string, definition *Definition) {
+	input := definition.Input
+	hashKey, rangeKey := keyAttributes(input.KeySchema)
+
+	h.open("import")
+	h.line("to = aws_dynamodb_table.%s", name)
+	h.line("id = %s", hclString(definition.Name()))
+	h.close()
+	h.line("")
+
+	h.open(`resource "aws_dynamodb_table" %s`, hclString(name))
+	h.line("name         = %s", hclString(definition.Name()))
+	h.line("billing_mode = %s", hclString(string(input.BillingMode)))
+	if throughput := input.ProvisionedThroughput; throughput != nil {
+		h.line("read_capacity  = %d", aws.ToInt64(throughput.ReadCapacityUnits))
+		h.line("write_capacity = %d", aws.ToInt64(throughput.WriteCapacityUnits))
+	}
+	h.line("hash_key     = %s", hclString(hashKey))
+	if rangeKey != "" {
+		h.line("range_key    = %s", hclString(rangeKey))
+	}
+	if input.TableClass != "" {
+		h.line("table_class  = %s", hclString(string(input.TableClass)))
+	}
+	if aws.ToBool(input.DeletionProtectionEnabled) {
+		h.line("deletion_protection_enabled = true")
+	}
+	if stream := input.StreamSpecification; stream != nil && aws.ToBool(stream.StreamEnabled) {
+		h.line("stream_enabled   = true")
+		h.line("stream_view_type = %s", hclString(string(stream.StreamViewType)))
+	}
+
+	for _, attribute := range input.AttributeDefinitions {
+		h.line("")
+		h.open("attribute")
+		h.line("name = %s", hclString(aws.ToString(attribute.AttributeName)))
+		h.line("type = %s", hclString(string(attribute.AttributeType)))
+		h.close()
+	}
+	for _, index := range input.GlobalSecondaryIndexes {
+		h.line("")
+		h.open("global_secondary_index")
+		writeTerraformIndex(h, aws.ToString(index.IndexName), index.KeySchema, index.Projection, index.ProvisionedThroughput, false)
+		h.close()
+	}
+	for _, index := range input.LocalSecondaryIndexes {
+		h.line("")
+		h.open("local_secondary_index")
+		writeTerraformIndex(h, aws.ToString(index.IndexName), index.KeySchema, index.Projection, nil, true)
+		h.close()
+	}
+	if ttl := definition.TimeToLive; ttl != nil {
+		h.line("")
+		h.open("ttl")
+		h.line("attribute_name = %s", hclString(aws.ToString(ttl.AttributeName)))
+		h.line("enabled        = true")
+		h.close()
+	}
+	if definition.PointInTimeRecovery {
+		h.line("")
+		h.open("point_in_time_recovery")
+		h.line("enabled = true")
+		h.close()
+	}
+	if sse := input.SSESpecification; sse != nil && aws.ToBool(sse.Enabled) {
+		h.line("")
+		h.open("server_side_encryption")
+		h.line("enabled     = true")
+		if sse.KMSMasterKeyId != nil {
+			h.line("kms_key_arn = %s", hclString(aws.ToString(sse.KMSMasterKeyId)))
+		}
+		h.close()
+	}
+	if len(input.Tags) > 0 {
+		h.line("")
+		h.open("tags =")
+		for _, tag := range input.Tags {
+			h.line("%s = %s", hclString(aws.ToString(tag.Key)), hclString(aws.ToString(tag.Value)))
+		}
+		h.close()
+	}
+	h.close()
+}
+
+// WriteTerraform writes the Terraform configuration declaring the tables of the definitions as aws_dynamodb_table resources,
+// each with an import block so the existing table is imported on the next plan.
+func WriteTerraform(w io.Writer, definitions []*Definition) error {
+	h := &hclWriter{w: &bytes.Buffer{}}
+	taken := map[string]bool{}
+	for i, definition := range definitions {
+		if i > 0 {
+			h.line("")
+		}
+		writeTerraformTable(h, unique(resourceName(definition.Name()), taken), definition)
+	}
+	_, err := w.Write(h.w.Bytes())
+	return err
+}
+
+// WriteDefinitions writes the definitions in the given schema format.
+func WriteDefinitions(dbmgr *client.DynamoDBManager, w io.Writer, definitions []*Definition, schemaFormat string, format string) error {
+	switch schemaFormat {
+	case FormatSpec:
+		return WriteSpecs(w, definitions, format)
+	case FormatCloudFormation:
+		return WriteCloudFormation(w, definitions, format)
+	case FormatTerraform:
+		return WriteTerraform(w, definitions)
+	default:
+		return WriteCreateTable(dbmgr, w, definitions)
+	}
+}
+
+// fileExtension returns the extension of the files written in the given schema format.
+func fileExtension(schemaFormat string, format string) string {
+	switch {
+	case schemaFormat == FormatTerraform:
+		return ".tf"
+	case (schemaFormat == FormatSpec || schemaFormat == FormatCloudFormation) && format != output.JSON:
+		return ".yaml"
+	default:
+		return ".json"
+	}
+}
+
+// writeFile writes the definition of a table to its own file of the directory, named after the table.
+func writeFile(dbmgr *client.DynamoDBManager, dir string, definition *Definition, schemaFormat string, format string) error {
+	var buffer bytes.Buffer
+	if err := WriteDefinitions(dbmgr, &buffer, []*Definition{definition}, schemaFormat, format); err != nil {
+		return err
+	}
+	file := filepath.Join(dir, definition.Name()+fileExtension(schemaFormat, format))
+	if err := os.WriteFile(file, buffer.Bytes(), 0644); err != nil {
+		return errors.New(fmt.Sprintf("Failed to write schema file:%s - error:%v", file, err))
+	}
+	dbmgr.Logger.Infof("Exported the schema of table:%s to %s", definition.Name(), file)
+	return nil
+}
+
+// ExecuteExport exports the schema of the given tables as table specs, CreateTable input JSON, a CloudFormation template or a Terraform configuration.
+// It takes a DynamoDBManager, the table names, the schema format, the optional output directory and the output format as input.
+// Without a directory a single document declaring all the tables is written to stdout, otherwise one file per table.
+// It returns client.ErrPartialFailure if some tables couldn't be described, or the error of the failure if none could.
+func ExecuteExport(dbmgr *client.DynamoDBManager, tables []string, schemaFormat string, dir string, format string) error {
+	var definitions []*Definition
+	var lastErr error
+	failed := 0
+	for _, tableName := range tables {
+		definition, err := GetDefinition(dbmgr, tableName)
+		if err != nil {
+			dbmgr.Logger.Errorf("Failed to export the schema of table:%s - error:%v", tableName, err)
+			failed, lastErr = failed+1, err
+			continue
+		}
+		definitions = append(definitions, definition)
+	}
+
+	if dir == "" && len(definitions) > 0 {
+		if err := WriteDefinitions(dbmgr, output.Stdout, definitions, schemaFormat, format); err != nil {
+			return err
+		}
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errors.New(fmt.Sprintf("Failed to create directory:%s - error:%v", dir, err))
+		}
+		for _, definition := range definitions {
+			if err := writeFile(dbmgr, dir, definition, schemaFormat, format); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(tables):
+		return fmt.Errorf("schema export failed for all %d table(s) - %w", failed, lastErr)
+	default:
+		return fmt.Errorf("schema export failed for %d of %d table(s) - %w", failed, len(tables), client.ErrPartialFailure)
+	}
+}
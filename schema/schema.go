@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// IsOnDemand reports whether the described table is billed per request.
+// Tables created before the on-demand mode have no billing mode summary and are provisioned.
+func IsOnDemand(table *types.TableDescription) bool {
+	return table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode == types.BillingModePayPerRequest
+}
+
+// provisionedThroughput returns the throughput to create a provisioned table or index with.
+func provisionedThroughput(throughput *types.ProvisionedThroughputDescription) *types.ProvisionedThroughput {
+	if throughput == nil {
+		return nil
+	}
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  throughput.ReadCapacityUnits,
+		WriteCapacityUnits: throughput.WriteCapacityUnits,
+	}
+}
+
+// CreateTableInput builds the input recreating the described table under the given name:
+// key schema, attribute definitions, indexes, billing mode and capacity, stream, table class and deletion protection.
+// The tags, time to live and encryption key aren't part of the description and are left to the caller.
+func CreateTableInput(table *types.TableDescription, name string) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:                 aws.String(name),
+		AttributeDefinitions:      table.AttributeDefinitions,
+		KeySchema:                 table.KeySchema,
+		DeletionProtectionEnabled: table.DeletionProtectionEnabled,
+	}
+
+	onDemand := IsOnDemand(table)
+	if onDemand {
+		input.BillingMode = types.BillingModePayPerRequest
+	} else {
+		input.BillingMode = types.BillingModeProvisioned
+		input.ProvisionedThroughput = provisionedThroughput(table.ProvisionedThroughput)
+	}
+
+	for _, index := range table.GlobalSecondaryIndexes {
+		gsi := types.GlobalSecondaryIndex{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		}
+		if !onDemand {
+			gsi.ProvisionedThroughput = provisionedThroughput(index.ProvisionedThroughput)
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+	for _, index := range table.LocalSecondaryIndexes {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		})
+	}
+
+	if table.StreamSpecification != nil && aws.ToBool(table.StreamSpecification.StreamEnabled) {
+		input.StreamSpecification = table.StreamSpecification
+	}
+	if table.TableClassSummary != nil {
+		input.TableClass = table.TableClassSummary.TableClass
+	}
+	return input
+}
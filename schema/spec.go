@@ -0,0 +1,446 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+)
+
+// Limits of the secondary indexes of a table
+const (
+	maxGlobalIndexes = 20
+	maxLocalIndexes  = 5
+)
+
+// validTableName matches the names DynamoDB accepts for tables and indexes
+var validTableName = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+// AttributeSpec is an attribute definition, the attributes used by the keys of the table and indexes must all be defined.
+type AttributeSpec struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"` // S, N or B
+}
+
+// KeySpec names the partition and optional sort key attributes of a table or an index.
+type KeySpec struct {
+	PartitionKey string `yaml:"partitionKey,omitempty" json:"partitionKey,omitempty"`
+	SortKey      string `yaml:"sortKey,omitempty" json:"sortKey,omitempty"`
+}
+
+// CapacitySpec is the provisioned capacity of a table or an index, client.DefaultRcu and client.DefaultWcu if not set.
+type CapacitySpec struct {
+	ReadCapacity  int64 `yaml:"readCapacity,omitempty" json:"readCapacity,omitempty"`
+	WriteCapacity int64 `yaml:"writeCapacity,omitempty" json:"writeCapacity,omitempty"`
+}
+
+// IndexSpec is a global or local secondary index, a local index shares the partition key of the table.
+type IndexSpec struct {
+	Name             string `yaml:"name" json:"name"`
+	KeySpec          `yaml:",inline"`
+	Projection       string   `yaml:"projection,omitempty" json:"projection,omitempty"` // ALL if not set, KEYS_ONLY or INCLUDE
+	NonKeyAttributes []string `yaml:"nonKeyAttributes,omitempty" json:"nonKeyAttributes,omitempty"`
+	CapacitySpec     `yaml:",inline"`
+}
+
+// EncryptionSpec is the server-side encryption with a KMS key, the AWS managed key if KMSKeyId is not set.
+type EncryptionSpec struct {
+	Type     string `yaml:"type" json:"type"`
+	KMSKeyId string `yaml:"kmsKeyId,omitempty" json:"kmsKeyId,omitempty"`
+}
+
+// Spec declares a table, it is read by the create command and written by the schema export in the spec format.
+type Spec struct {
+	Name                   string          `yaml:"name" json:"name"`
+	Attributes             []AttributeSpec `yaml:"attributes" json:"attributes"`
+	KeySpec                `yaml:",inline"`
+	BillingMode            string `yaml:"billingMode,omitempty" json:"billingMode,omitempty"` // PROVISIONED if not set, or PAY_PER_REQUEST
+	CapacitySpec           `yaml:",inline"`
+	GlobalSecondaryIndexes []IndexSpec       `yaml:"globalSecondaryIndexes,omitempty" json:"globalSecondaryIndexes,omitempty"`
+	LocalSecondaryIndexes  []IndexSpec       `yaml:"localSecondaryIndexes,omitempty" json:"localSecondaryIndexes,omitempty"`
+	Stream                 string            `yaml:"stream,omitempty" json:"stream,omitempty"`         // Stream view type, no stream if not set
+	TimeToLive             string            `yaml:"timeToLive,omitempty" json:"timeToLive,omitempty"` // Expiry attribute, no time to live if not set
+	PointInTimeRecovery    bool              `yaml:"pointInTimeRecovery,omitempty" json:"pointInTimeRecovery,omitempty"`
+	Encryption             *EncryptionSpec   `yaml:"encryption,omitempty" json:"encryption,omitempty"` // DynamoDB owned key if not set
+	TableClass             string            `yaml:"tableClass,omitempty" json:"tableClass,omitempty"`
+	DeletionProtection     bool              `yaml:"deletionProtection,omitempty" json:"deletionProtection,omitempty"`
+	Tags                   map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// isOneOf reports whether the value is one of the values of a DynamoDB enum.
+func isOneOf[T ~string](value string, values []T) bool {
+	for _, v := range values {
+		if string(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// onDemand reports whether the spec declares an on-demand table.
+func (s *Spec) onDemand() bool {
+	return s.BillingMode == string(types.BillingModePayPerRequest)
+}
+
+// validateKeys checks that the key attributes of a table or an index are defined, and marks them as used.
+func validateKeys(owner string, keys KeySpec, defined map[string]string, used map[string]bool) []error {
+	var errs []error
+	if keys.PartitionKey == "" {
+		errs = append(errs, errors.New(fmt.Sprintf("%s has no partition key", owner)))
+	}
+	for _, key := range []string{keys.PartitionKey, keys.SortKey} {
+		if key == "" {
+			continue
+		}
+		if _, exists := defined[key]; !exists {
+			errs = append(errs, errors.New(fmt.Sprintf("key attribute:%s of %s isn't defined in the attributes", key, owner)))
+		}
+		used[key] = true
+	}
+	return errs
+}
+
+// validateCapacity checks the capacity of a table or an index against the billing mode.
+func (s *Spec) validateCapacity(owner string, capacity CapacitySpec) []error {
+	switch {
+	case capacity.ReadCapacity < 0 || capacity.WriteCapacity < 0:
+		return []error{errors.New(fmt.Sprintf("%s has a negative capacity", owner))}
+	case s.onDemand() && (capacity.ReadCapacity > 0 || capacity.WriteCapacity > 0):
+		return []error{errors.New(fmt.Sprintf("%s has a provisioned capacity, but the billing mode is %s", owner, s.BillingMode))}
+	}
+	return nil
+}
+
+// validateProjection checks the projection of an index.
+func validateProjection(owner string, index IndexSpec) []error {
+	switch {
+	case index.Projection != "" && !isOneOf(index.Projection, types.ProjectionType("").Values()):
+		return []error{errors.New(fmt.Sprintf("%s has an invalid projection:%s", owner, index.Projection))}
+	case index.Projection == string(types.ProjectionTypeInclude) && len(index.NonKeyAttributes) == 0:
+		return []error{errors.New(fmt.Sprintf("%s projects INCLUDE without nonKeyAttributes", owner))}
+	case index.Projection != string(types.ProjectionTypeInclude) && len(index.NonKeyAttributes) > 0:
+		return []error{errors.New(fmt.Sprintf("%s has nonKeyAttributes, but doesn't project INCLUDE", owner))}
+	}
+	return nil
+}
+
+// Validate checks the spec locally, as DynamoDB would: the attribute definitions must match the key schemas of the table and indexes,
+// the indexes must be unique and within limits, and the billing mode, capacities, stream, encryption and table class must be valid.
+// It returns all the problems found joined, nil if the spec is valid.
+func (s *Spec) Validate() error {
+	var errs []error
+	if !validTableName.MatchString(s.Name) {
+		errs = append(errs, errors.New(fmt.Sprintf("invalid table name:%q, 3 to 255 letters, digits, '_', '-' or '.' are expected", s.Name)))
+	}
+	table := "table:" + s.Name
+
+	defined := make(map[string]string, len(s.Attributes))
+	for _, attribute := range s.Attributes {
+		if _, exists := defined[attribute.Name]; exists {
+			errs = append(errs, errors.New(fmt.Sprintf("attribute:%s is defined more than once", attribute.Name)))
+		}
+		if !isOneOf(attribute.Type, types.ScalarAttributeType("").Values()) {
+			errs = append(errs, errors.New(fmt.Sprintf("attribute:%s has an invalid type:%q, S, N or B is expected", attribute.Name, attribute.Type)))
+		}
+		defined[attribute.Name] = attribute.Type
+	}
+
+	used := map[string]bool{}
+	errs = append(errs, validateKeys(table, s.KeySpec, defined, used)...)
+
+	if s.BillingMode != "" && !isOneOf(s.BillingMode, types.BillingMode("").Values()) {
+		errs = append(errs, errors.New(fmt.Sprintf("invalid billing mode:%s, PROVISIONED or PAY_PER_REQUEST is expected", s.BillingMode)))
+	}
+	errs = append(errs, s.validateCapacity(table, s.CapacitySpec)...)
+
+	indexes := map[string]bool{}
+	if len(s.GlobalSecondaryIndexes) > maxGlobalIndexes {
+		errs = append(errs, errors.New(fmt.Sprintf("%s has %d global secondary indexes, at most %d are allowed", table, len(s.GlobalSecondaryIndexes), maxGlobalIndexes)))
+	}
+	for _, index := range s.GlobalSecondaryIndexes {
+		owner := "global secondary index:" + index.Name
+		if indexes[index.Name] {
+			errs = append(errs, errors.New(fmt.Sprintf("index:%s is declared more than once", index.Name)))
+		}
+		if !validTableName.MatchString(index.Name) {
+			errs = append(errs, errors.New(fmt.Sprintf("invalid index name:%q", index.Name)))
+		}
+		indexes[index.Name] = true
+		errs = append(errs, validateKeys(owner, index.KeySpec, defined, used)...)
+		errs = append(errs, validateProjection(owner, index)...)
+		errs = append(errs, s.validateCapacity(owner, index.CapacitySpec)...)
+	}
+
+	if len(s.LocalSecondaryIndexes) > maxLocalIndexes {
+		errs = append(errs, errors.New(fmt.Sprintf("%s has %d local secondary indexes, at most %d are allowed", table, len(s.LocalSecondaryIndexes), maxLocalIndexes)))
+	}
+	if len(s.LocalSecondaryIndexes) > 0 && s.SortKey == "" {
+		errs = append(errs, errors.New(fmt.Sprintf("%s has local secondary indexes, but no sort key", table)))
+	}
+	for _, index := range s.LocalSecondaryIndexes {
+		owner := "local secondary index:" + index.Name
+		if indexes[index.Name] {
+			errs = append(errs, errors.New(fmt.Sprintf("index:%s is declared more than once", index.Name)))
+		}
+		if !validTableName.MatchString(index.Name) {
+			errs = append(errs, errors.New(fmt.Sprintf("invalid index name:%q", index.Name)))
+		}
+		indexes[index.Name] = true
+		if index.PartitionKey != "" && index.PartitionKey != s.PartitionKey {
+			errs = append(errs, errors.New(fmt.Sprintf("%s must share the partition key:%s of the table", owner, s.PartitionKey)))
+		}
+		if index.SortKey == "" {
+			errs = append(errs, errors.New(fmt.Sprintf("%s has no sort key", owner)))
+		}
+		errs = append(errs, validateKeys(owner, KeySpec{PartitionKey: s.PartitionKey, SortKey: index.SortKey}, defined, used)...)
+		errs = append(errs, validateProjection(owner, index)...)
+		if index.ReadCapacity != 0 || index.WriteCapacity != 0 {
+			errs = append(errs, errors.New(fmt.Sprintf("%s has a capacity, local indexes share the capacity of the table", owner)))
+		}
+	}
+
+	for _, attribute := range s.Attributes {
+		if !used[attribute.Name] {
+			errs = append(errs, errors.New(fmt.Sprintf("attribute:%s is defined but used by no key schema", attribute.Name)))
+		}
+	}
+
+	if s.Stream != "" && !isOneOf(s.Stream, types.StreamViewType("").Values()) {
+		errs = append(errs, errors.New(fmt.Sprintf("invalid stream view type:%s", s.Stream)))
+	}
+	// AES256 is a valid SSE type of the API, but CreateTable only accepts KMS: the key owned by DynamoDB is used without encryption
+	if s.Encryption != nil && s.Encryption.Type != string(types.SSETypeKms) {
+		errs = append(errs, errors.New(fmt.Sprintf("invalid encryption type:%q, KMS is expected, leave the encryption out for the key owned by DynamoDB", s.Encryption.Type)))
+	}
+	if s.TableClass != "" && !isOneOf(s.TableClass, types.TableClass("").Values()) {
+		errs = append(errs, errors.New(fmt.Sprintf("invalid table class:%s", s.TableClass)))
+	}
+	return errors.Join(errs...)
+}
+
+// keySchema converts the key attribute names into a key schema.
+func keySchema(keys KeySpec) []types.KeySchemaElement {
+	schema := []types.KeySchemaElement{{AttributeName: aws.String(keys.PartitionKey), KeyType: types.KeyTypeHash}}
+	if keys.SortKey != "" {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(keys.SortKey), KeyType: types.KeyTypeRange})
+	}
+	return schema
+}
+
+// throughput returns the provisioned capacity, falling back to client.DefaultRcu and client.DefaultWcu.
+func throughput(capacity CapacitySpec) *types.ProvisionedThroughput {
+	rcu, wcu := capacity.ReadCapacity, capacity.WriteCapacity
+	if rcu == 0 {
+		rcu = client.DefaultRcu
+	}
+	if wcu == 0 {
+		wcu = client.DefaultWcu
+	}
+	return &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(rcu), WriteCapacityUnits: aws.Int64(wcu)}
+}
+
+// projection converts the projection of an index, ALL if not set.
+func projection(index IndexSpec) *types.Projection {
+	projectionType := types.ProjectionTypeAll
+	if index.Projection != "" {
+		projectionType = types.ProjectionType(index.Projection)
+	}
+	return &types.Projection{ProjectionType: projectionType, NonKeyAttributes: index.NonKeyAttributes}
+}
+
+// Definition converts a validated spec into the definition creating the table.
+func (s *Spec) Definition() *Definition {
+	input := &dynamodb.CreateTableInput{
+		TableName:                 aws.String(s.Name),
+		KeySchema:                 keySchema(s.KeySpec),
+		BillingMode:               types.BillingModeProvisioned,
+		DeletionProtectionEnabled: aws.Bool(s.DeletionProtection),
+		TableClass:                types.TableClass(s.TableClass),
+	}
+	if s.onDemand() {
+		input.BillingMode = types.BillingModePayPerRequest
+	} else {
+		input.ProvisionedThroughput = throughput(s.CapacitySpec)
+	}
+	for _, attribute := range s.Attributes {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(attribute.Name),
+			AttributeType: types.ScalarAttributeType(attribute.Type),
+		})
+	}
+	for _, index := range s.GlobalSecondaryIndexes {
+		gsi := types.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  keySchema(index.KeySpec),
+			Projection: projection(index),
+		}
+		if !s.onDemand() {
+			gsi.ProvisionedThroughput = throughput(index.CapacitySpec)
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+	for _, index := range s.LocalSecondaryIndexes {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  keySchema(KeySpec{PartitionKey: s.PartitionKey, SortKey: index.SortKey}),
+			Projection: projection(index),
+		})
+	}
+	if s.Stream != "" {
+		input.StreamSpecification = &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: types.StreamViewType(s.Stream)}
+	}
+	if s.Encryption != nil {
+		input.SSESpecification = &types.SSESpecification{Enabled: aws.Bool(true), SSEType: types.SSEType(s.Encryption.Type)}
+		if s.Encryption.KMSKeyId != "" {
+			input.SSESpecification.KMSMasterKeyId = aws.String(s.Encryption.KMSKeyId)
+		}
+	}
+	keys := make([]string, 0, len(s.Tags))
+	for key := range s.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(key), Value: aws.String(s.Tags[key])})
+	}
+
+	definition := &Definition{Input: input, PointInTimeRecovery: s.PointInTimeRecovery}
+	if s.TimeToLive != "" {
+		definition.TimeToLive = &types.TimeToLiveSpecification{AttributeName: aws.String(s.TimeToLive), Enabled: aws.Bool(true)}
+	}
+	return definition
+}
+
+// keySpec converts a key schema into the key attribute names.
+func keySpec(schema []types.KeySchemaElement) KeySpec {
+	hashKey, rangeKey := keyAttributes(schema)
+	return KeySpec{PartitionKey: hashKey, SortKey: rangeKey}
+}
+
+// capacitySpec converts a provisioned throughput into a capacity.
+func capacitySpec(throughput *types.ProvisionedThroughput) CapacitySpec {
+	if throughput == nil {
+		return CapacitySpec{}
+	}
+	return CapacitySpec{ReadCapacity: aws.ToInt64(throughput.ReadCapacityUnits), WriteCapacity: aws.ToInt64(throughput.WriteCapacityUnits)}
+}
+
+// indexProjection sets the projection of an index spec, ALL is left out as the default.
+func indexProjection(index *IndexSpec, p *types.Projection) {
+	if p != nil && p.ProjectionType != types.ProjectionTypeAll {
+		index.Projection, index.NonKeyAttributes = string(p.ProjectionType), p.NonKeyAttributes
+	}
+}
+
+// NewSpec converts the definition of a table into its spec.
+func NewSpec(definition *Definition) *Spec {
+	input := definition.Input
+	spec := &Spec{
+		Name:                aws.ToString(input.TableName),
+		KeySpec:             keySpec(input.KeySchema),
+		BillingMode:         string(input.BillingMode),
+		CapacitySpec:        capacitySpec(input.ProvisionedThroughput),
+		PointInTimeRecovery: definition.PointInTimeRecovery,
+		TableClass:          string(input.TableClass),
+		DeletionProtection:  aws.ToBool(input.DeletionProtectionEnabled),
+	}
+	for _, attribute := range input.AttributeDefinitions {
+		spec.Attributes = append(spec.Attributes, AttributeSpec{Name: aws.ToString(attribute.AttributeName), Type: string(attribute.AttributeType)})
+	}
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		index := IndexSpec{Name: aws.ToString(gsi.IndexName), KeySpec: keySpec(gsi.KeySchema), CapacitySpec: capacitySpec(gsi.ProvisionedThroughput)}
+		indexProjection(&index, gsi.Projection)
+		spec.GlobalSecondaryIndexes = append(spec.GlobalSecondaryIndexes, index)
+	}
+	for _, lsi := range input.LocalSecondaryIndexes {
+		index := IndexSpec{Name: aws.ToString(lsi.IndexName), KeySpec: KeySpec{SortKey: keySpec(lsi.KeySchema).SortKey}}
+		indexProjection(&index, lsi.Projection)
+		spec.LocalSecondaryIndexes = append(spec.LocalSecondaryIndexes, index)
+	}
+	if stream := input.StreamSpecification; stream != nil && aws.ToBool(stream.StreamEnabled) {
+		spec.Stream = string(stream.StreamViewType)
+	}
+	if ttl := definition.TimeToLive; ttl != nil {
+		spec.TimeToLive = aws.ToString(ttl.AttributeName)
+	}
+	if sse := input.SSESpecification; sse != nil && aws.ToBool(sse.Enabled) {
+		spec.Encryption = &EncryptionSpec{Type: string(sse.SSEType), KMSKeyId: aws.ToString(sse.KMSMasterKeyId)}
+	}
+	if len(input.Tags) > 0 {
+		spec.Tags = make(map[string]string, len(input.Tags))
+		for _, tag := range input.Tags {
+			spec.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	return spec
+}
+
+// WriteSpecs writes the specs of the definitions as YAML documents, or with the JSON output format
+// as JSON, an object for a single table or an array of objects.
+func WriteSpecs(w io.Writer, definitions []*Definition, format string) error {
+	specs := make([]*Spec, 0, len(definitions))
+	for _, definition := range definitions {
+		specs = append(specs, NewSpec(definition))
+	}
+
+	if format == output.JSON {
+		if len(specs) == 1 {
+			return output.WriteJSON(w, specs[0])
+		}
+		return output.WriteJSON(w, specs)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	for _, spec := range specs {
+		if err := encoder.Encode(spec); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// LoadSpecs reads the table specs of a YAML or JSON file: one or more YAML documents, or a JSON object or array of objects.
+// Unknown fields are rejected, so that a misspelled setting isn't silently ignored.
+// It returns the specs and an error if the file can't be read or parsed.
+func LoadSpecs(file string) ([]Spec, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read spec file:%s - error:%v", file, err))
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	var specs []Spec
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = decoder.Decode(&specs)
+	} else {
+		for {
+			var spec Spec
+			if err = decoder.Decode(&spec); err != nil {
+				break
+			}
+			specs = append(specs, spec)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to parse spec file:%s - error:%v", file, err))
+	}
+	if len(specs) == 0 {
+		return nil, errors.New(fmt.Sprintf("No table spec in file:%s", file))
+	}
+	return specs, nil
+}
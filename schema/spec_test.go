@@ -0,0 +1,110 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+// validSpec returns a valid spec of a provisioned table with a global and a local secondary index.
+func validSpec() Spec {
+	return Spec{
+		Name: "orders",
+		Attributes: []AttributeSpec{
+			{Name: "customerId", Type: "S"},
+			{Name: "orderId", Type: "S"},
+			{Name: "status", Type: "S"},
+			{Name: "total", Type: "N"},
+		},
+		KeySpec:      KeySpec{PartitionKey: "customerId", SortKey: "orderId"},
+		CapacitySpec: CapacitySpec{ReadCapacity: 10, WriteCapacity: 5},
+		GlobalSecondaryIndexes: []IndexSpec{{
+			Name:             "by-status",
+			KeySpec:          KeySpec{PartitionKey: "status", SortKey: "orderId"},
+			Projection:       "INCLUDE",
+			NonKeyAttributes: []string{"total"},
+		}},
+		LocalSecondaryIndexes: []IndexSpec{{
+			Name:       "by-total",
+			KeySpec:    KeySpec{SortKey: "total"},
+			Projection: "KEYS_ONLY",
+		}},
+		Stream:     "NEW_AND_OLD_IMAGES",
+		Encryption: &EncryptionSpec{Type: "KMS", KMSKeyId: "alias/orders"},
+		TableClass: "STANDARD_INFREQUENT_ACCESS",
+	}
+}
+
+func TestSpecValidate(t *testing.T) {
+	if err := (&Spec{Name: "minimal", Attributes: []AttributeSpec{{Name: "id", Type: "S"}}, KeySpec: KeySpec{PartitionKey: "id"}}).Validate(); err != nil {
+		t.Errorf("Validate() of a minimal spec error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *Spec)
+		want   string // Part of the error message, empty if the spec is valid
+	}{
+		{"valid", func(s *Spec) {}, ""},
+		{"on-demand", func(s *Spec) { s.BillingMode, s.CapacitySpec = "PAY_PER_REQUEST", CapacitySpec{} }, ""},
+		{"invalid table name", func(s *Spec) { s.Name = "o" }, "invalid table name"},
+		{"duplicate attribute", func(s *Spec) { s.Attributes = append(s.Attributes, AttributeSpec{Name: "status", Type: "S"}) }, "defined more than once"},
+		{"invalid attribute type", func(s *Spec) { s.Attributes[3].Type = "BOOL" }, "invalid type"},
+		{"missing partition key", func(s *Spec) { s.PartitionKey = "" }, "has no partition key"},
+		{"undefined key attribute", func(s *Spec) { s.SortKey = "createdAt" }, "isn't defined in the attributes"},
+		{"unused attribute", func(s *Spec) { s.Attributes = append(s.Attributes, AttributeSpec{Name: "note", Type: "S"}) }, "used by no key schema"},
+		{"invalid billing mode", func(s *Spec) { s.BillingMode = "ON_DEMAND" }, "invalid billing mode"},
+		{"negative capacity", func(s *Spec) { s.ReadCapacity = -1 }, "negative capacity"},
+		{"capacity of an on-demand table", func(s *Spec) { s.BillingMode = "PAY_PER_REQUEST" }, "has a provisioned capacity"},
+		{"duplicate index", func(s *Spec) { s.LocalSecondaryIndexes[0].Name = "by-status" }, "declared more than once"},
+		{"include without attributes", func(s *Spec) { s.GlobalSecondaryIndexes[0].NonKeyAttributes = nil }, "without nonKeyAttributes"},
+		{"attributes without include", func(s *Spec) { s.LocalSecondaryIndexes[0].NonKeyAttributes = []string{"note"} }, "doesn't project INCLUDE"},
+		{"invalid projection", func(s *Spec) { s.GlobalSecondaryIndexes[0].Projection = "SOME" }, "invalid projection"},
+		{"local index without table sort key", func(s *Spec) { s.SortKey = "" }, "no sort key"},
+		{"local index with another partition key", func(s *Spec) { s.LocalSecondaryIndexes[0].PartitionKey = "status" }, "must share the partition key"},
+		{"local index with capacity", func(s *Spec) { s.LocalSecondaryIndexes[0].ReadCapacity = 5 }, "share the capacity of the table"},
+		{"too many global indexes", func(s *Spec) {
+			for i := 0; i < maxGlobalIndexes; i++ {
+				s.GlobalSecondaryIndexes = append(s.GlobalSecondaryIndexes, IndexSpec{Name: "by-status-" + strings.Repeat("x", i+1), KeySpec: KeySpec{PartitionKey: "status"}})
+			}
+		}, "at most 20 are allowed"},
+		{"invalid stream", func(s *Spec) { s.Stream = "ALL" }, "invalid stream view type"},
+		{"AES256 encryption", func(s *Spec) { s.Encryption = &EncryptionSpec{Type: "AES256"} }, "KMS is expected"},
+		{"invalid table class", func(s *Spec) { s.TableClass = "COLD" }, "invalid table class"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := validSpec()
+			tt.modify(&spec)
+			err := spec.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate() error = %v", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("Validate() returned no error, want %q", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecDefinition(t *testing.T) {
+	spec := validSpec()
+	input := spec.Definition().Input
+	if input.ProvisionedThroughput == nil || *input.ProvisionedThroughput.ReadCapacityUnits != 10 {
+		t.Errorf("ProvisionedThroughput = %+v, want 10 read capacity units", input.ProvisionedThroughput)
+	}
+	if len(input.GlobalSecondaryIndexes) != 1 || input.GlobalSecondaryIndexes[0].ProvisionedThroughput == nil {
+		t.Errorf("GlobalSecondaryIndexes = %+v, want one index with a provisioned capacity", input.GlobalSecondaryIndexes)
+	}
+	if sse := input.SSESpecification; sse == nil || string(sse.SSEType) != "KMS" || *sse.KMSMasterKeyId != "alias/orders" {
+		t.Errorf("SSESpecification = %+v, want KMS with alias/orders", sse)
+	}
+
+	spec.BillingMode, spec.CapacitySpec = "PAY_PER_REQUEST", CapacitySpec{}
+	input = spec.Definition().Input
+	if input.ProvisionedThroughput != nil || input.GlobalSecondaryIndexes[0].ProvisionedThroughput != nil {
+		t.Error("the definition of an on-demand table has a provisioned capacity")
+	}
+}
@@ -8,7 +8,7 @@ require (
 )
 
 require (
-	github.com/ForrestIsARealGoodman/dynamodb-manager/logging v0.0.0-20240221102801-90c8c4ef4e1f // indirect
+	github.com/ForrestIsARealGoodman/dynamodb-manager/logging v0.0.0-20240221110741-558121082fe7 // indirect
 	github.com/aws/aws-sdk-go-v2 v1.25.0 // indirect
 	github.com/aws/aws-sdk-go-v2/config v1.27.1 // indirect
 	github.com/aws/aws-sdk-go-v2/credentials v1.17.1 // indirect
@@ -28,3 +28,8 @@ require (
 	go.uber.org/multierr v1.10.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
 )
+
+replace (
+	github.com/ForrestIsARealGoodman/dynamodb-manager/client => ../client
+	github.com/ForrestIsARealGoodman/dynamodb-manager/logging => ../logging
+)
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EnvPlaceholder is the placeholder of the environment in a naming template
+const EnvPlaceholder = "{env}"
+
+// placeholder matches the placeholders of a naming template, e.g. {service}
+var placeholder = regexp.MustCompile(`\{[a-zA-Z0-9_]+\}`)
+
+// NameTemplate is the naming convention of the tables, e.g. {env}-{service}-{entity}.
+// The {env} placeholder stands for the environment, the other placeholders for any non-empty text.
+type NameTemplate struct {
+	template string
+	parts    []string // Literal texts and placeholders of the template, in order
+}
+
+// ParseNameTemplate parses a naming template, which must hold the {env} placeholder exactly once.
+// It returns the template and an error if the template is invalid.
+func ParseNameTemplate(template string) (*NameTemplate, error) {
+	if strings.Count(template, EnvPlaceholder) != 1 {
+		return nil, errors.New(fmt.Sprintf("invalid naming template:%s, the %s placeholder is expected exactly once", template, EnvPlaceholder))
+	}
+
+	t := &NameTemplate{template: template}
+	last := 0
+	for _, loc := range placeholder.FindAllStringIndex(template, -1) {
+		if loc[0] > last {
+			t.parts = append(t.parts, template[last:loc[0]])
+		}
+		if len(t.parts) > 0 && placeholder.MatchString(t.parts[len(t.parts)-1]) {
+			return nil, errors.New(fmt.Sprintf("invalid naming template:%s, placeholders must be separated", template))
+		}
+		t.parts = append(t.parts, template[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	if last < len(template) {
+		t.parts = append(t.parts, template[last:])
+	}
+	return t, nil
+}
+
+// String returns the naming template as it was given.
+func (t *NameTemplate) String() string {
+	return t.template
+}
+
+// matcher builds the expression matching the table names of the given environment, the environment being its only group.
+func (t *NameTemplate) matcher(env string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, part := range t.parts {
+		switch {
+		case part == EnvPlaceholder:
+			expr.WriteString("(" + regexp.QuoteMeta(env) + ")")
+		case placeholder.MatchString(part):
+			expr.WriteString("(?:.+)")
+		default:
+			expr.WriteString(regexp.QuoteMeta(part))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// Strip replaces the environment in the name of a table of that environment with the {env} placeholder,
+// e.g. prod-orders-items becomes {env}-orders-items for the prod environment, so the tables of several environments can be paired.
+// It returns false if the name doesn't follow the template for the environment.
+func (t *NameTemplate) Strip(tableName string, env string) (string, bool) {
+	loc := t.matcher(env).FindStringSubmatchIndex(tableName)
+	if loc == nil {
+		return "", false
+	}
+	return tableName[:loc[2]] + EnvPlaceholder + tableName[loc[3]:], true
+}
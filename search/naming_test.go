@@ -0,0 +1,46 @@
+package search
+
+import "testing"
+
+func TestParseNameTemplate(t *testing.T) {
+	for _, template := range []string{"{env}-{service}-{entity}", "orders_{env}", "{env}"} {
+		if _, err := ParseNameTemplate(template); err != nil {
+			t.Errorf("ParseNameTemplate(%s) error = %v", template, err)
+		}
+	}
+	for _, template := range []string{"{service}-{entity}", "{env}-{env}", "{env}{service}"} {
+		if _, err := ParseNameTemplate(template); err == nil {
+			t.Errorf("ParseNameTemplate(%s) returned no error", template)
+		}
+	}
+}
+
+func TestNameTemplateStrip(t *testing.T) {
+	tests := []struct {
+		template string
+		table    string
+		env      string
+		want     string // Empty if the name doesn't follow the template for the environment
+	}{
+		{"{env}-{service}-{entity}", "prod-orders-items", "prod", "{env}-orders-items"},
+		{"{env}-{service}-{entity}", "prod-orders-line-items", "prod", "{env}-orders-line-items"},
+		{"{env}-{service}-{entity}", "dev-orders-items", "prod", ""},
+		{"{env}-{service}-{entity}", "prod-orders", "prod", ""},
+		{"{service}.{env}", "orders.prod", "prod", "orders.{env}"},
+		{"{service}.{env}", "orders-prod", "prod", ""},
+		{"{service}-{env}", "prod-prod", "prod", "prod-{env}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			template, err := ParseNameTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := template.Strip(tt.table, tt.env)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Errorf("Strip(%s, %s) = %q, %t, want %q", tt.table, tt.env, got, ok, tt.want)
+			}
+		})
+	}
+}
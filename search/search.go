@@ -1,6 +1,8 @@
 package search
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
@@ -43,18 +45,20 @@ func FuzzyMatchRatio(str1 string, str2 string) int {
 }
 
 // searchTablesByFuzzyName searches DynamoDB tables by fuzzy name using the provided DynamoDBManager.
-// It takes a DynamoDBManager and a fuzzy name as input and returns a slice of matching tables.
-func searchTablesByFuzzyName(dbmgr *client.DynamoDBManager, fuzzyName string) []map[string]string {
+// It takes a DynamoDBManager and a fuzzy name as input and returns a slice of matching tables,
+// and an error wrapping client.ErrPartialFailure if some of the tables had to be skipped.
+func searchTablesByFuzzyName(dbmgr *client.DynamoDBManager, fuzzyName string) ([]map[string]string, error) {
 	// Get the list of table names
 	tableList, err := GetTableListClient(dbmgr)
 	if err != nil {
 		dbmgr.Logger.Errorf("Error finding DynamoDB tables: %v", err)
-		return nil
+		return nil, err
 	}
 
 	// Perform fuzzy search and filter matching tables
 	dbmgr.Logger.Info("searchTablesByFuzzyName before")
 	matchingTables := make([]map[string]string, 0)
+	var skipped []string
 	for _, tableName := range tableList {
 		if !strings.Contains(tableName, fuzzyName) {
 			fuzzyRatio := FuzzyMatchRatio(strings.ToLower(fuzzyName), strings.ToLower(tableName))
@@ -67,21 +71,33 @@ func searchTablesByFuzzyName(dbmgr *client.DynamoDBManager, fuzzyName string) []
 		tableArn, err := GetTableArnClient(dbmgr, tableName)
 		if err != nil {
 			dbmgr.Logger.Warnf("Error getting table ARN: %v", err)
+			skipped = append(skipped, tableName)
 			continue
 		}
 		dbmgr.Logger.Infof("searchTablesByFuzzyName: fuzzyname:%s - tablename:%s - tableArn: %s\n", strings.ToLower(fuzzyName), strings.ToLower(tableName), tableArn)
 		matchingTables = append(matchingTables, map[string]string{"Name": tableName, "ARN": tableArn})
 
 	}
-	return matchingTables
+	return matchingTables, partialFailure(skipped)
+}
+
+// partialFailure returns an error wrapping client.ErrPartialFailure which lists the skipped tables.
+// It returns nil if no table was skipped.
+func partialFailure(skipped []string) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d table(s) skipped: %s - %w", len(skipped), strings.Join(skipped, ","), client.ErrPartialFailure)
 }
 
 // searchTablesByTagValue searches DynamoDB tables by tag value using the provided DynamoDBManager and a list of table names.
-// It takes a DynamoDBManager, a tag value, and a slice of table names as input and returns a slice of matching tables.
-func searchTablesByTagValue(dbmgr *client.DynamoDBManager, tagValue string, tableList []string) []map[string]string {
+// It takes a DynamoDBManager, a tag value, and a slice of table names as input and returns a slice of matching tables,
+// and an error wrapping client.ErrPartialFailure if some of the tables had to be skipped.
+func searchTablesByTagValue(dbmgr *client.DynamoDBManager, tagValue string, tableList []string) ([]map[string]string, error) {
 	var matchingTables []map[string]string
 	var tableListTag []string
 	var errGetTable error
+	var skipped []string
 
 	if tableList != nil {
 		tableListTag = make([]string, len(tableList))
@@ -90,7 +106,7 @@ func searchTablesByTagValue(dbmgr *client.DynamoDBManager, tagValue string, tabl
 		tableListTag, errGetTable = GetTableListClient(dbmgr)
 		if errGetTable != nil {
 			dbmgr.Logger.Errorf("Error finding DynamoDB tables: %v", errGetTable)
-			return nil
+			return nil, errGetTable
 		}
 	}
 
@@ -100,12 +116,14 @@ func searchTablesByTagValue(dbmgr *client.DynamoDBManager, tagValue string, tabl
 		tableArn, err := GetTableArnClient(dbmgr, tableName)
 		if err != nil {
 			dbmgr.Logger.Warnf("Error getting table ARN: %v", err)
+			skipped = append(skipped, tableName)
 			continue
 		}
 
 		tags, errTag := GetTableTagsClient(dbmgr, tableArn)
 		if errTag != nil {
 			dbmgr.Logger.Warnf("Get tags for arn:%s, failed due to:%v", tableArn, errTag)
+			skipped = append(skipped, tableName)
 			continue
 		}
 		// Check if tagValue matches any tag in the list
@@ -118,17 +136,21 @@ func searchTablesByTagValue(dbmgr *client.DynamoDBManager, tagValue string, tabl
 		}
 	}
 
-	return matchingTables
+	return matchingTables, partialFailure(skipped)
 }
 
 // ExecuteSearch performs a search operation based on the provided conditions such as fuzzy table name and tag value.
 // It takes a DynamoDBManager, a fuzzy table name, and a tag value as input and returns a slice of matching tables.
-func ExecuteSearch(dbmgr *client.DynamoDBManager, tableFuzzyName string, tagValue string) []map[string]string {
+// The returned error wraps client.ErrPartialFailure if some tables were skipped, the matching tables are still returned then.
+func ExecuteSearch(dbmgr *client.DynamoDBManager, tableFuzzyName string, tagValue string) ([]map[string]string, error) {
 	var matchingTables []map[string]string
+	var err error
 	if tableFuzzyName != "" && tagValue != "" {
 		dbmgr.Logger.Infof("Begin to search the matched tables via fuzzy name:%s, tag:%s, ...", tableFuzzyName, tagValue)
-		fuzzyMatchingTables := []map[string]string{}
-		fuzzyMatchingTables = searchTablesByFuzzyName(dbmgr, tableFuzzyName)
+		fuzzyMatchingTables, errFuzzy := searchTablesByFuzzyName(dbmgr, tableFuzzyName)
+		if fuzzyMatchingTables == nil {
+			return nil, errFuzzy
+		}
 		var tableList []string
 		for _, entry := range fuzzyMatchingTables {
 			name, exists := entry["Name"]
@@ -136,16 +158,26 @@ func ExecuteSearch(dbmgr *client.DynamoDBManager, tableFuzzyName string, tagValu
 				tableList = append(tableList, name)
 			}
 		}
-		matchingTables = searchTablesByTagValue(dbmgr, tagValue, tableList)
+		if len(tableList) > 0 {
+			var errTag error
+			matchingTables, errTag = searchTablesByTagValue(dbmgr, tagValue, tableList)
+			err = errors.Join(errFuzzy, errTag)
+		} else {
+			err = errFuzzy
+		}
 	} else if tableFuzzyName != "" {
 		dbmgr.Logger.Infof("Begin to search the matched tables via fuzzy name:%s, ...", tableFuzzyName)
-		matchingTables = searchTablesByFuzzyName(dbmgr, tableFuzzyName)
+		matchingTables, err = searchTablesByFuzzyName(dbmgr, tableFuzzyName)
 	} else if tagValue != "" {
 		dbmgr.Logger.Infof("Begin to search the matched tables via tag:%s, ...", tagValue)
-		matchingTables = searchTablesByTagValue(dbmgr, tagValue, nil)
+		matchingTables, err = searchTablesByTagValue(dbmgr, tagValue, nil)
 	} else {
 		dbmgr.Logger.Error("Invalid search conditions: search table name or tag value should not be empty!")
-		return nil
+		return nil, errors.New("search table name or tag value should not be empty!")
+	}
+
+	if matchingTables == nil && err != nil && !errors.Is(err, client.ErrPartialFailure) {
+		return nil, err
 	}
 
 	if matchingTables == nil || len(matchingTables) == 0 {
@@ -157,5 +189,5 @@ func ExecuteSearch(dbmgr *client.DynamoDBManager, tableFuzzyName string, tagValu
 		dbmgr.Logger.Infof("Table Name: %s, ARN: %s\n", table["Name"], table["ARN"])
 	}
 
-	return matchingTables
+	return matchingTables, err
 }
@@ -0,0 +1,269 @@
+package tagging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/outcome"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/output"
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/prompt"
+)
+
+// Tag operations
+const (
+	Add    string = "add"    // Add the tags, overwriting the values of existing keys
+	Remove string = "remove" // Remove the tags with the given keys
+	Set    string = "set"    // Replace all the tags of the table with the given ones, but the aws: ones
+)
+
+// Statuses of a table change
+const (
+	StatusApplied   string = "applied"
+	StatusPending   string = "pending"
+	StatusUnchanged string = "unchanged"
+	StatusFailed           = outcome.StatusFailed
+)
+
+var (
+	GetTableArnClient  = client.GetTableArn
+	GetTableTagsClient = client.GetTableTags
+	TagTableClient     = client.TagTable
+	UntagTableClient   = client.UntagTable
+)
+
+// TagUpdate is a tag whose value changes.
+type TagUpdate struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// TableChange describes the tag changes of a table and whether they were applied.
+type TableChange struct {
+	Table   string               `json:"table"`
+	Arn     string               `json:"arn,omitempty"`
+	Added   map[string]string    `json:"added,omitempty"`
+	Updated map[string]TagUpdate `json:"updated,omitempty"`
+	Removed []string             `json:"removed,omitempty"`
+	outcome.Outcome
+}
+
+// HasChanges reports whether the table has any tag to add, update or remove.
+func (c *TableChange) HasChanges() bool {
+	return len(c.Added) > 0 || len(c.Updated) > 0 || len(c.Removed) > 0
+}
+
+// TagsToApply returns the added and updated tags with their new values.
+func (c *TableChange) TagsToApply() map[string]string {
+	tags := make(map[string]string, len(c.Added)+len(c.Updated))
+	for key, value := range c.Added {
+		tags[key] = value
+	}
+	for key, update := range c.Updated {
+		tags[key] = update.New
+	}
+	return tags
+}
+
+// Summary formats the changes as "+key=value ~key=old->new -key", sorted by key.
+func (c *TableChange) Summary() string {
+	var changes []string
+	for key, value := range c.Added {
+		changes = append(changes, fmt.Sprintf("+%s=%s", key, value))
+	}
+	for key, update := range c.Updated {
+		changes = append(changes, fmt.Sprintf("~%s=%s->%s", key, update.Old, update.New))
+	}
+	for _, key := range c.Removed {
+		changes = append(changes, fmt.Sprintf("-%s", key))
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i][1:] < changes[j][1:] })
+	return strings.Join(changes, " ")
+}
+
+// ParseTags parses tags given as "key=value" arguments.
+// It returns the tags and an error if an argument is malformed, a key is reserved for AWS or a key is repeated.
+func ParseTags(args []string) (map[string]string, error) {
+	tags := make(map[string]string, len(args))
+	for _, arg := range args {
+		idx := strings.Index(arg, "=")
+		if idx <= 0 {
+			return nil, errors.New(fmt.Sprintf("invalid tag:%s, key=value is expected", arg))
+		}
+		key, value := arg[:idx], arg[idx+1:]
+		if client.IsReservedTag(key) {
+			return nil, errors.New(fmt.Sprintf("tag key reserved for AWS:%s, the %s prefix can't be set", key, client.ReservedTagPrefix))
+		}
+		if _, exists := tags[key]; exists {
+			return nil, errors.New(fmt.Sprintf("tag key provided more than once:%s", key))
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// PlanChange computes the changes the operation makes to the current tags of a table.
+// 'tags' holds the tags to add or set, 'keys' the keys to remove. Set keeps the tags reserved for AWS.
+func PlanChange(operation string, current map[string]string, tags map[string]string, keys []string) TableChange {
+	change := TableChange{
+		Added:   map[string]string{},
+		Updated: map[string]TagUpdate{},
+	}
+
+	switch operation {
+	case Add, Set:
+		for key, value := range tags {
+			oldValue, exists := current[key]
+			if !exists {
+				change.Added[key] = value
+			} else if oldValue != value {
+				change.Updated[key] = TagUpdate{Old: oldValue, New: value}
+			}
+		}
+		if operation == Set {
+			for key := range current {
+				// The tags reserved for AWS can't be removed, they are kept
+				if _, exists := tags[key]; !exists && !client.IsReservedTag(key) {
+					change.Removed = append(change.Removed, key)
+				}
+			}
+		}
+	case Remove:
+		for _, key := range keys {
+			if _, exists := current[key]; exists {
+				change.Removed = append(change.Removed, key)
+			}
+		}
+	}
+	sort.Strings(change.Removed)
+	return change
+}
+
+// GetCurrentTags retrieves the ARN and the tags of a table as a map.
+// It returns the ARN, the tags and an error.
+func GetCurrentTags(dbmgr *client.DynamoDBManager, tableName string) (string, map[string]string, error) {
+	tableArn, err := GetTableArnClient(dbmgr, tableName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tags, err := GetTableTagsClient(dbmgr, tableArn)
+	if err != nil {
+		return tableArn, nil, err
+	}
+
+	current := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		current[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tableArn, current, nil
+}
+
+// ApplyChange tags and untags the table according to the change, and updates its status.
+// It returns an error if any of the calls fails.
+func ApplyChange(dbmgr *client.DynamoDBManager, change *TableChange) error {
+	var err error
+	if tags := change.TagsToApply(); len(tags) > 0 {
+		err = TagTableClient(dbmgr, change.Arn, tags)
+	}
+	if err == nil && len(change.Removed) > 0 {
+		err = UntagTableClient(dbmgr, change.Arn, change.Removed)
+	}
+
+	if err != nil {
+		change.Fail(err)
+		return err
+	}
+	change.Status = StatusApplied
+	dbmgr.Logger.Infof("Tags of table:%s updated: %s", change.Table, change.Summary())
+	return nil
+}
+
+// ApplyChanges applies the pending changes once the plan is shown and confirmed, or only reports them with dryRun set.
+// It returns an error wrapping client.ErrPendingChanges for a dry run with pending changes,
+// client.ErrPartialFailure if some tables failed, or the error of the failure if all of them failed.
+func ApplyChanges(dbmgr *client.DynamoDBManager, changes []TableChange, dryRun bool, yes bool) error {
+	pending := outcome.CountStatus(changes, StatusPending)
+	if pending > 0 && dryRun {
+		return fmt.Errorf("tag changes pending for %d table(s) - %w", pending, client.ErrPendingChanges)
+	}
+
+	if pending > 0 {
+		plan := func(w io.Writer) error { return WriteReport(w, changes, output.Text) }
+		if err := prompt.ApprovePlan(yes, fmt.Sprintf("Apply the tag changes to %d table(s)?", pending), plan); err != nil {
+			return err
+		}
+	}
+
+	for i := range changes {
+		if changes[i].Status == StatusPending {
+			ApplyChange(dbmgr, &changes[i])
+		}
+	}
+
+	return outcome.Summarize("tag changes", "table(s)", changes)
+}
+
+// WriteReport writes the changes of every table in the given output format.
+func WriteReport(w io.Writer, changes []TableChange, format string) error {
+	if format == output.JSON {
+		return output.WriteJSON(w, changes)
+	}
+
+	tw := output.NewTabWriter(w)
+	fmt.Fprintf(tw, "TABLE\tSTATUS\tCHANGES\n")
+	for _, change := range changes {
+		details := change.Summary()
+		if change.Error != "" {
+			details = change.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", change.Table, change.Status, details)
+	}
+	return tw.Flush()
+}
+
+// ExecuteTag adds, removes or sets the tags of the given tables.
+// It takes a DynamoDBManager, the tag operation, the table names, the tags to add or set, the keys to remove,
+// the dry-run and confirmation flags and the output format of the report as input.
+// Tables whose tags already match are left unchanged, the others are changed once the user confirms, unless 'yes' is set.
+// It returns an error as described by ApplyChanges.
+func ExecuteTag(dbmgr *client.DynamoDBManager, operation string, tables []string, tags map[string]string, keys []string, dryRun bool, yes bool, format string) error {
+	if operation != Add && operation != Remove && operation != Set {
+		return errors.New(fmt.Sprintf("unrecognized tag operation provided:%s", operation))
+	}
+
+	changes := make([]TableChange, 0, len(tables))
+	for _, tableName := range tables {
+		tableArn, current, err := GetCurrentTags(dbmgr, tableName)
+		if err != nil {
+			dbmgr.Logger.Warnf("Get tags of table:%s, failed due to:%v", tableName, err)
+			change := TableChange{Table: tableName, Arn: tableArn}
+			change.Fail(err)
+			changes = append(changes, change)
+			continue
+		}
+
+		change := PlanChange(operation, current, tags, keys)
+		change.Table, change.Arn = tableName, tableArn
+		change.Status = StatusUnchanged
+		if change.HasChanges() {
+			change.Status = StatusPending
+			dbmgr.Logger.Infof("Planned tag changes of table:%s: %s", tableName, change.Summary())
+		}
+		changes = append(changes, change)
+	}
+
+	err := ApplyChanges(dbmgr, changes, dryRun, yes)
+	if errors.Is(err, prompt.ErrAborted) {
+		return err
+	}
+	if errWrite := WriteReport(output.Stdout, changes, format); errWrite != nil {
+		return errors.Join(err, errWrite)
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package tagging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string // Part of the error message, empty if the tags are valid
+	}{
+		{"valid", []string{"env=prod", "team=data", "empty="}, ""},
+		{"missing value", []string{"env"}, "key=value is expected"},
+		{"missing key", []string{"=prod"}, "key=value is expected"},
+		{"repeated key", []string{"env=prod", "env=dev"}, "more than once"},
+		{"reserved key", []string{"aws:cloudformation:stack-name=orders"}, "reserved for AWS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := ParseTags(tt.args)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("ParseTags() error = %v", err)
+			case tt.want == "" && len(tags) != len(tt.args):
+				t.Errorf("ParseTags() = %v, want %d tag(s)", tags, len(tt.args))
+			case tt.want != "" && err == nil:
+				t.Errorf("ParseTags() returned no error, want %q", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("ParseTags() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanChangeKeepsReservedTags(t *testing.T) {
+	current := map[string]string{"env": "dev", "owner": "team", "aws:cloudformation:stack-name": "orders"}
+	change := PlanChange(Set, current, map[string]string{"env": "prod"}, nil)
+	if len(change.Removed) != 1 || change.Removed[0] != "owner" {
+		t.Errorf("PlanChange() removed %v, want [owner]", change.Removed)
+	}
+	if update, exists := change.Updated["env"]; !exists || update.New != "prod" {
+		t.Errorf("PlanChange() updated %v, want env to prod", change.Updated)
+	}
+}
@@ -2,18 +2,21 @@ module github.com/ForrestIsARealGoodman/dynamodb-manager/update
 
 go 1.20
 
-require github.com/ForrestIsARealGoodman/dynamodb-manager/client v0.0.0-20240221110741-558121082fe7
+require (
+	github.com/ForrestIsARealGoodman/dynamodb-manager/client v0.0.0-20240221110741-558121082fe7
+	github.com/aws/aws-sdk-go-v2 v1.25.0
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.29.1
+	github.com/aws/aws-sdk-go-v2/service/kms v1.28.2
+)
 
 require (
-	github.com/ForrestIsARealGoodman/dynamodb-manager/logging v0.0.0-20240221102801-90c8c4ef4e1f // indirect
-	github.com/aws/aws-sdk-go-v2 v1.25.0 // indirect
+	github.com/ForrestIsARealGoodman/dynamodb-manager/logging v0.0.0-20240221110741-558121082fe7 // indirect
 	github.com/aws/aws-sdk-go-v2/config v1.27.1 // indirect
 	github.com/aws/aws-sdk-go-v2/credentials v1.17.1 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.15.0 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.0 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.0 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/ini v1.8.0 // indirect
-	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.29.1 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.11.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/endpoint-discovery v1.9.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.11.0 // indirect
@@ -25,3 +28,8 @@ require (
 	go.uber.org/multierr v1.10.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
 )
+
+replace (
+	github.com/ForrestIsARealGoodman/dynamodb-manager/client => ../client
+	github.com/ForrestIsARealGoodman/dynamodb-manager/logging => ../logging
+)
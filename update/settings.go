@@ -0,0 +1,289 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+
+	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
+)
+
+// Values of the table settings
+const (
+	On              string = "on"
+	Off             string = "off"
+	EncryptionOwned string = "owned" // Encryption with a key owned by DynamoDB, the default
+	EncryptionKMS   string = "kms"   // Encryption with a KMS key, the AWS managed key if none is given
+)
+
+// settingsTimeout is how long an update of the settings waits for the table to become active before the next one
+const settingsTimeout = 30 * time.Minute
+
+var (
+	DescribeTableClient      = client.DescribeTable
+	DescribeTimeToLiveClient = client.DescribeTimeToLive
+	UpdateTableClient        = client.UpdateTable
+	UpdateTimeToLiveClient   = client.UpdateTimeToLive
+	WaitForTableClient       = client.WaitForTable
+	DescribeKeyArnClient     = describeKeyArn
+)
+
+var NewKMSClient = kms.NewFromConfig
+
+// Settings are the table settings to update beyond the capacity, the empty ones are left unchanged.
+type Settings struct {
+	TimeToLive         string // Attribute the items expire on, or Off
+	Stream             string // Stream view type, or Off
+	TableClass         string // STANDARD or STANDARD_INFREQUENT_ACCESS
+	DeletionProtection string // On or Off
+	Encryption         string // EncryptionOwned or EncryptionKMS
+	KMSKey             string // KMS key ID, ARN or alias with EncryptionKMS, the AWS managed key if empty
+}
+
+// IsEmpty reports whether no setting is to be updated.
+func (s *Settings) IsEmpty() bool {
+	return s.TimeToLive == "" && s.Stream == "" && s.TableClass == "" && s.DeletionProtection == "" && s.Encryption == "" && s.KMSKey == ""
+}
+
+// Validate checks the values of the settings.
+// It returns an error if any of them is invalid.
+func (s *Settings) Validate() error {
+	if s.Stream != "" && s.Stream != Off {
+		valid := false
+		for _, viewType := range types.StreamViewType("").Values() {
+			valid = valid || string(viewType) == s.Stream
+		}
+		if !valid {
+			return errors.New(fmt.Sprintf("invalid stream:%s, off or a view type (NEW_IMAGE, OLD_IMAGE, NEW_AND_OLD_IMAGES, KEYS_ONLY) is expected", s.Stream))
+		}
+	}
+	if s.TableClass != "" && s.TableClass != string(types.TableClassStandard) && s.TableClass != string(types.TableClassStandardInfrequentAccess) {
+		return errors.New(fmt.Sprintf("invalid table class:%s, STANDARD or STANDARD_INFREQUENT_ACCESS is expected", s.TableClass))
+	}
+	if s.DeletionProtection != "" && s.DeletionProtection != On && s.DeletionProtection != Off {
+		return errors.New(fmt.Sprintf("invalid deletion protection:%s, on or off is expected", s.DeletionProtection))
+	}
+	if s.Encryption != "" && s.Encryption != EncryptionOwned && s.Encryption != EncryptionKMS {
+		return errors.New(fmt.Sprintf("invalid encryption:%s, owned or kms is expected", s.Encryption))
+	}
+	if s.KMSKey != "" && s.Encryption != EncryptionKMS {
+		return errors.New("a KMS key can only be given with the kms encryption")
+	}
+	return nil
+}
+
+// settingChange is a change of a table setting and the way to apply it.
+type settingChange struct {
+	description string
+	apply       func() error
+}
+
+// updateTable returns the change applying the input to the table and waiting until the table is active again.
+func updateTable(dbmgr *client.DynamoDBManager, description string, input *dynamodb.UpdateTableInput) settingChange {
+	return settingChange{
+		description: description,
+		apply: func() error {
+			if _, err := UpdateTableClient(dbmgr, input); err != nil {
+				return err
+			}
+			// The table accepts the next update once active again
+			return WaitForTableClient(dbmgr, aws.ToString(input.TableName), settingsTimeout)
+		},
+	}
+}
+
+// streamChanges compares the stream of the table with the requested one.
+// A stream can't change its view type while enabled, it is disabled and enabled again then.
+func streamChanges(dbmgr *client.DynamoDBManager, table *types.TableDescription, stream string) []settingChange {
+	tableName := aws.ToString(table.TableName)
+	current := Off
+	if table.StreamSpecification != nil && aws.ToBool(table.StreamSpecification.StreamEnabled) {
+		current = string(table.StreamSpecification.StreamViewType)
+	}
+	if stream == "" || stream == current {
+		return nil
+	}
+
+	var changes []settingChange
+	if current != Off {
+		changes = append(changes, updateTable(dbmgr, fmt.Sprintf("disable stream %s", current), &dynamodb.UpdateTableInput{
+			TableName:           aws.String(tableName),
+			StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(false)},
+		}))
+	}
+	if stream != Off {
+		changes = append(changes, updateTable(dbmgr, fmt.Sprintf("enable stream %s", stream), &dynamodb.UpdateTableInput{
+			TableName:           aws.String(tableName),
+			StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: types.StreamViewType(stream)},
+		}))
+	}
+	return changes
+}
+
+// timeToLiveChanges compares the time to live of the table with the requested one.
+// It returns an error if the time to live is to move to another attribute: it must be disabled first,
+// and DynamoDB rejects any further change of the time to live of a table for an hour.
+func timeToLiveChanges(dbmgr *client.DynamoDBManager, tableName string, ttl *types.TimeToLiveDescription, attribute string) ([]settingChange, error) {
+	current := Off
+	if ttl != nil && (ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabled || ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+		current = aws.ToString(ttl.AttributeName)
+	}
+	switch {
+	case attribute == "" || attribute == current:
+		return nil, nil
+	case attribute == Off:
+		return []settingChange{{
+			description: fmt.Sprintf("disable time to live on %s", current),
+			apply:       func() error { return UpdateTimeToLiveClient(dbmgr, tableName, current, false) },
+		}}, nil
+	case current != Off:
+		return nil, errors.New(fmt.Sprintf("time to live is enabled on %s, disable it and wait an hour before enabling it on %s", current, attribute))
+	}
+	return []settingChange{{
+		description: fmt.Sprintf("enable time to live on %s", attribute),
+		apply:       func() error { return UpdateTimeToLiveClient(dbmgr, tableName, attribute, true) },
+	}}, nil
+}
+
+// describeKeyArn resolves a KMS key ID, ARN, alias or alias ARN into the ARN of the key with kms:DescribeKey.
+// The DynamoDB endpoint, e.g. of DynamoDB Local, isn't used for KMS.
+// It returns the ARN of the key and an error if the key can't be described.
+func describeKeyArn(dbmgr *client.DynamoDBManager, kmsKey string) (string, error) {
+	kmsClient := NewKMSClient(dbmgr.Config, func(o *kms.Options) {
+		o.BaseEndpoint = nil
+	})
+	output, err := kmsClient.DescribeKey(context.Background(), &kms.DescribeKeyInput{KeyId: aws.String(kmsKey)})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.KeyMetadata.Arn), nil
+}
+
+// encryptionChanges compares the server-side encryption of the table with the requested one.
+// A KMS key given as ID matches the ARN of the current key, a key given as alias is resolved with kms:DescribeKey first,
+// and applied anyway if it can't be resolved.
+func encryptionChanges(dbmgr *client.DynamoDBManager, table *types.TableDescription, encryption string, kmsKey string) []settingChange {
+	current, currentKey := EncryptionOwned, ""
+	if sse := table.SSEDescription; sse != nil && (sse.Status == types.SSEStatusEnabled || sse.Status == types.SSEStatusEnabling) {
+		current, currentKey = EncryptionKMS, aws.ToString(sse.KMSMasterKeyArn)
+	}
+	sameKey := kmsKey == "" || kmsKey == currentKey || strings.HasSuffix(currentKey, ":key/"+kmsKey)
+	if !sameKey && encryption == EncryptionKMS && current == EncryptionKMS && (strings.HasPrefix(kmsKey, "alias/") || strings.Contains(kmsKey, ":alias/")) {
+		keyArn, err := DescribeKeyArnClient(dbmgr, kmsKey)
+		if err != nil {
+			dbmgr.Logger.Warnf("Failed to resolve the KMS key:%s of table:%s, it is applied - error:%v", kmsKey, aws.ToString(table.TableName), err)
+		}
+		sameKey = err == nil && keyArn == currentKey
+	}
+	if encryption == "" || (encryption == current && (encryption == EncryptionOwned || sameKey)) {
+		return nil
+	}
+
+	input := &dynamodb.UpdateTableInput{TableName: table.TableName}
+	description := "encrypt with the DynamoDB owned key"
+	if encryption == EncryptionKMS {
+		input.SSESpecification = &types.SSESpecification{Enabled: aws.Bool(true), SSEType: types.SSETypeKms}
+		description = "encrypt with the AWS managed KMS key"
+		if kmsKey != "" {
+			input.SSESpecification.KMSMasterKeyId = aws.String(kmsKey)
+			description = "encrypt with KMS key " + kmsKey
+		}
+	} else {
+		input.SSESpecification = &types.SSESpecification{Enabled: aws.Bool(false)}
+	}
+	return []settingChange{updateTable(dbmgr, description, input)}
+}
+
+// planSettings compares the current settings of the table with the requested ones.
+// It returns the changes to apply, none if the table already has the requested settings, or an error if a change isn't possible.
+func planSettings(dbmgr *client.DynamoDBManager, table *types.TableDescription, ttl *types.TimeToLiveDescription, settings Settings) ([]settingChange, error) {
+	tableName := aws.ToString(table.TableName)
+	var changes []settingChange
+
+	if settings.DeletionProtection != "" {
+		enabled := settings.DeletionProtection == On
+		if aws.ToBool(table.DeletionProtectionEnabled) != enabled {
+			changes = append(changes, updateTable(dbmgr, fmt.Sprintf("turn deletion protection %s", settings.DeletionProtection), &dynamodb.UpdateTableInput{
+				TableName:                 aws.String(tableName),
+				DeletionProtectionEnabled: aws.Bool(enabled),
+			}))
+		}
+	}
+
+	if settings.TableClass != "" {
+		current := types.TableClassStandard
+		if table.TableClassSummary != nil && table.TableClassSummary.TableClass != "" {
+			current = table.TableClassSummary.TableClass
+		}
+		if string(current) != settings.TableClass {
+			changes = append(changes, updateTable(dbmgr, fmt.Sprintf("switch table class from %s to %s", current, settings.TableClass), &dynamodb.UpdateTableInput{
+				TableName:  aws.String(tableName),
+				TableClass: types.TableClass(settings.TableClass),
+			}))
+		}
+	}
+
+	changes = append(changes, streamChanges(dbmgr, table, settings.Stream)...)
+	changes = append(changes, encryptionChanges(dbmgr, table, settings.Encryption, settings.KMSKey)...)
+	ttlChanges, err := timeToLiveChanges(dbmgr, tableName, ttl, settings.TimeToLive)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, ttlChanges...), nil
+}
+
+// ExecuteUpdateSettings updates the time to live, stream, table class, deletion protection and server-side encryption of a DynamoDB table.
+// It takes a DynamoDBManager, the table name, the settings to update and the dry-run flag as input.
+// Each setting is compared with the current state of the table, only the ones that differ are updated, one after the other.
+// With dryRun set, the changes are only logged and an error wrapping client.ErrPendingChanges is returned if there are any.
+// It returns an error if the table can't be described or an update fails, the updates before it are kept.
+func ExecuteUpdateSettings(dbmgr *client.DynamoDBManager, tableName string, settings Settings, dryRun bool) error {
+	table, err := DescribeTableClient(dbmgr, tableName)
+	if err != nil {
+		return fmt.Errorf("Failed to update the table:%s - %w", tableName, err)
+	}
+	var ttl *types.TimeToLiveDescription
+	if settings.TimeToLive != "" {
+		if ttl, err = DescribeTimeToLiveClient(dbmgr, tableName); err != nil {
+			return fmt.Errorf("Failed to update the table:%s - %w", tableName, err)
+		}
+	}
+
+	changes, err := planSettings(dbmgr, table, ttl, settings)
+	if err != nil {
+		return fmt.Errorf("Failed to update the table:%s - %w", tableName, err)
+	}
+	if len(changes) == 0 {
+		dbmgr.Logger.Warn("No need to update, as the table already has the requested settings!")
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		descriptions = append(descriptions, change.description)
+	}
+	if dryRun {
+		return pendingChange(dbmgr, tableName, strings.Join(descriptions, ", "))
+	}
+
+	// The table doesn't accept an update while a previous one, e.g. of its capacity, is in progress
+	if table.TableStatus != types.TableStatusActive {
+		if err = WaitForTableClient(dbmgr, tableName, settingsTimeout); err != nil {
+			return fmt.Errorf("Failed to update the table:%s - %w", tableName, err)
+		}
+	}
+	for i, change := range changes {
+		if err = change.apply(); err != nil {
+			return fmt.Errorf("Failed to update the table:%s - %s - %w", tableName, change.description, err)
+		}
+		dbmgr.Logger.Infof("Updated table:%s - %s (%d/%d)", tableName, change.description, i+1, len(changes))
+	}
+	return nil
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/ForrestIsARealGoodman/dynamodb-manager/client"
 )
 
+// ErrInvalidModeSwitch is returned when the requested change isn't supported by the current billing mode of the table.
+var ErrInvalidModeSwitch = errors.New("invalid billing mode switch")
+
 var (
 	SwitchToOnDemandCapacityClient  = client.SwitchToOnDemandCapacity
 	UpdateProvisionedCapacityClient = client.UpdateProvisionedCapacity
@@ -16,16 +19,20 @@ var (
 // ExecuteUpdate updates the capacity mode and provisioned capacity of a DynamoDB table.
 // It takes a DynamoDBManager, table name, parameters for Read Capacity Units (RCU), Write Capacity Units (WCU),
 // and flags to switch to on-demand or provisioned capacity as input.
-// It returns an error if the update operation fails.
-func ExecuteUpdate(dbmgr *client.DynamoDBManager, tableName string, paramRcu string, paramWcu string, switchToOnDemand bool, switchToProvisioned bool) error {
+// With dryRun set, the changes are only logged and an error wrapping client.ErrPendingChanges is returned if there are any.
+// It returns an error if the update operation fails, wrapping the client error or ErrInvalidModeSwitch.
+func ExecuteUpdate(dbmgr *client.DynamoDBManager, tableName string, paramRcu string, paramWcu string, switchToOnDemand bool, switchToProvisioned bool, dryRun bool) error {
 	billingMode, rcu, wcu, err := GetCurrentBillingModeClient(dbmgr, tableName)
 	if err != nil {
 		dbmgr.Logger.Errorf("Failed to get the billing mode info of table:%s : as current billing mode due to error:%v", tableName, err)
-		return errors.New("Failed to update the table!")
+		return fmt.Errorf("Failed to update the table:%s - %w", tableName, err)
 	}
 
 	if switchToOnDemand {
 		if billingMode != "PAY_PER_REQUEST" {
+			if dryRun {
+				return pendingChange(dbmgr, tableName, fmt.Sprintf("switch billing mode from %s to PAY_PER_REQUEST", billingMode))
+			}
 			return SwitchToOnDemandCapacityClient(dbmgr, tableName)
 		} else {
 			dbmgr.Logger.Warn("No need to switch, as it already is on demand mode!")
@@ -34,10 +41,17 @@ func ExecuteUpdate(dbmgr *client.DynamoDBManager, tableName string, paramRcu str
 	} else {
 		if billingMode != "PROVISIONED" && !switchToProvisioned {
 			dbmgr.Logger.Errorf("Failed to update table:%s : as current billing mode:%s - does not support modification of rcu or wcu", tableName, billingMode)
-			return errors.New("Failed to update the table!")
+			return fmt.Errorf("Failed to update the table:%s - billing mode:%s - %w", tableName, billingMode, ErrInvalidModeSwitch)
 		}
 
 		if paramRcu == "" && paramWcu == "" {
+			if dryRun {
+				if billingMode == "PROVISIONED" {
+					dbmgr.Logger.Warn("No need to switch, as it already is provisioned mode!")
+					return nil
+				}
+				return pendingChange(dbmgr, tableName, fmt.Sprintf("switch billing mode from %s to PROVISIONED with RCU:%d - WCU:%d", billingMode, client.DefaultRcu, client.DefaultWcu))
+			}
 			return UpdateProvisionedCapacityClient(dbmgr, switchToProvisioned, tableName, "", "")
 		}
 
@@ -50,6 +64,9 @@ func ExecuteUpdate(dbmgr *client.DynamoDBManager, tableName string, paramRcu str
 		}
 
 		if paramRcu != rcu || paramWcu != wcu {
+			if dryRun {
+				return pendingChange(dbmgr, tableName, fmt.Sprintf("set billing mode PROVISIONED - RCU:%s -> %s - WCU:%s -> %s", rcu, paramRcu, wcu, paramWcu))
+			}
 			return UpdateProvisionedCapacityClient(dbmgr, switchToProvisioned, tableName, paramRcu, paramWcu)
 		} else {
 			dbmgr.Logger.Warn("No need to update, as it already is provisioned mode or remain the same rcu and wcu!")
@@ -57,3 +74,10 @@ func ExecuteUpdate(dbmgr *client.DynamoDBManager, tableName string, paramRcu str
 		}
 	}
 }
+
+// pendingChange logs a change that a dry run would apply to the table.
+// It returns an error wrapping client.ErrPendingChanges.
+func pendingChange(dbmgr *client.DynamoDBManager, tableName string, change string) error {
+	dbmgr.Logger.Infof("Dry run - table:%s would be updated: %s", tableName, change)
+	return fmt.Errorf("table:%s - %s - %w", tableName, change, client.ErrPendingChanges)
+}